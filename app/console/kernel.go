@@ -3,6 +3,7 @@ package console
 import (
 	"goravel/app/console/commands"
 	"goravel/app/jobs"
+	"goravel/app/services"
 
 	"github.com/goravel/framework/contracts/console"
 	"github.com/goravel/framework/contracts/schedule"
@@ -14,13 +15,28 @@ type Kernel struct {
 
 func (kernel Kernel) Schedule() []schedule.Event {
 	return []schedule.Event{
-		// 每天凌晨 1 点检查服务器到期告警
+		// 每天凌晨 1 点检查服务器到期告警，与其他告警一样遵循静默规则
 		facades.Schedule().Call(func() {
-			job := &jobs.CheckServerExpirationJob{}
-			if err := job.Handle(); err != nil {
+			if err := services.NewAlertService().CheckExpirations(); err != nil {
 				facades.Log().Errorf("执行服务器到期检查任务失败: %v", err)
 			}
 		}).DailyAt("01:00").Name("check_server_expiration"),
+
+		// 每天凌晨 2 点根据历史指标重新计算异常检测基线
+		facades.Schedule().Call(func() {
+			job := &jobs.ComputeMetricBaselinesJob{}
+			if err := job.Handle(); err != nil {
+				facades.Log().Errorf("执行指标基线计算任务失败: %v", err)
+			}
+		}).DailyAt("02:00").Name("compute_metric_baselines"),
+
+		// 每分钟重试发送失败的通知，并清理过期的投递记录
+		facades.Schedule().Call(func() {
+			job := &jobs.RetryNotificationDeliveriesJob{}
+			if err := job.Handle(); err != nil {
+				facades.Log().Errorf("执行通知重试任务失败: %v", err)
+			}
+		}).EveryMinute().SkipIfStillRunning().Name("retry_notification_deliveries"),
 	}
 }
 
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"strconv"
+
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/app/utils"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/facades"
+)
+
+type AgentRolloutController struct{}
+
+func NewAgentRolloutController() *AgentRolloutController {
+	return &AgentRolloutController{}
+}
+
+// GetRollouts 获取所有发布计划
+func (c *AgentRolloutController) GetRollouts(ctx http.Context) http.Response {
+	rollouts, err := repositories.GetAgentRolloutRepository().GetAll()
+	if err != nil {
+		facades.Log().Errorf("获取发布计划列表失败: %v", err)
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取发布计划列表失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", rollouts)
+}
+
+// GetRollout 获取发布计划详情及各服务器的更新进度
+func (c *AgentRolloutController) GetRollout(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的发布计划ID")
+	}
+
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	rollout, err := rolloutRepo.GetByID(uint(id))
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取发布计划失败", err)
+	}
+	if rollout == nil {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "发布计划不存在")
+	}
+
+	servers, err := rolloutRepo.GetServers(rollout.ID)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取发布服务器失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"rollout": rollout,
+		"servers": servers,
+	})
+}
+
+// CreateRollout 创建发布计划并开始执行
+func (c *AgentRolloutController) CreateRollout(ctx http.Context) http.Response {
+	type CreateRolloutRequest struct {
+		Name         string   `json:"name" form:"name"`
+		Version      string   `json:"version" form:"version"`
+		GroupID      *uint    `json:"group_id" form:"group_id"`
+		ServerIDs    []string `json:"server_ids" form:"server_ids"`
+		CanaryCount  *int     `json:"canary_count" form:"canary_count"`
+		BatchSize    int      `json:"batch_size" form:"batch_size"`
+		MaxFailures  int      `json:"max_failures" form:"max_failures"`
+		BatchTimeout int      `json:"batch_timeout" form:"batch_timeout"`
+	}
+
+	var req CreateRolloutRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+	if req.GroupID == nil && len(req.ServerIDs) == 0 {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "请选择分组或服务器")
+	}
+
+	// 未指定版本时使用最新发布的版本
+	versionType := ""
+	if req.Version == "" {
+		releaseInfo, err := services.NewUpdateService().FetchLatestRelease(agentReleaseUrls)
+		if err != nil {
+			return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, err.Error(), err, "FETCH_RELEASE_FAILED")
+		}
+		req.Version = releaseInfo.NormalizedTagName
+		versionType = releaseInfo.VersionType
+	}
+
+	canaryCount := 1
+	if req.CanaryCount != nil {
+		canaryCount = *req.CanaryCount
+	}
+
+	rollout, err := services.GetAgentRolloutService().Create(services.AgentRolloutOptions{
+		Name:         req.Name,
+		Version:      req.Version,
+		VersionType:  versionType,
+		GroupID:      req.GroupID,
+		ServerIDs:    req.ServerIDs,
+		CanaryCount:  canaryCount,
+		BatchSize:    req.BatchSize,
+		MaxFailures:  req.MaxFailures,
+		BatchTimeout: req.BatchTimeout,
+	})
+	if err != nil {
+		facades.Log().Errorf("创建发布计划失败: %v", err)
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	facades.Log().Infof("成功创建Agent发布计划: id=%d, version=%s, servers=%d", rollout.ID, rollout.TargetVersion, rollout.TotalServers)
+	return utils.SuccessResponseWithStatus(ctx, http.StatusCreated, "发布计划已开始", rollout)
+}
+
+// ResumeRollout 继续已暂停的发布计划
+func (c *AgentRolloutController) ResumeRollout(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的发布计划ID")
+	}
+
+	if err := services.GetAgentRolloutService().Start(uint(id)); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "发布计划已继续")
+}
+
+// CancelRollout 取消发布计划
+func (c *AgentRolloutController) CancelRollout(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的发布计划ID")
+	}
+
+	if err := services.GetAgentRolloutService().Cancel(uint(id)); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "发布计划已取消")
+}
@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/app/utils"
+
+	"github.com/goravel/framework/contracts/http"
+)
+
+type AlertController struct{}
+
+func NewAlertController() *AlertController {
+	return &AlertController{}
+}
+
+// GetAlerts 获取告警记录，支持按服务器、状态、级别、类型、规则、确认状态和时间范围筛选
+func (c *AlertController) GetAlerts(ctx http.Context) http.Response {
+	filter := repositories.AlertFilter{
+		ServerID: ctx.Request().Query("server_id"),
+		Status:   ctx.Request().Query("status"),
+		Severity: ctx.Request().Query("severity"),
+		Type:     ctx.Request().Query("type"),
+		RuleKey:  ctx.Request().Query("rule_key"),
+		Page:     ctx.Request().QueryInt("page", 1),
+		PageSize: ctx.Request().QueryInt("page_size", 20),
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	if acknowledged := ctx.Request().Query("acknowledged"); acknowledged != "" {
+		value, err := strconv.ParseBool(acknowledged)
+		if err != nil {
+			return utils.ErrorResponse(ctx, http.StatusBadRequest, "acknowledged 参数无效")
+		}
+		filter.Acknowledged = &value
+	}
+
+	// 时间范围为 Unix 时间戳（秒）
+	if start := ctx.Request().QueryInt64("start", 0); start > 0 {
+		startTime := time.Unix(start, 0)
+		filter.StartTime = &startTime
+	}
+	if end := ctx.Request().QueryInt64("end", 0); end > 0 {
+		endTime := time.Unix(end, 0)
+		filter.EndTime = &endTime
+	}
+
+	alerts, total, err := repositories.GetAlertRepository().List(filter)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警记录失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"list":      alerts,
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
+
+// AcknowledgeAlert 确认告警
+func (c *AlertController) AcknowledgeAlert(ctx http.Context) http.Response {
+	alert, err := services.NewAlertService().AcknowledgeAlert(ctx.Request().Route("id"), currentAdminName())
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "告警已确认", alert)
+}
+
+// ResolveAlert 手动恢复告警
+func (c *AlertController) ResolveAlert(ctx http.Context) http.Response {
+	alert, err := services.NewAlertService().ResolveAlert(ctx.Request().Route("id"), currentAdminName())
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "告警已恢复", alert)
+}
+
+// silenceRequest 创建或更新静默规则的请求参数
+type silenceRequest struct {
+	Name     string            `json:"name" form:"name"`
+	Comment  string            `json:"comment" form:"comment"`
+	ServerID *string           `json:"server_id" form:"server_id"`
+	GroupID  *uint             `json:"group_id" form:"group_id"`
+	RuleType string            `json:"rule_type" form:"rule_type"` // cpu、server_offline、expression 等，为空表示所有告警
+	Labels   map[string]string `json:"labels" form:"labels"`
+	StartsAt string            `json:"starts_at" form:"starts_at"` // RFC3339 或 2006-01-02 15:04:05
+	EndsAt   string            `json:"ends_at" form:"ends_at"`
+	Cron     string            `json:"cron" form:"cron"`         // 周期维护窗口，例如每周日凌晨 3 点: 0 3 * * 0
+	Duration int               `json:"duration" form:"duration"` // 周期维护窗口时长(分钟)
+}
+
+// options 转换为静默规则参数
+func (r *silenceRequest) options() (services.SilenceOptions, error) {
+	options := services.SilenceOptions{
+		Name:     r.Name,
+		Comment:  r.Comment,
+		ServerID: r.ServerID,
+		GroupID:  r.GroupID,
+		RuleType: r.RuleType,
+		Labels:   r.Labels,
+		Cron:     r.Cron,
+		Duration: r.Duration,
+	}
+	if options.ServerID != nil && *options.ServerID == "" {
+		options.ServerID = nil
+	}
+
+	var err error
+	if options.StartsAt, err = parseRequestTime(r.StartsAt); err != nil {
+		return options, errors.New("开始时间格式无效")
+	}
+	if options.EndsAt, err = parseRequestTime(r.EndsAt); err != nil {
+		return options, errors.New("结束时间格式无效")
+	}
+	return options, nil
+}
+
+// parseRequestTime 解析请求中的时间，支持 RFC3339 和 2006-01-02 15:04:05（本地时间）
+func parseRequestTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &parsed, nil
+}
+
+// GetSilences 获取静默规则列表，并标记当前是否生效
+func (c *AlertController) GetSilences(ctx http.Context) http.Response {
+	silences, err := repositories.GetAlertSilenceRepository().GetAll()
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取静默规则失败", err)
+	}
+
+	type silenceItem struct {
+		*models.AlertSilence
+		Active bool `json:"active"`
+	}
+	now := time.Now()
+	result := make([]silenceItem, 0, len(silences))
+	for _, silence := range silences {
+		result = append(result, silenceItem{AlertSilence: silence, Active: services.IsSilenceActive(silence, now)})
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", result)
+}
+
+// CreateSilence 创建静默规则
+func (c *AlertController) CreateSilence(ctx http.Context) http.Response {
+	var req silenceRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+	options, err := req.options()
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	silence, err := services.NewAlertService().CreateSilence(options, currentAdminName())
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponseWithStatus(ctx, http.StatusCreated, "创建成功", silence)
+}
+
+// UpdateSilence 更新静默规则
+func (c *AlertController) UpdateSilence(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的静默规则ID")
+	}
+
+	var req silenceRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+	options, err := req.options()
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	silence, err := services.NewAlertService().UpdateSilence(uint(id), options)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "更新成功", silence)
+}
+
+// DeleteSilence 删除静默规则
+func (c *AlertController) DeleteSilence(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的静默规则ID")
+	}
+
+	if err := repositories.GetAlertSilenceRepository().Delete(uint(id)); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "删除静默规则失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// escalationPolicyItem 升级策略响应，步骤以 JSON 数组返回
+type escalationPolicyItem struct {
+	*models.AlertEscalationPolicy
+	Steps json.RawMessage `json:"steps"`
+}
+
+func newEscalationPolicyItem(policy *models.AlertEscalationPolicy) escalationPolicyItem {
+	steps := json.RawMessage("[]")
+	if json.Valid([]byte(policy.Steps)) {
+		steps = json.RawMessage(policy.Steps)
+	}
+	return escalationPolicyItem{AlertEscalationPolicy: policy, Steps: steps}
+}
+
+// GetEscalationPolicies 获取升级策略列表
+func (c *AlertController) GetEscalationPolicies(ctx http.Context) http.Response {
+	policies, err := repositories.GetAlertEscalationPolicyRepository().GetAll()
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取升级策略失败", err)
+	}
+
+	result := make([]escalationPolicyItem, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, newEscalationPolicyItem(policy))
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", result)
+}
+
+// CreateEscalationPolicy 创建升级策略
+func (c *AlertController) CreateEscalationPolicy(ctx http.Context) http.Response {
+	var req services.EscalationPolicyOptions
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	policy, err := services.NewAlertService().CreateEscalationPolicy(req)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponseWithStatus(ctx, http.StatusCreated, "创建成功", newEscalationPolicyItem(policy))
+}
+
+// UpdateEscalationPolicy 更新升级策略
+func (c *AlertController) UpdateEscalationPolicy(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的升级策略ID")
+	}
+
+	var req services.EscalationPolicyOptions
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	policy, err := services.NewAlertService().UpdateEscalationPolicy(uint(id), req)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "更新成功", newEscalationPolicyItem(policy))
+}
+
+// DeleteEscalationPolicy 删除升级策略，使用该策略的分组和告警改为按服务器通知配置发送
+func (c *AlertController) DeleteEscalationPolicy(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的升级策略ID")
+	}
+
+	if err := repositories.GetAlertEscalationPolicyRepository().Delete(uint(id)); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "删除升级策略失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// GetNotificationDeliveries 获取通知投递记录，支持按渠道、渠道类型、状态和时间范围筛选
+func (c *AlertController) GetNotificationDeliveries(ctx http.Context) http.Response {
+	filter := repositories.NotificationDeliveryFilter{
+		ChannelID:   uint(ctx.Request().QueryInt("channel_id", 0)),
+		ChannelType: ctx.Request().Query("channel_type"),
+		Status:      ctx.Request().Query("status"),
+		Page:        ctx.Request().QueryInt("page", 1),
+		PageSize:    ctx.Request().QueryInt("page_size", 20),
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	// 时间范围为 Unix 时间戳（秒）
+	if start := ctx.Request().QueryInt64("start", 0); start > 0 {
+		startTime := time.Unix(start, 0)
+		filter.StartTime = &startTime
+	}
+	if end := ctx.Request().QueryInt64("end", 0); end > 0 {
+		endTime := time.Unix(end, 0)
+		filter.EndTime = &endTime
+	}
+
+	deliveries, total, err := repositories.GetNotificationDeliveryRepository().List(filter)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取投递记录失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"list":      deliveries,
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
+
+// GetNotificationDelivery 获取投递记录详情及每次发送尝试的结果
+func (c *AlertController) GetNotificationDelivery(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的投递记录ID")
+	}
+
+	deliveryRepo := repositories.GetNotificationDeliveryRepository()
+	delivery, err := deliveryRepo.GetByID(uint(id))
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取投递记录失败", err)
+	}
+	if delivery == nil {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "投递记录不存在")
+	}
+
+	attempts, err := deliveryRepo.GetAttempts(delivery.ID)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取发送尝试失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"delivery": delivery,
+		"attempts": attempts,
+	})
+}
+
+// ResendNotificationDelivery 手动重发通知，返回新的投递记录
+func (c *AlertController) ResendNotificationDelivery(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的投递记录ID")
+	}
+
+	delivery, err := repositories.GetNotificationDeliveryRepository().GetByID(uint(id))
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取投递记录失败", err)
+	}
+	if delivery == nil {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "投递记录不存在")
+	}
+
+	resent, err := services.NewAlertService().ResendNotificationDelivery(delivery)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	if resent.Status != models.DeliveryStatusSent {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "重发失败: "+resent.LastError)
+	}
+
+	return utils.SuccessResponse(ctx, "重发成功", resent)
+}
+
+// currentAdminName 获取管理员用户名，用于记录告警处理人
+func currentAdminName() string {
+	return repositories.GetSystemSettingRepository().GetValue("admin_username", "admin")
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"strconv"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/app/utils"
+
+	"github.com/goravel/framework/contracts/http"
+)
+
+type AlertProfileController struct{}
+
+func NewAlertProfileController() *AlertProfileController {
+	return &AlertProfileController{}
+}
+
+// alertProfileItem 告警配置模板响应，包含模板规则和使用情况
+type alertProfileItem struct {
+	*models.AlertProfile
+	Rules       map[string]interface{} `json:"rules,omitempty"`
+	ServerCount int64                  `json:"server_count"`
+	GroupCount  int64                  `json:"group_count"`
+}
+
+func newAlertProfileItem(profile *models.AlertProfile, withRules bool) (alertProfileItem, error) {
+	item := alertProfileItem{AlertProfile: profile}
+	serverCount, groupCount, err := repositories.GetAlertProfileRepository().CountUsage(profile.ID)
+	if err != nil {
+		return item, err
+	}
+	item.ServerCount = serverCount
+	item.GroupCount = groupCount
+
+	if withRules {
+		rules, err := services.NewAlertService().GetScopeAlertRules(services.RuleScope{ProfileID: &profile.ID})
+		if err != nil {
+			return item, err
+		}
+		item.Rules = rules
+	}
+	return item, nil
+}
+
+// GetAlertProfiles 获取告警配置模板列表
+func (c *AlertProfileController) GetAlertProfiles(ctx http.Context) http.Response {
+	profiles, err := repositories.GetAlertProfileRepository().GetAll()
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警配置模板失败", err)
+	}
+
+	result := make([]alertProfileItem, 0, len(profiles))
+	for _, profile := range profiles {
+		item, err := newAlertProfileItem(profile, false)
+		if err != nil {
+			return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警配置模板失败", err)
+		}
+		result = append(result, item)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", result)
+}
+
+// GetAlertProfile 获取告警配置模板及其规则
+func (c *AlertProfileController) GetAlertProfile(ctx http.Context) http.Response {
+	profile, ok := c.profileFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "告警配置模板不存在")
+	}
+
+	item, err := newAlertProfileItem(profile, true)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警配置模板失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", item)
+}
+
+// CreateAlertProfile 创建告警配置模板
+func (c *AlertProfileController) CreateAlertProfile(ctx http.Context) http.Response {
+	var req services.AlertProfileOptions
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	profile, err := services.NewAlertService().CreateAlertProfile(req)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := newAlertProfileItem(profile, true)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警配置模板失败", err)
+	}
+
+	return utils.SuccessResponseWithStatus(ctx, http.StatusCreated, "创建成功", item)
+}
+
+// UpdateAlertProfile 更新告警配置模板，只更新请求中包含的规则类型，修改对使用该模板的所有服务器生效
+func (c *AlertProfileController) UpdateAlertProfile(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的模板ID")
+	}
+
+	var req services.AlertProfileOptions
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	profile, err := services.NewAlertService().UpdateAlertProfile(uint(id), req)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := newAlertProfileItem(profile, true)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警配置模板失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "更新成功", item)
+}
+
+// DeleteAlertProfile 删除告警配置模板，使用该模板的服务器和分组改为继承下一级规则
+func (c *AlertProfileController) DeleteAlertProfile(ctx http.Context) http.Response {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的模板ID")
+	}
+
+	if err := repositories.GetAlertProfileRepository().Delete(uint(id)); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "删除告警配置模板失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// DeleteAlertProfileRule 删除告警配置模板中指定类型的规则
+func (c *AlertProfileController) DeleteAlertProfileRule(ctx http.Context) http.Response {
+	profile, ok := c.profileFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "告警配置模板不存在")
+	}
+
+	ruleType := ctx.Request().Route("type")
+	if err := services.NewAlertService().DeleteAlertRule(services.RuleScope{ProfileID: &profile.ID}, ruleType); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// AssignAlertProfile 将告警配置模板分配给服务器和分组，服务器自身配置的规则仍作为覆盖优先生效
+func (c *AlertProfileController) AssignAlertProfile(ctx http.Context) http.Response {
+	profile, ok := c.profileFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "告警配置模板不存在")
+	}
+
+	type AssignAlertProfileRequest struct {
+		ServerIDs []string `json:"server_ids" form:"server_ids"`
+		GroupIDs  []uint   `json:"group_ids" form:"group_ids"`
+	}
+
+	var req AssignAlertProfileRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+	if len(req.ServerIDs) == 0 && len(req.GroupIDs) == 0 {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "至少需要选择一台服务器或一个分组")
+	}
+
+	if err := services.NewAlertService().AssignAlertProfile(&profile.ID, req.ServerIDs, req.GroupIDs); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "分配告警配置模板失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "分配成功")
+}
+
+// profileFromRoute 从路由参数获取告警配置模板
+func (c *AlertProfileController) profileFromRoute(ctx http.Context) (*models.AlertProfile, bool) {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	profile, err := repositories.GetAlertProfileRepository().GetByID(uint(id))
+	if err != nil || profile == nil {
+		return nil, false
+	}
+	return profile, true
+}
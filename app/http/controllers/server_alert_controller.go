@@ -7,6 +7,7 @@ import (
 	"goravel/app/repositories"
 	"goravel/app/services"
 	"goravel/app/utils"
+	"strings"
 
 	"github.com/goravel/framework/contracts/http"
 	"github.com/goravel/framework/facades"
@@ -32,30 +33,22 @@ func (c *ServerAlertController) GetServerAlertRules(ctx http.Context) http.Respo
 		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警规则失败", err)
 	}
 
-	// 转换为前端需要的格式
+	// 转换为前端需要的格式（for、recovery、labels 仅在配置时返回）
 	result := map[string]interface{}{
-		"cpu": map[string]interface{}{
-			"enabled":  rules.CPU.Enabled,
-			"warning":  rules.CPU.Warning,
-			"critical": rules.CPU.Critical,
-		},
-		"memory": map[string]interface{}{
-			"enabled":  rules.Memory.Enabled,
-			"warning":  rules.Memory.Warning,
-			"critical": rules.Memory.Critical,
-		},
-		"disk": map[string]interface{}{
-			"enabled":  rules.Disk.Enabled,
-			"warning":  rules.Disk.Warning,
-			"critical": rules.Disk.Critical,
-		},
+		"cpu":         rules.CPU,
+		"memory":      rules.Memory,
+		"disk":        rules.Disk,
+		"gpu_util":    rules.GPUUtil,
+		"gpu_mem":     rules.GPUMem,
+		"gpu_temp":    rules.GPUTemp,
+		"temperature": rules.Temperature,
 	}
 
 	// 获取其他类型的告警规则（bandwidth, traffic, expiration）
 	ruleRepo := repositories.GetServerAlertRuleRepository()
-	
+
 	// bandwidth: {enabled: bool, threshold: number}
-	bandwidthRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "bandwidth")
+	bandwidthRule, err := ruleRepo.GetEffectiveByType(serverID, "bandwidth")
 	if err == nil && bandwidthRule != nil {
 		var ruleConfig map[string]interface{}
 		if err := json.Unmarshal([]byte(bandwidthRule.Config), &ruleConfig); err == nil {
@@ -76,7 +69,7 @@ func (c *ServerAlertController) GetServerAlertRules(ctx http.Context) http.Respo
 	}
 
 	// traffic: {enabled: bool, threshold_percent: number}
-	trafficRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "traffic")
+	trafficRule, err := ruleRepo.GetEffectiveByType(serverID, "traffic")
 	if err == nil && trafficRule != nil {
 		var ruleConfig map[string]interface{}
 		if err := json.Unmarshal([]byte(trafficRule.Config), &ruleConfig); err == nil {
@@ -84,20 +77,20 @@ func (c *ServerAlertController) GetServerAlertRules(ctx http.Context) http.Respo
 		} else {
 			// 解析失败，返回默认值
 			result["traffic"] = map[string]interface{}{
-				"enabled":         false,
+				"enabled":           false,
 				"threshold_percent": 80,
 			}
 		}
 	} else {
 		// 没有配置，返回默认禁用状态
 		result["traffic"] = map[string]interface{}{
-			"enabled":         false,
+			"enabled":           false,
 			"threshold_percent": 80,
 		}
 	}
 
 	// expiration: {enabled: bool, alert_days: number}
-	expirationRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "expiration")
+	expirationRule, err := ruleRepo.GetEffectiveByType(serverID, "expiration")
 	if err == nil && expirationRule != nil {
 		var ruleConfig map[string]interface{}
 		if err := json.Unmarshal([]byte(expirationRule.Config), &ruleConfig); err == nil {
@@ -117,6 +110,34 @@ func (c *ServerAlertController) GetServerAlertRules(ctx http.Context) http.Respo
 		}
 	}
 
+	// service: {enabled: bool}
+	serviceRule, err := ruleRepo.GetEffectiveByType(serverID, "service")
+	if err == nil && serviceRule != nil {
+		var ruleConfig map[string]interface{}
+		if err := json.Unmarshal([]byte(serviceRule.Config), &ruleConfig); err == nil {
+			result["service"] = ruleConfig
+		} else {
+			result["service"] = map[string]interface{}{
+				"enabled": false,
+			}
+		}
+	} else {
+		result["service"] = map[string]interface{}{
+			"enabled": false,
+		}
+	}
+
+	// anomaly、disk_forecast: 未配置的字段使用默认值
+	result["anomaly"] = alertService.GetAnomalyRule(serverID)
+	result["disk_forecast"] = alertService.GetDiskForecastRule(serverID)
+
+	// 表达式规则
+	expressionRules, err := alertService.GetExpressionRules(serverID)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警规则失败", err)
+	}
+	result["expressions"] = expressionRules
+
 	return ctx.Response().Success().Json(http.Json{
 		"status":  true,
 		"message": "success",
@@ -131,20 +152,24 @@ func (c *ServerAlertController) UpdateServerAlertRules(ctx http.Context) http.Re
 		return utils.ErrorResponse(ctx, http.StatusBadRequest, "服务器ID不能为空")
 	}
 
-	type RuleInput struct {
-		Enabled  bool    `json:"enabled" form:"enabled"`
-		Warning  float64 `json:"warning" form:"warning"`
-		Critical float64 `json:"critical" form:"critical"`
-	}
-
 	type RulesInput struct {
-		CPU    *RuleInput `json:"cpu" form:"cpu"`
-		Memory *RuleInput `json:"memory" form:"memory"`
-		Disk   *RuleInput `json:"disk" form:"disk"`
+		// 阈值规则 {enabled: bool, warning: float64, critical: float64, for: string, recovery: float64, labels: {}, escalation_policy_id: uint, 重复通知配置}
+		CPU    *map[string]interface{} `json:"cpu" form:"cpu"`
+		Memory *map[string]interface{} `json:"memory" form:"memory"`
+		Disk   *map[string]interface{} `json:"disk" form:"disk"`
+		// GPU规则（显存为使用率百分比，温度单位为°C）
+		GPUUtil *map[string]interface{} `json:"gpu_util" form:"gpu_util"`
+		GPUMem  *map[string]interface{} `json:"gpu_mem" form:"gpu_mem"`
+		GPUTemp *map[string]interface{} `json:"gpu_temp" form:"gpu_temp"`
+		// 硬件传感器温度规则（°C）
+		Temperature *map[string]interface{} `json:"temperature" form:"temperature"`
 		// 新增规则类型
-		Bandwidth  *map[string]interface{} `json:"bandwidth" form:"bandwidth"`   // {enabled: bool, threshold: float64}
-		Traffic    *map[string]interface{} `json:"traffic" form:"traffic"`       // {enabled: bool, threshold_percent: float64}
-		Expiration *map[string]interface{} `json:"expiration" form:"expiration"` // {enabled: bool, alert_days: float64}
+		Bandwidth    *map[string]interface{} `json:"bandwidth" form:"bandwidth"`         // {enabled: bool, threshold: float64}
+		Traffic      *map[string]interface{} `json:"traffic" form:"traffic"`             // {enabled: bool, threshold_percent: float64}
+		Expiration   *map[string]interface{} `json:"expiration" form:"expiration"`       // {enabled: bool, alert_days: float64}
+		Service      *map[string]interface{} `json:"service" form:"service"`             // {enabled: bool}
+		Anomaly      *map[string]interface{} `json:"anomaly" form:"anomaly"`             // {enabled: bool, metrics: [], sensitivity: float64, ...}
+		DiskForecast *map[string]interface{} `json:"disk_forecast" form:"disk_forecast"` // {enabled: bool, warning_hours: float64, critical_hours: float64, window: string}
 	}
 
 	var req RulesInput
@@ -157,56 +182,63 @@ func (c *ServerAlertController) UpdateServerAlertRules(ctx http.Context) http.Re
 	rules := make(map[string]services.Rule)
 
 	// 处理基础资源规则
-	if req.CPU != nil {
-		rules["cpu"] = services.Rule{
-			Enabled:  req.CPU.Enabled,
-			Warning:  req.CPU.Warning,
-			Critical: req.CPU.Critical,
-		}
+	thresholdInputs := map[string]*map[string]interface{}{
+		"cpu":         req.CPU,
+		"memory":      req.Memory,
+		"disk":        req.Disk,
+		"gpu_util":    req.GPUUtil,
+		"gpu_mem":     req.GPUMem,
+		"gpu_temp":    req.GPUTemp,
+		"temperature": req.Temperature,
 	}
-	if req.Memory != nil {
-		rules["memory"] = services.Rule{
-			Enabled:  req.Memory.Enabled,
-			Warning:  req.Memory.Warning,
-			Critical: req.Memory.Critical,
+	for ruleType, input := range thresholdInputs {
+		if input == nil {
+			continue
 		}
-	}
-	if req.Disk != nil {
-		rules["disk"] = services.Rule{
-			Enabled:  req.Disk.Enabled,
-			Warning:  req.Disk.Warning,
-			Critical: req.Disk.Critical,
+		ruleData := *input
+		enabled, _ := ruleData["enabled"].(bool)
+		warning, _ := ruleData["warning"].(float64)
+		critical, _ := ruleData["critical"].(float64)
+		rule := services.Rule{
+			Enabled:  enabled,
+			Warning:  warning,
+			Critical: critical,
+		}
+		// 可选的持续时间、恢复阈值、标签、升级策略和重复通知配置
+		services.ApplyRuleOptions(&rule, ruleData)
+		if err := rule.Validate(); err != nil {
+			return utils.ErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("%s 规则无效: %v", ruleType, err))
 		}
+		if !escalationPolicyExists(rule.EscalationPolicyID) {
+			return utils.ErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("%s 规则的升级策略不存在", ruleType))
+		}
+		rules[ruleType] = rule
 	}
 
-	// 处理新增规则类型
-	ruleRepo := repositories.GetServerAlertRuleRepository()
-	if req.Bandwidth != nil {
-		configJson, _ := json.Marshal(*req.Bandwidth)
-		rule := &models.ServerAlertRule{
-			ServerID: serverIDPtr,
-			RuleType: "bandwidth",
-			Config:   string(configJson),
-		}
-		_ = ruleRepo.CreateOrUpdate(rule)
-	}
-	if req.Traffic != nil {
-		configJson, _ := json.Marshal(*req.Traffic)
-		rule := &models.ServerAlertRule{
-			ServerID: serverIDPtr,
-			RuleType: "traffic",
-			Config:   string(configJson),
+	// 校验新增规则类型的配置
+	configInputs := map[string]*map[string]interface{}{
+		"bandwidth":     req.Bandwidth,
+		"traffic":       req.Traffic,
+		"expiration":    req.Expiration,
+		"service":       req.Service,
+		"anomaly":       req.Anomaly,
+		"disk_forecast": req.DiskForecast,
+	}
+	configRules := make(map[string]interface{})
+	for ruleType, input := range configInputs {
+		if input != nil {
+			configRules[ruleType] = *input
 		}
-		_ = ruleRepo.CreateOrUpdate(rule)
-	}
-	if req.Expiration != nil {
-		configJson, _ := json.Marshal(*req.Expiration)
-		rule := &models.ServerAlertRule{
-			ServerID: serverIDPtr,
-			RuleType: "expiration",
-			Config:   string(configJson),
+	}
+	if err := alertService.ValidateAlertRules(configRules); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// 保存新增规则类型
+	if len(configRules) > 0 {
+		if err := alertService.SaveAlertRules(services.RuleScope{ServerID: serverIDPtr}, configRules); err != nil {
+			return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "保存告警规则失败", err)
 		}
-		_ = ruleRepo.CreateOrUpdate(rule)
 	}
 
 	// 保存基础资源规则
@@ -219,12 +251,12 @@ func (c *ServerAlertController) UpdateServerAlertRules(ctx http.Context) http.Re
 	return utils.SuccessResponse(ctx, "success")
 }
 
-// CopyAlertRules 复制告警规则到多个服务器
+// CopyAlertRules 复制告警规则到多个服务器，复制后的规则与源服务器相互独立，需要共用规则时使用告警配置模板
 func (c *ServerAlertController) CopyAlertRules(ctx http.Context) http.Response {
 	type CopyAlertRulesRequest struct {
 		SourceServerID  string   `json:"source_server_id" form:"source_server_id"`
 		TargetServerIDs []string `json:"target_server_ids" form:"target_server_ids"`
-		RuleTypes       []string `json:"rule_types" form:"rule_types"` // cpu, memory, disk, bandwidth, traffic, expiration
+		RuleTypes       []string `json:"rule_types" form:"rule_types"` // cpu, memory, disk, gpu_util, gpu_mem, gpu_temp, temperature, bandwidth, traffic, expiration, service, anomaly, disk_forecast, expression（全部表达式规则）
 	}
 
 	var req CopyAlertRulesRequest
@@ -255,6 +287,24 @@ func (c *ServerAlertController) CopyAlertRules(ctx http.Context) http.Response {
 	ruleRepo := repositories.GetServerAlertRuleRepository()
 	sourceServerIDPtr := &req.SourceServerID
 
+	// expression 表示复制源服务器的所有表达式规则
+	ruleTypes := make([]string, 0, len(req.RuleTypes))
+	for _, ruleType := range req.RuleTypes {
+		if ruleType != "expression" {
+			ruleTypes = append(ruleTypes, ruleType)
+			continue
+		}
+		sourceRules, err := ruleRepo.GetByServerID(req.SourceServerID)
+		if err != nil {
+			return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取源服务器告警规则失败", err)
+		}
+		for _, sourceRule := range sourceRules {
+			if strings.HasPrefix(sourceRule.RuleType, services.ExpressionRuleTypePrefix) {
+				ruleTypes = append(ruleTypes, sourceRule.RuleType)
+			}
+		}
+	}
+
 	// 复制规则到目标服务器
 	successCount := 0
 	failCount := 0
@@ -275,7 +325,7 @@ func (c *ServerAlertController) CopyAlertRules(ctx http.Context) http.Response {
 		}
 
 		// 复制每个规则类型
-		for _, ruleType := range req.RuleTypes {
+		for _, ruleType := range ruleTypes {
 			// 获取源服务器的规则
 			sourceRule, err := ruleRepo.GetByServerIDAndType(sourceServerIDPtr, ruleType)
 			if err != nil {
@@ -312,3 +362,223 @@ func (c *ServerAlertController) CopyAlertRules(ctx http.Context) http.Response {
 
 	return utils.SuccessResponse(ctx, message)
 }
+
+// GetExpressionRules 获取指定服务器的表达式告警规则
+func (c *ServerAlertController) GetExpressionRules(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if serverID == "" {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "服务器ID不能为空")
+	}
+
+	rules, err := services.NewAlertService().GetExpressionRules(serverID)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取表达式规则失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", rules)
+}
+
+// SaveExpressionRule 创建或更新指定服务器的表达式告警规则
+func (c *ServerAlertController) SaveExpressionRule(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if serverID == "" {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "服务器ID不能为空")
+	}
+
+	server, err := repositories.GetServerRepository().GetByID(serverID)
+	if err != nil || server == nil {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "服务器不存在")
+	}
+
+	var rule services.ExpressionRule
+	if err := ctx.Request().Bind(&rule); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+
+	if err := services.NewAlertService().SaveExpressionRule(serverID, rule); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	facades.Log().Infof("保存表达式告警规则: server_id=%s, key=%s, expression=%s", serverID, rule.Key, rule.Expression)
+	return utils.SuccessResponse(ctx, "保存成功", rule)
+}
+
+// DeleteExpressionRule 删除指定服务器的表达式告警规则
+func (c *ServerAlertController) DeleteExpressionRule(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	key := ctx.Request().Route("key")
+	if serverID == "" || key == "" {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "服务器ID和规则标识不能为空")
+	}
+
+	if err := services.NewAlertService().DeleteExpressionRule(serverID, key); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "删除表达式规则失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// PreviewExpression 校验表达式并使用服务器当前指标求值
+func (c *ServerAlertController) PreviewExpression(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if serverID == "" {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "服务器ID不能为空")
+	}
+
+	expr := ctx.Request().Input("expression")
+	result, err := services.NewAlertService().PreviewExpression(serverID, expr)
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error(), "INVALID_EXPRESSION")
+	}
+
+	return utils.SuccessResponse(ctx, "表达式有效", result)
+}
+
+// GetEffectiveAlertRules 获取服务器生效的告警规则及每条规则的来源（server、group、profile、global、default）
+func (c *ServerAlertController) GetEffectiveAlertRules(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	server, err := repositories.GetServerRepository().GetByID(serverID)
+	if err != nil || server == nil {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "服务器不存在")
+	}
+
+	rules, err := services.NewAlertService().GetEffectiveAlertRules(serverID)
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取告警规则失败", err)
+	}
+
+	mode := server.AlertRulesMode
+	if mode == "" {
+		mode = models.AlertRulesModeOverride
+	}
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"mode":     mode,
+		"group_id": server.GroupID,
+		"rules":    rules,
+	})
+}
+
+// GetGlobalAlertRules 获取全局告警规则
+func (c *ServerAlertController) GetGlobalAlertRules(ctx http.Context) http.Response {
+	rules, err := services.NewAlertService().GetScopeAlertRules(services.RuleScope{})
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取全局告警规则失败", err)
+	}
+	return utils.SuccessResponse(ctx, "获取成功", rules)
+}
+
+// UpdateGlobalAlertRules 更新全局告警规则，只更新请求中包含的规则类型
+func (c *ServerAlertController) UpdateGlobalAlertRules(ctx http.Context) http.Response {
+	var req map[string]interface{}
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	alertService := services.NewAlertService()
+	if err := alertService.ValidateAlertRules(req); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{}, req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "保存全局告警规则失败", err)
+	}
+
+	rules, _ := alertService.GetScopeAlertRules(services.RuleScope{})
+	return utils.SuccessResponse(ctx, "保存成功", rules)
+}
+
+// DeleteGlobalAlertRule 删除指定类型的全局告警规则
+func (c *ServerAlertController) DeleteGlobalAlertRule(ctx http.Context) http.Response {
+	ruleType := ctx.Request().Route("type")
+	if err := services.NewAlertService().DeleteAlertRule(services.RuleScope{}, ruleType); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// BacktestAlertRule 用历史指标回放阈值规则，返回规则会产生的告警和恢复事件，不发送通知
+func (c *ServerAlertController) BacktestAlertRule(ctx http.Context) http.Response {
+	type BacktestRequest struct {
+		Metric string `json:"metric" form:"metric"` // cpu、memory、disk
+		Rule   struct {
+			Warning  float64  `json:"warning" form:"warning"`
+			Critical float64  `json:"critical" form:"critical"`
+			For      string   `json:"for" form:"for"`
+			Recovery *float64 `json:"recovery" form:"recovery"`
+		} `json:"rule" form:"rule"`
+		ServerIDs []string `json:"server_ids" form:"server_ids"`
+		Start     string   `json:"start" form:"start"` // RFC3339 或 2006-01-02 15:04:05
+		End       string   `json:"end" form:"end"`
+	}
+
+	var req BacktestRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	start, err := parseRequestTime(req.Start)
+	if err != nil || start == nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "开始时间格式无效")
+	}
+	end, err := parseRequestTime(req.End)
+	if err != nil || end == nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "结束时间格式无效")
+	}
+
+	result, err := services.NewAlertService().BacktestRule(services.BacktestOptions{
+		Metric: req.Metric,
+		Rule: services.Rule{
+			Enabled:  true,
+			Warning:  req.Rule.Warning,
+			Critical: req.Rule.Critical,
+			For:      req.Rule.For,
+			Recovery: req.Rule.Recovery,
+		},
+		ServerIDs: req.ServerIDs,
+		Start:     *start,
+		End:       *end,
+	})
+	if err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "回测完成", result)
+}
+
+// GetServerNotificationSubscriptions 获取服务器对各通知渠道的订阅情况及来源
+func (c *ServerAlertController) GetServerNotificationSubscriptions(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if server, err := repositories.GetServerRepository().GetByID(serverID); err != nil || server == nil || server.ID == "" {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "服务器不存在")
+	}
+
+	subscriptions, err := services.NewAlertService().GetNotificationSubscriptions(services.RuleScope{ServerID: &serverID})
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取服务器通知订阅失败", err)
+	}
+	return utils.SuccessResponse(ctx, "获取成功", subscriptions)
+}
+
+// UpdateServerNotificationSubscriptions 替换服务器的通知渠道订阅，未列出的渠道继承分组或渠道的默认设置
+func (c *ServerAlertController) UpdateServerNotificationSubscriptions(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if server, err := repositories.GetServerRepository().GetByID(serverID); err != nil || server == nil || server.ID == "" {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "服务器不存在")
+	}
+
+	var req NotificationSubscriptionRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	alertService := services.NewAlertService()
+	scope := services.RuleScope{ServerID: &serverID}
+	if err := alertService.SaveNotificationSubscriptions(scope, req.toMap()); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	subscriptions, _ := alertService.GetNotificationSubscriptions(scope)
+	return utils.SuccessResponse(ctx, "保存成功", subscriptions)
+}
@@ -102,27 +102,6 @@ func (c *ServerController) CreateServer(ctx http.Context) http.Response {
 		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "创建服务器失败", err)
 	}
 
-	// 创建服务器后，默认开启所有已配置的全局通知渠道
-	alertService := services.NewAlertService()
-	notificationRepo := repositories.GetAlertNotificationRepository()
-	globalNotifications, err := notificationRepo.GetAll()
-	if err == nil {
-		// 获取所有已配置且启用的全局通知渠道
-		channels := make(map[string]bool)
-		for _, notif := range globalNotifications {
-			if notif.Enabled && notif.ConfigJson != "" {
-				// 默认开启已配置的全局通知渠道
-				channels[notif.NotificationType] = true
-			}
-		}
-		// 保存服务器通知渠道配置
-		if len(channels) > 0 {
-			if err := alertService.SaveServerNotificationChannels(serverID, channels); err != nil {
-				facades.Log().Warningf("创建服务器默认通知渠道配置失败: %v", err)
-			}
-		}
-	}
-
 	facades.Log().Infof("成功创建服务器: %s (IP: %s)", req.Name, req.IP)
 
 	// 返回服务器信息和agent_key
@@ -145,6 +124,10 @@ func (c *ServerController) GetServers(ctx http.Context) http.Response {
 		userType = "guest" // 默认为游客
 	}
 
+	// 获取 Agent 版本筛选参数（仅管理员可用）
+	agentVersionFilter := services.NormalizeAgentVersion(ctx.Request().Query("agent_version"))
+	agentStatusFilter := ctx.Request().Query("agent_status")
+
 	// 获取分组筛选参数
 	groupIDStr := ctx.Request().Query("group_id")
 	var groupID *uint
@@ -180,6 +163,28 @@ func (c *ServerController) GetServers(ctx http.Context) http.Response {
 		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取服务器列表失败", err)
 	}
 
+	// 按 Agent 版本或版本状态筛选
+	if isAdmin && (agentVersionFilter != "" || agentStatusFilter != "") {
+		latestVersion := ""
+		minSupportedVersion := ""
+		if agentStatusFilter != "" {
+			latestVersion = services.GetLatestAgentVersion(agentReleaseUrls)
+			minSupportedVersion = services.GetMinSupportedAgentVersion()
+		}
+		filtered := make([]*models.Server, 0, len(allServers))
+		for _, s := range allServers {
+			version := services.NormalizeAgentVersion(s.AgentVersion)
+			if agentVersionFilter != "" && version != agentVersionFilter {
+				continue
+			}
+			if agentStatusFilter != "" && services.GetAgentVersionStatus(version, latestVersion, minSupportedVersion) != agentStatusFilter {
+				continue
+			}
+			filtered = append(filtered, s)
+		}
+		allServers = filtered
+	}
+
 	// 收集所有服务器ID
 	serverIDs := make([]string, 0, len(allServers))
 	for _, s := range allServers {
@@ -199,6 +204,10 @@ func (c *ServerController) GetServers(ctx http.Context) http.Response {
 		serversWithDisks = allServers
 	}
 
+	// 获取生效中的静默规则，用于标记维护中的服务器
+	now := time.Now()
+	activeSilences := services.NewAlertService().GetActiveSilences(now)
+
 	servers := make([]map[string]interface{}, 0, len(allServers))
 	for _, server := range serversWithDisks {
 		serverData := map[string]interface{}{
@@ -256,6 +265,15 @@ func (c *ServerController) GetServers(ctx http.Context) http.Response {
 		// 计算运行时间
 		serverData["uptime"] = services.CalculateUptime(server.BootTime, nil)
 
+		// 维护状态（存在覆盖整台服务器的生效静默规则）
+		serverData["in_maintenance"] = false
+		if silence := services.FindMaintenanceSilence(activeSilences, server.ID, server.GroupID); silence != nil {
+			serverData["in_maintenance"] = true
+			if isAdmin {
+				serverData["maintenance"] = services.MaintenanceInfo(silence, now)
+			}
+		}
+
 		// 设置指标数据
 		if metric, exists := latestMetrics[server.ID]; exists {
 			serverData["metrics"] = map[string]interface{}{
@@ -391,6 +409,17 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 	// 计算运行时间
 	serverData["uptime"] = services.CalculateUptime(server.BootTime, nil)
 
+	// 维护状态（存在覆盖整台服务器的生效静默规则）
+	now := time.Now()
+	serverData["in_maintenance"] = false
+	activeSilences := services.NewAlertService().GetActiveSilences(now)
+	if silence := services.FindMaintenanceSilence(activeSilences, server.ID, server.GroupID); silence != nil {
+		serverData["in_maintenance"] = true
+		if isAdmin {
+			serverData["maintenance"] = services.MaintenanceInfo(silence, now)
+		}
+	}
+
 	// 处理磁盘信息
 	disks := make([]map[string]interface{}, 0, len(server.ServerDisks))
 	for _, disk := range server.ServerDisks {
@@ -409,6 +438,13 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 	serverData["disks"] = disks
 	serverData["cpus"] = []map[string]interface{}{}
 
+	// 磁盘写满预测（按近期磁盘使用率趋势推算，数据不足时为空）
+	diskForecast, err := services.NewAlertService().GetDiskForecast(server.ID, now)
+	if err != nil {
+		facades.Log().Warningf("预测磁盘写满时间失败: %v", err)
+	}
+	serverData["disk_forecast"] = diskForecast
+
 	// 处理内存信息
 	if len(server.ServerMemoryHistory) > 0 {
 		mem := server.ServerMemoryHistory[0]
@@ -469,6 +505,8 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 	// 如果是管理员，添加 agent_version
 	if isAdmin {
 		serverData["agent_version"] = server.AgentVersion
+		serverData["alert_rules_mode"] = server.AlertRulesMode
+		serverData["alert_profile_id"] = server.AlertProfileID
 	}
 
 	// 获取告警规则
@@ -492,13 +530,33 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 				"warning":  rules.Disk.Warning,
 				"critical": rules.Disk.Critical,
 			},
+			"gpu_util": map[string]interface{}{
+				"enabled":  rules.GPUUtil.Enabled,
+				"warning":  rules.GPUUtil.Warning,
+				"critical": rules.GPUUtil.Critical,
+			},
+			"gpu_mem": map[string]interface{}{
+				"enabled":  rules.GPUMem.Enabled,
+				"warning":  rules.GPUMem.Warning,
+				"critical": rules.GPUMem.Critical,
+			},
+			"gpu_temp": map[string]interface{}{
+				"enabled":  rules.GPUTemp.Enabled,
+				"warning":  rules.GPUTemp.Warning,
+				"critical": rules.GPUTemp.Critical,
+			},
+			"temperature": map[string]interface{}{
+				"enabled":  rules.Temperature.Enabled,
+				"warning":  rules.Temperature.Warning,
+				"critical": rules.Temperature.Critical,
+			},
 		}
 
 		// 获取其他类型的告警规则（bandwidth, traffic, expiration）
 		ruleRepo := repositories.GetServerAlertRuleRepository()
 
 		// bandwidth: {enabled: bool, threshold: number}
-		bandwidthRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "bandwidth")
+		bandwidthRule, err := ruleRepo.GetEffectiveByType(serverID, "bandwidth")
 		if err == nil && bandwidthRule != nil {
 			var ruleConfig map[string]interface{}
 			if err := json.Unmarshal([]byte(bandwidthRule.Config), &ruleConfig); err == nil {
@@ -519,7 +577,7 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 		}
 
 		// traffic: {enabled: bool, threshold_percent: number}
-		trafficRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "traffic")
+		trafficRule, err := ruleRepo.GetEffectiveByType(serverID, "traffic")
 		if err == nil && trafficRule != nil {
 			var ruleConfig map[string]interface{}
 			if err := json.Unmarshal([]byte(trafficRule.Config), &ruleConfig); err == nil {
@@ -540,7 +598,7 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 		}
 
 		// expiration: {enabled: bool, alert_days: number}
-		expirationRule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "expiration")
+		expirationRule, err := ruleRepo.GetEffectiveByType(serverID, "expiration")
 		if err == nil && expirationRule != nil {
 			var ruleConfig map[string]interface{}
 			if err := json.Unmarshal([]byte(expirationRule.Config), &ruleConfig); err == nil {
@@ -560,6 +618,27 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 			}
 		}
 
+		// service: {enabled: bool}
+		serviceRule, err := ruleRepo.GetEffectiveByType(serverID, "service")
+		if err == nil && serviceRule != nil {
+			var ruleConfig map[string]interface{}
+			if err := json.Unmarshal([]byte(serviceRule.Config), &ruleConfig); err == nil {
+				alertRulesData["service"] = ruleConfig
+			} else {
+				alertRulesData["service"] = map[string]interface{}{
+					"enabled": false,
+				}
+			}
+		} else {
+			alertRulesData["service"] = map[string]interface{}{
+				"enabled": false,
+			}
+		}
+
+		// anomaly、disk_forecast: 未配置的字段使用默认值
+		alertRulesData["anomaly"] = alertService.GetAnomalyRule(serverID)
+		alertRulesData["disk_forecast"] = alertService.GetDiskForecastRule(serverID)
+
 		serverData["alert_rules"] = alertRulesData
 	}
 
@@ -568,6 +647,10 @@ func (c *ServerController) GetServerDetail(ctx http.Context) http.Response {
 	if err == nil {
 		serverData["notification_channels"] = notificationChannels
 	}
+	notificationSubscriptions, err := alertService.GetNotificationSubscriptions(services.RuleScope{ServerID: &serverID})
+	if err == nil {
+		serverData["notification_subscriptions"] = notificationSubscriptions
+	}
 
 	// 添加Agent配置字段
 	if server.AgentTimezone != "" {
@@ -609,6 +692,16 @@ func (c *ServerController) GetServerMetricsNetwork(ctx http.Context) http.Respon
 	return c.getServerMetricsByType(ctx, "network")
 }
 
+// GetServerMetricsGPU 获取服务器每块GPU的历史数据
+func (c *ServerController) GetServerMetricsGPU(ctx http.Context) http.Response {
+	return c.getServerMetricsByType(ctx, "gpu")
+}
+
+// GetServerMetricsTemperature 获取服务器各传感器温度历史数据
+func (c *ServerController) GetServerMetricsTemperature(ctx http.Context) http.Response {
+	return c.getServerMetricsByType(ctx, "temperature")
+}
+
 // getServerMetricsByType 根据类型获取服务器历史性能指标
 func (c *ServerController) getServerMetricsByType(ctx http.Context, metricType string) http.Response {
 	serverID := ctx.Request().Route("id")
@@ -875,6 +968,102 @@ func (c *ServerController) getServerMetricsByType(ctx http.Context, metricType s
 
 		err = facades.Orm().Query().Raw(sql, args...).Scan(&metrics)
 
+	case "gpu":
+		// 按 GPU 序号分别聚合，前端根据 gpu_index 拆分为多条曲线
+		sampleIntervalSeconds := sampleIntervalMinutes * 60
+		sql := `SELECT 
+			datetime(CAST((timestamp_unix / ?) * ? AS INTEGER), 'unixepoch') AS timestamp,
+			gpu_index,
+			AVG(utilization) AS gpu_util,
+			AVG(memory_used) AS gpu_mem_used,
+			AVG(memory_total) AS gpu_mem_total,
+			AVG(temperature) AS gpu_temp,
+			AVG(power_draw) AS gpu_power
+		FROM (
+			SELECT 
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END AS timestamp_unix,
+				gpu_index,
+				utilization,
+				memory_used,
+				memory_total,
+				temperature,
+				power_draw
+			FROM server_gpu_metrics
+			WHERE server_id = ? 
+			AND (
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END
+			) >= ? 
+			AND (
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END
+			) <= ?
+		)
+		GROUP BY gpu_index, timestamp_unix / ?
+		ORDER BY timestamp ASC, gpu_index ASC`
+		args := []interface{}{sampleIntervalSeconds, sampleIntervalSeconds, serverID, startTime.Unix()}
+		if endParam != "" {
+			args = append(args, endTime.Unix())
+		} else {
+			args = append(args, time.Now().Unix())
+		}
+		args = append(args, sampleIntervalSeconds)
+
+		err = facades.Orm().Query().Raw(sql, args...).Scan(&metrics)
+
+	case "temperature":
+		// 按传感器分别聚合，前端根据 sensor_key 拆分为多条曲线
+		sampleIntervalSeconds := sampleIntervalMinutes * 60
+		sql := `SELECT 
+			datetime(CAST((timestamp_unix / ?) * ? AS INTEGER), 'unixepoch') AS timestamp,
+			sensor_key,
+			sensor_type,
+			label,
+			AVG(temperature) AS temperature
+		FROM (
+			SELECT 
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END AS timestamp_unix,
+				sensor_key,
+				sensor_type,
+				label,
+				temperature
+			FROM server_sensor_readings
+			WHERE server_id = ? 
+			AND (
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END
+			) >= ? 
+			AND (
+				CASE 
+					WHEN typeof(timestamp) = 'integer' THEN timestamp
+					ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+				END
+			) <= ?
+		)
+		GROUP BY sensor_key, timestamp_unix / ?
+		ORDER BY timestamp ASC, sensor_key ASC`
+		args := []interface{}{sampleIntervalSeconds, sampleIntervalSeconds, serverID, startTime.Unix()}
+		if endParam != "" {
+			args = append(args, endTime.Unix())
+		} else {
+			args = append(args, time.Now().Unix())
+		}
+		args = append(args, sampleIntervalSeconds)
+
+		err = facades.Orm().Query().Raw(sql, args...).Scan(&metrics)
+
 	default:
 		return ctx.Response().Status(http.StatusBadRequest).Json(http.Json{
 			"status":  false,
@@ -914,6 +1103,15 @@ func (c *ServerController) getServerMetricsByType(ctx http.Context, metricType s
 			case "network":
 				dataPoint["network_upload"] = 0.0
 				dataPoint["network_download"] = 0.0
+			case "gpu":
+				dataPoint["gpu_index"] = 0
+				dataPoint["gpu_util"] = 0.0
+				dataPoint["gpu_mem_used"] = 0.0
+				dataPoint["gpu_mem_total"] = 0.0
+				dataPoint["gpu_temp"] = 0.0
+				dataPoint["gpu_power"] = 0.0
+			case "temperature":
+				dataPoint["temperature"] = 0.0
 			}
 
 			metrics = append(metrics, dataPoint)
@@ -957,6 +1155,9 @@ func (c *ServerController) getServerMetricsByType(ctx http.Context, metricType s
 			if key == "timestamp" {
 				continue // 跳过timestamp字段
 			}
+			if _, ok := value.(string); ok {
+				continue // 跳过传感器标识等文本字段
+			}
 			// 使用统一的格式化函数
 			metrics[i][key] = services.FormatMetricValue(value)
 		}
@@ -969,6 +1170,38 @@ func (c *ServerController) getServerMetricsByType(ctx http.Context, metricType s
 	})
 }
 
+// GetServerServiceHistory 获取服务器被监控服务的可用性历史
+func (c *ServerController) GetServerServiceHistory(ctx http.Context) http.Response {
+	serverID := ctx.Request().Route("id")
+	if serverID == "" {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "缺少服务器ID")
+	}
+
+	// 默认统计最近24小时，最长30天
+	hours := ctx.Request().QueryInt("hours", 24)
+	if hours <= 0 {
+		hours = 24
+	}
+	if hours > 24*30 {
+		hours = 24 * 30
+	}
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Duration(hours) * time.Hour)
+
+	alertService := services.NewAlertService()
+	history, err := alertService.GetServiceUptimeHistory(serverID, startTime, endTime)
+	if err != nil {
+		facades.Log().Errorf("获取服务可用性历史失败: %v", err)
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取服务可用性历史失败", err)
+	}
+
+	return utils.SuccessResponse(ctx, "获取成功", map[string]interface{}{
+		"start":    startTime.Unix(),
+		"end":      endTime.Unix(),
+		"services": history,
+	})
+}
+
 // UpdateServer 更新服务器信息
 func (c *ServerController) UpdateServer(ctx http.Context) http.Response {
 	serverID := ctx.Request().Route("id")
@@ -1002,6 +1235,8 @@ func (c *ServerController) UpdateServer(ctx http.Context) http.Response {
 		TrafficCustomCycleDays *int                    `json:"traffic_custom_cycle_days" form:"traffic_custom_cycle_days"`
 		AlertRules             *map[string]interface{} `json:"alert_rules" form:"alert_rules"`
 		NotificationChannels   *map[string]bool        `json:"notification_channels" form:"notification_channels"`
+		AlertRulesMode         *string                 `json:"alert_rules_mode" form:"alert_rules_mode"`
+		AlertProfileID         *uint                   `json:"alert_profile_id" form:"alert_profile_id"` // 0 表示取消告警配置模板
 		// Agent配置字段
 		AgentTimezone          *string   `json:"agent_timezone" form:"agent_timezone"`
 		AgentMetricsInterval   *int      `json:"agent_metrics_interval" form:"agent_metrics_interval"`
@@ -1025,6 +1260,17 @@ func (c *ServerController) UpdateServer(ctx http.Context) http.Response {
 		})
 	}
 
+	// 校验告警规则的重复通知配置
+	alertService := services.NewAlertService()
+	if req.AlertRules != nil {
+		if err := alertService.ValidateAlertRules(*req.AlertRules); err != nil {
+			return ctx.Response().Status(http.StatusBadRequest).Json(http.Json{
+				"status":  false,
+				"message": err.Error(),
+			})
+		}
+	}
+
 	// 构建更新数据
 	updateData := make(map[string]interface{})
 	if req.Name != "" {
@@ -1077,6 +1323,25 @@ func (c *ServerController) UpdateServer(ctx http.Context) http.Response {
 		updateData["traffic_custom_cycle_days"] = *req.TrafficCustomCycleDays
 	}
 
+	// 告警规则模式
+	if req.AlertRulesMode != nil {
+		if *req.AlertRulesMode != models.AlertRulesModeOverride && *req.AlertRulesMode != models.AlertRulesModeInherit {
+			return utils.ErrorResponse(ctx, http.StatusBadRequest, "告警规则模式只能为 override 或 inherit")
+		}
+		updateData["alert_rules_mode"] = *req.AlertRulesMode
+	}
+
+	// 告警配置模板
+	if req.AlertProfileID != nil {
+		if *req.AlertProfileID == 0 {
+			updateData["alert_profile_id"] = nil
+		} else if !alertProfileExists(req.AlertProfileID) {
+			return utils.ErrorResponse(ctx, http.StatusBadRequest, "告警配置模板不存在")
+		} else {
+			updateData["alert_profile_id"] = *req.AlertProfileID
+		}
+	}
+
 	// 处理Agent配置字段
 	// 用于发送给Agent的配置更新
 	configUpdate := make(map[string]interface{})
@@ -1138,98 +1403,10 @@ func (c *ServerController) UpdateServer(ctx http.Context) http.Response {
 	}
 
 	// 处理告警规则和通知渠道
-	alertService := services.NewAlertService()
 	if req.AlertRules != nil {
-		serverIDPtr := &serverID
-		rules := make(map[string]services.Rule)
-
-		// 处理基础资源规则（cpu, memory, disk）
-		// 无论 enabled 是 true 还是 false，只要规则数据存在就保存
-		if cpuRule, ok := (*req.AlertRules)["cpu"].(map[string]interface{}); ok {
-			enabled := false
-			if e, ok := cpuRule["enabled"].(bool); ok {
-				enabled = e
-			}
-			warning, _ := cpuRule["warning"].(float64)
-			critical, _ := cpuRule["critical"].(float64)
-			// 如果没有设置阈值，使用默认值
-			if warning == 0 {
-				warning = 80
-			}
-			if critical == 0 {
-				critical = 90
-			}
-			rules["cpu"] = services.Rule{
-				Enabled:  enabled,
-				Warning:  warning,
-				Critical: critical,
-			}
-		}
-		if memoryRule, ok := (*req.AlertRules)["memory"].(map[string]interface{}); ok {
-			enabled := false
-			if e, ok := memoryRule["enabled"].(bool); ok {
-				enabled = e
-			}
-			warning, _ := memoryRule["warning"].(float64)
-			critical, _ := memoryRule["critical"].(float64)
-			// 如果没有设置阈值，使用默认值
-			if warning == 0 {
-				warning = 85
-			}
-			if critical == 0 {
-				critical = 95
-			}
-			rules["memory"] = services.Rule{
-				Enabled:  enabled,
-				Warning:  warning,
-				Critical: critical,
-			}
-		}
-		if diskRule, ok := (*req.AlertRules)["disk"].(map[string]interface{}); ok {
-			enabled := false
-			if e, ok := diskRule["enabled"].(bool); ok {
-				enabled = e
-			}
-			warning, _ := diskRule["warning"].(float64)
-			critical, _ := diskRule["critical"].(float64)
-			// 如果没有设置阈值，使用默认值
-			if warning == 0 {
-				warning = 85
-			}
-			if critical == 0 {
-				critical = 95
-			}
-			rules["disk"] = services.Rule{
-				Enabled:  enabled,
-				Warning:  warning,
-				Critical: critical,
-			}
-		}
-
-		// 保存基础资源规则
-		if len(rules) > 0 {
-			if err := alertService.SaveServerRules(serverIDPtr, rules); err != nil {
-				facades.Log().Warningf("保存告警规则失败: %v", err)
-			}
-		}
-
-		// 处理其他类型的告警规则（bandwidth, traffic, expiration）
-		ruleRepo := repositories.GetServerAlertRuleRepository()
-		ruleTypes := []string{"bandwidth", "traffic", "expiration"}
-		for _, ruleType := range ruleTypes {
-			if ruleData, ok := (*req.AlertRules)[ruleType].(map[string]interface{}); ok {
-				configJson, err := json.Marshal(ruleData)
-				if err == nil {
-					rule := &models.ServerAlertRule{
-						ServerID: serverIDPtr,
-						RuleType: ruleType,
-						Config:   string(configJson),
-					}
-					if err := ruleRepo.CreateOrUpdate(rule); err != nil {
-						facades.Log().Warningf("保存告警规则 %s 失败: %v", ruleType, err)
-					}
-				}
-			}
+		// 阈值规则无论 enabled 是 true 还是 false，只要规则数据存在就保存
+		if err := alertService.SaveAlertRules(services.RuleScope{ServerID: &serverID}, *req.AlertRules); err != nil {
+			facades.Log().Warningf("保存告警规则失败: %v", err)
 		}
 	}
 
@@ -1312,7 +1489,7 @@ func (c *ServerController) DeleteServer(ctx http.Context) http.Response {
 	// 删除所有关联表的数据
 	tables := []string{
 		"server_alert_rules",
-		"server_notification_channels",
+		"notification_subscriptions",
 		"server_metrics",
 		"server_disks",
 		"server_status_logs",
@@ -1323,6 +1500,10 @@ func (c *ServerController) DeleteServer(ctx http.Context) http.Response {
 		"server_traffic_usage",
 		"server_network_speed",
 		"server_disk_io",
+		"server_gpu_metrics",
+		"server_sensor_readings",
+		"server_metric_baselines",
+		"agent_rollout_servers",
 		"alerts",
 		"service_monitor_rule_servers",
 		"service_monitor_alerts",
@@ -1346,6 +1527,9 @@ func (c *ServerController) DeleteServer(ctx http.Context) http.Response {
 		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "删除服务器失败", err)
 	}
 
+	// 清除内存中的指标窗口
+	services.GetMetricWindow().Remove(serverID)
+
 	facades.Log().Infof("成功删除服务器: %s", serverID)
 
 	return utils.SuccessResponse(ctx, "删除成功")
@@ -3,6 +3,7 @@ package controllers
 import (
 	"goravel/app/models"
 	"goravel/app/repositories"
+	"goravel/app/services"
 	"goravel/app/utils"
 	"strconv"
 
@@ -31,9 +32,11 @@ func (c *ServerGroupController) GetGroups(ctx http.Context) http.Response {
 // CreateGroup 创建分组
 func (c *ServerGroupController) CreateGroup(ctx http.Context) http.Response {
 	type CreateGroupRequest struct {
-		Name        string `json:"name" form:"name"`
-		Description string `json:"description" form:"description"`
-		Color       string `json:"color" form:"color"`
+		Name               string `json:"name" form:"name"`
+		Description        string `json:"description" form:"description"`
+		Color              string `json:"color" form:"color"`
+		EscalationPolicyID *uint  `json:"escalation_policy_id" form:"escalation_policy_id"`
+		AlertProfileID     *uint  `json:"alert_profile_id" form:"alert_profile_id"`
 	}
 
 	var req CreateGroupRequest
@@ -46,10 +49,19 @@ func (c *ServerGroupController) CreateGroup(ctx http.Context) http.Response {
 		return utils.ErrorResponse(ctx, http.StatusBadRequest, "分组名称为必填项")
 	}
 
+	if !escalationPolicyExists(req.EscalationPolicyID) {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "升级策略不存在")
+	}
+	if !alertProfileExists(req.AlertProfileID) {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "告警配置模板不存在")
+	}
+
 	group := &models.ServerGroup{
-		Name:        req.Name,
-		Description: req.Description,
-		Color:       req.Color,
+		Name:               req.Name,
+		Description:        req.Description,
+		Color:              req.Color,
+		EscalationPolicyID: req.EscalationPolicyID,
+		AlertProfileID:     req.AlertProfileID,
 	}
 
 	groupRepo := repositories.GetServerGroupRepository()
@@ -71,9 +83,11 @@ func (c *ServerGroupController) UpdateGroup(ctx http.Context) http.Response {
 	}
 
 	type UpdateGroupRequest struct {
-		Name        string `json:"name" form:"name"`
-		Description string `json:"description" form:"description"`
-		Color       string `json:"color" form:"color"`
+		Name               string `json:"name" form:"name"`
+		Description        string `json:"description" form:"description"`
+		Color              string `json:"color" form:"color"`
+		EscalationPolicyID *uint  `json:"escalation_policy_id" form:"escalation_policy_id"`
+		AlertProfileID     *uint  `json:"alert_profile_id" form:"alert_profile_id"`
 	}
 
 	var req UpdateGroupRequest
@@ -87,9 +101,18 @@ func (c *ServerGroupController) UpdateGroup(ctx http.Context) http.Response {
 		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
 	}
 
+	if !escalationPolicyExists(req.EscalationPolicyID) {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "升级策略不存在")
+	}
+	if !alertProfileExists(req.AlertProfileID) {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, "告警配置模板不存在")
+	}
+
 	group.Name = req.Name
 	group.Description = req.Description
 	group.Color = req.Color
+	group.EscalationPolicyID = req.EscalationPolicyID
+	group.AlertProfileID = req.AlertProfileID
 
 	if err := groupRepo.Update(group); err != nil {
 		facades.Log().Errorf("更新分组失败: %v", err)
@@ -115,3 +138,139 @@ func (c *ServerGroupController) DeleteGroup(ctx http.Context) http.Response {
 
 	return utils.SuccessResponse(ctx, "删除成功", nil)
 }
+
+// GetGroupAlertRules 获取分组的告警规则
+func (c *ServerGroupController) GetGroupAlertRules(ctx http.Context) http.Response {
+	groupID, ok := c.groupIDFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
+	}
+
+	rules, err := services.NewAlertService().GetScopeAlertRules(services.RuleScope{GroupID: &groupID})
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取分组告警规则失败", err)
+	}
+	return utils.SuccessResponse(ctx, "获取成功", rules)
+}
+
+// UpdateGroupAlertRules 更新分组的告警规则，只更新请求中包含的规则类型
+func (c *ServerGroupController) UpdateGroupAlertRules(ctx http.Context) http.Response {
+	groupID, ok := c.groupIDFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
+	}
+
+	var req map[string]interface{}
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	alertService := services.NewAlertService()
+	scope := services.RuleScope{GroupID: &groupID}
+	if err := alertService.ValidateAlertRules(req); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	if err := alertService.SaveAlertRules(scope, req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "保存分组告警规则失败", err)
+	}
+
+	rules, _ := alertService.GetScopeAlertRules(scope)
+	return utils.SuccessResponse(ctx, "保存成功", rules)
+}
+
+// DeleteGroupAlertRule 删除分组指定类型的告警规则，分组内服务器改为继承全局规则
+func (c *ServerGroupController) DeleteGroupAlertRule(ctx http.Context) http.Response {
+	groupID, ok := c.groupIDFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
+	}
+
+	if err := services.NewAlertService().DeleteAlertRule(services.RuleScope{GroupID: &groupID}, ctx.Request().Route("type")); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	return utils.SuccessResponse(ctx, "删除成功")
+}
+
+// NotificationSubscriptionRequest 订阅通知渠道的请求，未列出的渠道继承上级设置
+type NotificationSubscriptionRequest struct {
+	Subscriptions []struct {
+		ChannelID uint `json:"channel_id" form:"channel_id"`
+		Enabled   bool `json:"enabled" form:"enabled"`
+	} `json:"subscriptions" form:"subscriptions"`
+}
+
+// toMap 转换为以渠道ID为键的订阅状态
+func (r *NotificationSubscriptionRequest) toMap() map[uint]bool {
+	subscriptions := make(map[uint]bool, len(r.Subscriptions))
+	for _, subscription := range r.Subscriptions {
+		subscriptions[subscription.ChannelID] = subscription.Enabled
+	}
+	return subscriptions
+}
+
+// GetGroupNotificationSubscriptions 获取分组对各通知渠道的订阅情况
+func (c *ServerGroupController) GetGroupNotificationSubscriptions(ctx http.Context) http.Response {
+	groupID, ok := c.groupIDFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
+	}
+
+	subscriptions, err := services.NewAlertService().GetNotificationSubscriptions(services.RuleScope{GroupID: &groupID})
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取分组通知订阅失败", err)
+	}
+	return utils.SuccessResponse(ctx, "获取成功", subscriptions)
+}
+
+// UpdateGroupNotificationSubscriptions 替换分组的通知渠道订阅，分组内未单独订阅的服务器使用分组的订阅
+func (c *ServerGroupController) UpdateGroupNotificationSubscriptions(ctx http.Context) http.Response {
+	groupID, ok := c.groupIDFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, http.StatusNotFound, "分组不存在")
+	}
+
+	var req NotificationSubscriptionRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, http.StatusBadRequest, "请求参数错误", err)
+	}
+
+	alertService := services.NewAlertService()
+	scope := services.RuleScope{GroupID: &groupID}
+	if err := alertService.SaveNotificationSubscriptions(scope, req.toMap()); err != nil {
+		return utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	subscriptions, _ := alertService.GetNotificationSubscriptions(scope)
+	return utils.SuccessResponse(ctx, "保存成功", subscriptions)
+}
+
+// groupIDFromRoute 从路由参数中获取分组ID，并检查分组是否存在
+func (c *ServerGroupController) groupIDFromRoute(ctx http.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	group, err := repositories.GetServerGroupRepository().GetByID(uint(id))
+	if err != nil || group == nil || group.ID == 0 {
+		return 0, false
+	}
+	return group.ID, true
+}
+
+// escalationPolicyExists 检查升级策略是否存在，未指定策略时视为有效
+func escalationPolicyExists(policyID *uint) bool {
+	if policyID == nil {
+		return true
+	}
+	policy, err := repositories.GetAlertEscalationPolicyRepository().GetByID(*policyID)
+	return err == nil && policy != nil
+}
+
+// alertProfileExists 检查告警配置模板是否存在，未指定模板时视为有效
+func alertProfileExists(profileID *uint) bool {
+	if profileID == nil {
+		return true
+	}
+	profile, err := repositories.GetAlertProfileRepository().GetByID(*profileID)
+	return err == nil && profile != nil
+}
@@ -3,9 +3,13 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"goravel/app/models"
 	"goravel/app/repositories"
+	"goravel/app/services"
 	"goravel/app/utils"
 	"goravel/app/utils/notification"
+	"goravel/app/utils/notifytemplate"
+	"goravel/app/utils/secret"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +47,7 @@ func (r *SettingsController) GetPublicSettings(ctx http.Context) http.Response {
 func (r *SettingsController) GetPanelSettings(ctx http.Context) http.Response {
 	panelTitle := utils.GetSetting("panel_title", "CloudSentinel 云哨")
 	logRetentionDays := utils.GetSetting("log_retention_days", "30")
+	agentMinSupportedVersion := utils.GetSetting("agent_min_supported_version", "")
 
 	// 提取当前版本类型
 	currentVersion := facades.Config().GetString("app.version", "0.0.1-release")
@@ -53,10 +58,11 @@ func (r *SettingsController) GetPanelSettings(ctx http.Context) http.Response {
 	}
 
 	return utils.SuccessResponse(ctx, "success", map[string]any{
-		"panel_title":          panelTitle,
-		"log_retention_days":   logRetentionDays,
-		"current_version":      currentVersion,
-		"current_version_type": currentVersionType,
+		"panel_title":                 panelTitle,
+		"log_retention_days":          logRetentionDays,
+		"agent_min_supported_version": agentMinSupportedVersion,
+		"current_version":             currentVersion,
+		"current_version_type":        currentVersionType,
 	})
 }
 
@@ -160,22 +166,42 @@ func (r *SettingsController) GetAlertsSettings(ctx http.Context) http.Response {
 		"security":    email.Config["security"],
 		"from":        email.Config["from"],
 		"to":          email.Config["to"],
+		"cc":          email.Config["cc"],
+		"bcc":         email.Config["bcc"],
+		"username":    email.Config["username"],
+		"auth_method": email.Config["auth_method"],
+		"skip_verify": email.Config["skip_verify"],
+		"ca_cert":     email.Config["ca_cert"],
 		"hasPassword": hasPassword,
 	}
+	// 检查钉钉加签密钥是否已设置
+	hasSecret := false
+	if webhookSecret, ok := webhook.Config["secret"].(string); ok && webhookSecret != "" {
+		hasSecret = true
+	}
+
 	webhookData := map[string]any{
-		"enabled":   webhook.Enabled,
-		"webhook":   webhook.Config["webhook"],
-		"mentioned": webhook.Config["mentioned"],
-		"platform":  webhook.Config["platform"],
+		"enabled":      webhook.Enabled,
+		"webhook":      webhook.Config["webhook"],
+		"mentioned":    webhook.Config["mentioned"],
+		"platform":     webhook.Config["platform"],
+		"keyword":      webhook.Config["keyword"],
+		"message_type": webhook.Config["message_type"],
+		"hasSecret":    hasSecret,
 	}
 
 	// 是否已配置任意一个通知渠道
-	hasNotificationChannel := isEmailChannelConfigured(email) || isWebhookChannelConfigured(webhook)
+	hasNotificationChannel := isEmailChannelConfigured(email) || isWebhookChannelConfigured(webhook) || hasExtraNotificationChannel()
 
 	// 服务器离线/上线告警开关
 	settingRepo := repositories.GetSystemSettingRepository()
 	alertServerOfflineEnabled := settingRepo.GetBool("alert_server_offline_enabled", false)
 	alertServerOnlineEnabled := settingRepo.GetBool("alert_server_online_enabled", false)
+	// 告警合并窗口（秒），为 0 表示不合并
+	alertGroupWindow := settingRepo.GetInt("alert_group_window", 0)
+	// 通知发送失败后的最大尝试次数和首次重试间隔（秒）
+	notificationRetryMaxAttempts := settingRepo.GetInt("notification_retry_max_attempts", 5)
+	notificationRetryBackoff := settingRepo.GetInt("notification_retry_backoff", 60)
 
 	return ctx.Response().Success().Json(http.Json{
 		"status":  true,
@@ -185,9 +211,12 @@ func (r *SettingsController) GetAlertsSettings(ctx http.Context) http.Response {
 				"email":   emailData,
 				"webhook": webhookData,
 			},
-			"hasNotificationChannel":   hasNotificationChannel,
-			"alertServerOfflineEnabled": alertServerOfflineEnabled,
-			"alertServerOnlineEnabled":  alertServerOnlineEnabled,
+			"hasNotificationChannel":       hasNotificationChannel,
+			"alertServerOfflineEnabled":    alertServerOfflineEnabled,
+			"alertServerOnlineEnabled":     alertServerOnlineEnabled,
+			"alertGroupWindow":             alertGroupWindow,
+			"notificationRetryMaxAttempts": notificationRetryMaxAttempts,
+			"notificationRetryBackoff":     notificationRetryBackoff,
 		},
 	})
 }
@@ -195,6 +224,7 @@ func (r *SettingsController) GetAlertsSettings(ctx http.Context) http.Response {
 func (r *SettingsController) UpdatePanelSettings(ctx http.Context) http.Response {
 	title := ctx.Request().Input("title")
 	logRetentionDays := ctx.Request().Input("log_retention_days")
+	agentMinSupportedVersion, hasAgentMinSupportedVersion := ctx.Request().All()["agent_min_supported_version"]
 
 	if title == "" {
 		return utils.ErrorResponse(ctx, 422, "缺少标题参数")
@@ -214,6 +244,14 @@ func (r *SettingsController) UpdatePanelSettings(ctx http.Context) http.Response
 		}
 	}
 
+	// 最低支持的 Agent 版本，允许置空表示不限制
+	if hasAgentMinSupportedVersion {
+		version, _ := agentMinSupportedVersion.(string)
+		if err := settingRepo.SetValue("agent_min_supported_version", services.NormalizeAgentVersion(version)); err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "更新最低Agent版本失败", err)
+		}
+	}
+
 	return utils.SuccessResponse(ctx, "success")
 }
 
@@ -411,29 +449,58 @@ func (r *SettingsController) UpdateAlertsSettings(ctx http.Context) http.Respons
 	}
 	webhookEnabled := ctx.Request().Input("notifications.webhook.enabled") == "true"
 	webhookCfg := map[string]any{
-		"webhook":   ctx.Request().Input("notifications.webhook.webhook"),
-		"mentioned": ctx.Request().Input("notifications.webhook.mentioned"),
-		"platform":  ctx.Request().Input("notifications.webhook.platform"),
+		"webhook":      ctx.Request().Input("notifications.webhook.webhook"),
+		"mentioned":    ctx.Request().Input("notifications.webhook.mentioned"),
+		"platform":     ctx.Request().Input("notifications.webhook.platform"),
+		"secret":       ctx.Request().Input("notifications.webhook.secret"),
+		"keyword":      ctx.Request().Input("notifications.webhook.keyword"),
+		"message_type": ctx.Request().Input("notifications.webhook.message_type"),
 	}
 	writeNotify := func(nType string, enabled bool, cfg map[string]any) error {
-		// 如果是邮件配置，处理密码逻辑
+		// 如果是邮件配置，处理密码逻辑，此处不编辑的收件人和连接安全配置保持不变
 		if nType == "email" {
-			password, _ := cfg["password"].(string)
-			// 如果密码为空，尝试读取旧配置中的密码
-			if password == "" {
-				oldNotification, err := notificationRepo.GetByType("email")
-				if err == nil && oldNotification != nil && oldNotification.ConfigJson != "" {
-					var oldCfg map[string]any
-					if err := json.Unmarshal([]byte(oldNotification.ConfigJson), &oldCfg); err == nil {
+			oldNotification, err := notificationRepo.GetByType("email")
+			if err == nil && oldNotification != nil && oldNotification.ConfigJson != "" {
+				var oldCfg map[string]any
+				if err := json.Unmarshal([]byte(oldNotification.ConfigJson), &oldCfg); err == nil {
+					// 如果密码为空，使用旧配置中的密码
+					if password, _ := cfg["password"].(string); password == "" {
 						if oldPwd, ok := oldCfg["password"].(string); ok {
 							cfg["password"] = oldPwd
 						}
 					}
+					for _, key := range []string{"cc", "bcc", "username", "auth_method", "skip_verify", "ca_cert"} {
+						if value, ok := oldCfg[key]; ok {
+							cfg[key] = value
+						}
+					}
+				}
+			}
+		}
+		// 如果是 Webhook 配置，加签密钥为空时保留旧配置中的密钥，此处不编辑的通用 Webhook 请求配置保持不变
+		if nType == "webhook" {
+			oldNotification, err := notificationRepo.GetByType("webhook")
+			if err == nil && oldNotification != nil && oldNotification.ConfigJson != "" {
+				var oldCfg map[string]any
+				if err := json.Unmarshal([]byte(oldNotification.ConfigJson), &oldCfg); err == nil {
+					if webhookSecret, _ := cfg["secret"].(string); webhookSecret == "" {
+						if oldSecret, ok := oldCfg["secret"].(string); ok {
+							cfg["secret"] = oldSecret
+						}
+					}
+					for _, key := range []string{"body_template", "headers", "method", "success_codes"} {
+						if value, ok := oldCfg[key]; ok {
+							cfg[key] = value
+						}
+					}
 				}
 			}
 		}
 
-		return notificationRepo.UpdateConfig(nType, cfg)
+		if err := notificationRepo.UpdateConfig(nType, cfg); err != nil {
+			return err
+		}
+		return notificationRepo.SetEnabled(nType, enabled)
 	}
 	if err := writeNotify("email", emailEnabled, emailCfg); err != nil {
 		return utils.ErrorResponseWithError(ctx, 500, "更新邮件通知失败", err)
@@ -450,7 +517,7 @@ func (r *SettingsController) UpdateAlertsSettings(ctx http.Context) http.Respons
 		strings.TrimSpace(fmt.Sprint(emailCfg["from"])) != "" && strings.TrimSpace(fmt.Sprint(emailCfg["to"])) != ""
 	webhookURL := strings.TrimSpace(fmt.Sprint(webhookCfg["webhook"]))
 	webhookConfigured := webhookEnabled && webhookURL != "" && (strings.HasPrefix(webhookURL, "http://") || strings.HasPrefix(webhookURL, "https://"))
-	hasChannel := emailConfigured || webhookConfigured
+	hasChannel := emailConfigured || webhookConfigured || hasExtraNotificationChannel()
 
 	if (alertServerOfflineEnabled || alertServerOnlineEnabled) && !hasChannel {
 		return utils.ErrorResponse(ctx, 422, "请先配置并启用至少一个通知渠道（邮件或 Webhook）后再开启服务器离线/上线告警")
@@ -463,6 +530,37 @@ func (r *SettingsController) UpdateAlertsSettings(ctx http.Context) http.Respons
 		return utils.ErrorResponseWithError(ctx, 500, "更新服务器上线告警设置失败", err)
 	}
 
+	// 告警合并窗口（秒），未传入时保持不变
+	if alertGroupWindow := ctx.Request().Input("alertGroupWindow"); alertGroupWindow != "" {
+		seconds, err := strconv.Atoi(alertGroupWindow)
+		if err != nil || seconds < 0 || seconds > 600 {
+			return utils.ErrorResponse(ctx, 422, "告警合并窗口需为 0-600 秒")
+		}
+		if err := settingRepo.SetValue("alert_group_window", strconv.Itoa(seconds)); err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "更新告警合并窗口失败", err)
+		}
+	}
+
+	// 通知重试次数和间隔，未传入时保持不变
+	if maxAttempts := ctx.Request().Input("notificationRetryMaxAttempts"); maxAttempts != "" {
+		attempts, err := strconv.Atoi(maxAttempts)
+		if err != nil || attempts < 1 || attempts > 20 {
+			return utils.ErrorResponse(ctx, 422, "通知最大尝试次数需为 1-20 次")
+		}
+		if err := settingRepo.SetValue("notification_retry_max_attempts", strconv.Itoa(attempts)); err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "更新通知最大尝试次数失败", err)
+		}
+	}
+	if backoff := ctx.Request().Input("notificationRetryBackoff"); backoff != "" {
+		seconds, err := strconv.Atoi(backoff)
+		if err != nil || seconds < 10 || seconds > 3600 {
+			return utils.ErrorResponse(ctx, 422, "通知重试间隔需为 10-3600 秒")
+		}
+		if err := settingRepo.SetValue("notification_retry_backoff", strconv.Itoa(seconds)); err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "更新通知重试间隔失败", err)
+		}
+	}
+
 	return utils.SuccessResponse(ctx, "success")
 }
 
@@ -493,6 +591,19 @@ func (r *SettingsController) TestAlertSettings(ctx http.Context) http.Response {
 
 	switch channel {
 	case "email":
+		// 告警设置中不编辑的收件人和连接安全配置使用已保存的值
+		if savedNotification, err := notificationRepo.GetByType("email"); err == nil && savedNotification != nil && savedNotification.ConfigJson != "" {
+			var savedCfg map[string]any
+			if err := json.Unmarshal([]byte(savedNotification.ConfigJson), &savedCfg); err == nil {
+				for _, key := range []string{"cc", "bcc", "username", "auth_method", "skip_verify", "ca_cert"} {
+					if _, ok := configData[key]; !ok && savedCfg[key] != nil {
+						configData[key] = savedCfg[key]
+					}
+				}
+				configBytes, _ = json.Marshal(configData)
+			}
+		}
+
 		var emailCfg notification.EmailConfig
 		if err := json.Unmarshal(configBytes, &emailCfg); err != nil {
 			return utils.ErrorResponseWithError(ctx, 422, "无效的邮件配置", err)
@@ -500,15 +611,7 @@ func (r *SettingsController) TestAlertSettings(ctx http.Context) http.Response {
 
 		// 如果密码为空，尝试使用已保存的密码
 		if emailCfg.Password == "" {
-			savedNotification, err := notificationRepo.GetByType("email")
-			if err == nil && savedNotification != nil && savedNotification.ConfigJson != "" {
-				var savedCfg notification.EmailConfig
-				if err := json.Unmarshal([]byte(savedNotification.ConfigJson), &savedCfg); err == nil {
-					if savedCfg.Password != "" {
-						emailCfg.Password = savedCfg.Password
-					}
-				}
-			}
+			emailCfg.Password = savedNotificationSecret("email", "password")
 		}
 
 		// 发送测试邮件
@@ -525,6 +628,20 @@ func (r *SettingsController) TestAlertSettings(ctx http.Context) http.Response {
 			return utils.ErrorResponseWithError(ctx, 422, "无效的Webhook配置", err)
 		}
 
+		// 如果加签密钥为空，尝试使用已保存的密钥
+		if webhookCfg.Secret == "" {
+			savedNotification, err := notificationRepo.GetByType("webhook")
+			if err == nil && savedNotification != nil && savedNotification.ConfigJson != "" {
+				var savedCfg notification.WebhookConfig
+				if err := json.Unmarshal([]byte(savedNotification.ConfigJson), &savedCfg); err == nil && savedCfg.Secret != "" {
+					webhookCfg.Secret = savedCfg.Secret
+					if dec, err := secret.DecryptStringWithAppKey(savedCfg.Secret); err == nil {
+						webhookCfg.Secret = dec
+					}
+				}
+			}
+		}
+
 		// 发送测试消息
 		content := fmt.Sprintf("CloudSentinel 告警通知测试\n这是一条测试消息，用于验证您的Webhook通知配置是否正确。\n发送时间：%s", time.Now().Format("2006-01-02 15:04:05"))
 
@@ -532,9 +649,251 @@ func (r *SettingsController) TestAlertSettings(ctx http.Context) http.Response {
 			return utils.ErrorResponseWithError(ctx, 500, "发送测试消息失败", err)
 		}
 
+	case "telegram":
+		var telegramCfg notification.TelegramConfig
+		if err := json.Unmarshal(configBytes, &telegramCfg); err != nil {
+			return utils.ErrorResponseWithError(ctx, 422, "无效的Telegram配置", err)
+		}
+
+		// 如果 Bot Token 为空，尝试使用已保存的 Bot Token
+		if telegramCfg.BotToken == "" {
+			savedNotification, err := notificationRepo.GetByType("telegram")
+			if err == nil && savedNotification != nil && savedNotification.ConfigJson != "" {
+				var savedCfg notification.TelegramConfig
+				if err := json.Unmarshal([]byte(savedNotification.ConfigJson), &savedCfg); err == nil && savedCfg.BotToken != "" {
+					telegramCfg.BotToken = savedCfg.BotToken
+					if dec, err := secret.DecryptStringWithAppKey(savedCfg.BotToken); err == nil {
+						telegramCfg.BotToken = dec
+					}
+				}
+			}
+		}
+
+		// 发送测试消息
+		content := fmt.Sprintf("CloudSentinel 告警通知测试\n这是一条测试消息，用于验证您的Telegram通知配置是否正确。\n发送时间：%s", time.Now().Format("2006-01-02 15:04:05"))
+
+		if err := notification.SendTelegram(telegramCfg, content); err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "发送测试消息失败", err)
+		}
+
+	case "ntfy", "gotify", "bark":
+		// 敏感字段为空时使用已保存的值
+		for _, field := range repositories.NotificationSecretFields(channel) {
+			if value, _ := configData[field].(string); value == "" {
+				configData[field] = savedNotificationSecret(channel, field)
+			}
+		}
+		configBytes, _ = json.Marshal(configData)
+
+		// 发送测试消息
+		msg := notification.Message{
+			Title:   "CloudSentinel 告警通知测试",
+			Content: fmt.Sprintf("这是一条测试消息，用于验证您的推送通知配置是否正确。\n发送时间：%s", time.Now().Format("2006-01-02 15:04:05")),
+		}
+		switch channel {
+		case "ntfy":
+			var ntfyCfg notification.NtfyConfig
+			if err := json.Unmarshal(configBytes, &ntfyCfg); err != nil {
+				return utils.ErrorResponseWithError(ctx, 422, "无效的ntfy配置", err)
+			}
+			err = notification.SendNtfy(ntfyCfg, msg)
+		case "gotify":
+			var gotifyCfg notification.GotifyConfig
+			if err := json.Unmarshal(configBytes, &gotifyCfg); err != nil {
+				return utils.ErrorResponseWithError(ctx, 422, "无效的Gotify配置", err)
+			}
+			err = notification.SendGotify(gotifyCfg, msg)
+		case "bark":
+			var barkCfg notification.BarkConfig
+			if err := json.Unmarshal(configBytes, &barkCfg); err != nil {
+				return utils.ErrorResponseWithError(ctx, 422, "无效的Bark配置", err)
+			}
+			err = notification.SendBark(barkCfg, msg)
+		}
+		if err != nil {
+			return utils.ErrorResponseWithError(ctx, 500, "发送测试消息失败", err)
+		}
+
 	default:
 		return utils.ErrorResponse(ctx, 422, "不支持的通知类型")
 	}
 
 	return utils.SuccessResponse(ctx, "测试发送成功")
 }
+
+// savedNotificationSecret 读取该类型第一个通知渠道已保存的敏感字段并解密，不存在时返回空字符串
+func savedNotificationSecret(nType, field string) string {
+	savedNotification, err := repositories.GetAlertNotificationRepository().GetByType(nType)
+	if err != nil || savedNotification == nil || savedNotification.ConfigJson == "" {
+		return ""
+	}
+	var savedCfg map[string]any
+	if err := json.Unmarshal([]byte(savedNotification.ConfigJson), &savedCfg); err != nil {
+		return ""
+	}
+	value, _ := savedCfg[field].(string)
+	if dec, err := secret.DecryptStringWithAppKey(value); err == nil {
+		return dec
+	}
+	return value
+}
+
+// hasExtraNotificationChannel 除告警设置中展示的各类型第一个渠道外，是否还有已启用的通知渠道
+func hasExtraNotificationChannel() bool {
+	notificationRepo := repositories.GetAlertNotificationRepository()
+	channels, err := notificationRepo.GetEnabled()
+	if err != nil {
+		return false
+	}
+	for _, channel := range channels {
+		first, err := notificationRepo.GetByType(channel.NotificationType)
+		if err == nil && first != nil && first.ID != channel.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNotificationChannels 获取所有通知渠道
+func (r *SettingsController) GetNotificationChannels(ctx http.Context) http.Response {
+	channels, err := services.NewAlertService().GetNotificationChannels()
+	if err != nil {
+		return utils.ErrorResponseWithError(ctx, 500, "获取通知渠道失败", err)
+	}
+	return utils.SuccessResponse(ctx, "success", channels)
+}
+
+// CreateNotificationChannel 创建通知渠道
+func (r *SettingsController) CreateNotificationChannel(ctx http.Context) http.Response {
+	var opts services.NotificationChannelOptions
+	if err := ctx.Request().Bind(&opts); err != nil {
+		return utils.ErrorResponseWithError(ctx, 422, "无效的请求数据", err)
+	}
+
+	channel, err := services.NewAlertService().CreateNotificationChannel(opts)
+	if err != nil {
+		return utils.ErrorResponse(ctx, 422, err.Error())
+	}
+	return utils.SuccessResponse(ctx, "success", channel)
+}
+
+// UpdateNotificationChannel 更新通知渠道，敏感字段留空时保留原值
+func (r *SettingsController) UpdateNotificationChannel(ctx http.Context) http.Response {
+	channel, ok := notificationChannelFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, 404, "通知渠道不存在")
+	}
+
+	var opts services.NotificationChannelOptions
+	if err := ctx.Request().Bind(&opts); err != nil {
+		return utils.ErrorResponseWithError(ctx, 422, "无效的请求数据", err)
+	}
+
+	item, err := services.NewAlertService().UpdateNotificationChannel(channel, opts)
+	if err != nil {
+		return utils.ErrorResponse(ctx, 422, err.Error())
+	}
+	return utils.SuccessResponse(ctx, "success", item)
+}
+
+// DeleteNotificationChannel 删除通知渠道及服务器和分组对它的订阅
+func (r *SettingsController) DeleteNotificationChannel(ctx http.Context) http.Response {
+	channel, ok := notificationChannelFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, 404, "通知渠道不存在")
+	}
+
+	if err := repositories.GetAlertNotificationRepository().Delete(channel.ID); err != nil {
+		return utils.ErrorResponseWithError(ctx, 500, "删除通知渠道失败", err)
+	}
+	return utils.SuccessResponse(ctx, "success")
+}
+
+// TestNotificationChannel 使用已保存的配置向通知渠道发送测试消息
+func (r *SettingsController) TestNotificationChannel(ctx http.Context) http.Response {
+	channel, ok := notificationChannelFromRoute(ctx)
+	if !ok {
+		return utils.ErrorResponse(ctx, 404, "通知渠道不存在")
+	}
+
+	if err := services.NewAlertService().TestNotificationChannel(channel); err != nil {
+		return utils.ErrorResponseWithError(ctx, 500, "发送测试消息失败", err)
+	}
+	return utils.SuccessResponse(ctx, "测试发送成功")
+}
+
+// notificationChannelFromRoute 从路由参数中获取通知渠道
+func notificationChannelFromRoute(ctx http.Context) (*models.AlertNotification, bool) {
+	id, err := strconv.ParseUint(ctx.Request().Route("id"), 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	channel, err := repositories.GetAlertNotificationRepository().GetByID(uint(id))
+	if err != nil || channel == nil {
+		return nil, false
+	}
+	return channel, true
+}
+
+// GetNotificationTemplates 获取各通知渠道和事件类型生效的模板及可用的模板变量
+func (r *SettingsController) GetNotificationTemplates(ctx http.Context) http.Response {
+	return utils.SuccessResponse(ctx, "success", map[string]any{
+		"templates": services.NewAlertService().GetNotificationTemplates(),
+		"channels":  notifytemplate.Channels,
+		"events":    notifytemplate.Events,
+		"variables": notifytemplate.Variables,
+	})
+}
+
+// UpdateNotificationTemplate 保存指定渠道和事件类型的自定义模板，模板无法渲染时返回错误
+func (r *SettingsController) UpdateNotificationTemplate(ctx http.Context) http.Response {
+	channel := ctx.Request().Route("channel")
+	event := ctx.Request().Route("event")
+
+	var tmpl notifytemplate.Template
+	if err := ctx.Request().Bind(&tmpl); err != nil {
+		return utils.ErrorResponseWithError(ctx, 422, "无效的请求数据", err)
+	}
+
+	if err := services.NewAlertService().SaveNotificationTemplate(channel, event, tmpl); err != nil {
+		return utils.ErrorResponse(ctx, 422, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "success")
+}
+
+// ResetNotificationTemplate 删除指定渠道和事件类型的自定义模板，恢复默认模板
+func (r *SettingsController) ResetNotificationTemplate(ctx http.Context) http.Response {
+	channel := ctx.Request().Route("channel")
+	event := ctx.Request().Route("event")
+
+	if err := services.NewAlertService().ResetNotificationTemplate(channel, event); err != nil {
+		return utils.ErrorResponse(ctx, 422, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "success")
+}
+
+// PreviewNotificationTemplate 使用示例数据渲染模板，用于保存前检查模板
+func (r *SettingsController) PreviewNotificationTemplate(ctx http.Context) http.Response {
+	type PreviewRequest struct {
+		Channel string `json:"channel" form:"channel"`
+		Event   string `json:"event" form:"event"`
+		notifytemplate.Template
+	}
+
+	var req PreviewRequest
+	if err := ctx.Request().Bind(&req); err != nil {
+		return utils.ErrorResponseWithError(ctx, 422, "无效的请求数据", err)
+	}
+
+	subject, body, err := services.NewAlertService().PreviewNotificationTemplate(req.Channel, req.Event, req.Template)
+	if err != nil {
+		return utils.ErrorResponse(ctx, 422, err.Error())
+	}
+
+	return utils.SuccessResponse(ctx, "success", map[string]any{
+		"subject": subject,
+		"body":    body,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"goravel/app/repositories"
 	"goravel/app/services"
 	"goravel/app/utils"
 
@@ -290,6 +291,21 @@ func (r *UpdateController) CheckAgent(ctx http.Context) http.Response {
 	return r.checkVersion(ctx, agentReleaseUrls, false)
 }
 
+// AgentVersions 获取所有服务器的 Agent 版本报告
+func (r *UpdateController) AgentVersions(ctx http.Context) http.Response {
+	servers, err := repositories.GetServerRepository().GetAll()
+	if err != nil {
+		facades.Log().Errorf("获取服务器列表失败: %v", err)
+		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "获取服务器列表失败", err)
+	}
+
+	latestVersion := services.GetLatestAgentVersion(agentReleaseUrls)
+	minSupportedVersion := services.GetMinSupportedAgentVersion()
+	report := services.BuildAgentVersionReport(servers, latestVersion, minSupportedVersion)
+
+	return utils.SuccessResponse(ctx, "获取成功", report)
+}
+
 // UpdateAgent 更新服务器 Agent
 func (r *UpdateController) UpdateAgent(ctx http.Context) http.Response {
 	serverID := ctx.Request().Route("id")
@@ -308,17 +324,7 @@ func (r *UpdateController) UpdateAgent(ctx http.Context) http.Response {
 	}
 
 	// 发送更新命令
-	wsService := services.GetWebSocketService()
-	message := map[string]interface{}{
-		"type":    "command",
-		"command": "update",
-		"data": map[string]interface{}{
-			"version":      releaseInfo.NormalizedTagName,
-			"version_type": releaseInfo.VersionType,
-		},
-	}
-
-	err = wsService.SendMessage(serverID, message)
+	err = services.SendAgentUpdateCommand(serverID, releaseInfo.NormalizedTagName, releaseInfo.VersionType)
 	if err != nil {
 		facades.Log().Errorf("发送更新命令失败: %v", err)
 		return utils.ErrorResponseWithError(ctx, http.StatusInternalServerError, "发送更新命令失败", err, "SEND_UPDATE_COMMAND_FAILED")
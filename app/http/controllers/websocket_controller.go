@@ -205,6 +205,8 @@ func (c *WebSocketController) handleAgentMessage(msgType string, data map[string
 		return c.agentHandler.HandleProcessInfo(data, conn)
 	case ws.MessageTypeGPUInfo:
 		return c.agentHandler.HandleGPUInfo(data, conn)
+	case ws.MessageTypeSensors:
+		return c.agentHandler.HandleSensors(data, conn)
 	case ws.MessageTypeAgentLog:
 		return c.agentHandler.HandleAgentLogs(data, conn)
 	default:
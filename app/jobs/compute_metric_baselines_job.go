@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"math"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/goravel/framework/facades"
+)
+
+// BaselineLookbackDays 计算基线使用的历史数据天数
+const BaselineLookbackDays = 28
+
+// BaselineMetrics 支持异常检测的指标
+var BaselineMetrics = []string{"cpu", "memory", "disk"}
+
+type ComputeMetricBaselinesJob struct {
+}
+
+// Signature The name and signature of the job.
+func (receiver *ComputeMetricBaselinesJob) Signature() string {
+	return "compute_metric_baselines_job"
+}
+
+// Handle Execute the job.
+func (receiver *ComputeMetricBaselinesJob) Handle(args ...any) error {
+	facades.Log().Info("开始计算服务器指标基线")
+
+	servers, err := repositories.GetServerRepository().GetAll()
+	if err != nil {
+		facades.Log().Errorf("获取服务器列表失败: %v", err)
+		return err
+	}
+
+	metricRepo := repositories.GetServerMetricRepository()
+	baselineRepo := repositories.GetServerMetricBaselineRepository()
+	since := time.Now().AddDate(0, 0, -BaselineLookbackDays)
+
+	for _, server := range servers {
+		stats, err := metricRepo.GetHourlyStats(server.ID, since)
+		if err != nil {
+			facades.Log().Warningf("汇总服务器 %s 的历史指标失败: %v", server.ID, err)
+			continue
+		}
+		baselines := BuildBaselines(server.ID, stats, time.Local)
+		if err := baselineRepo.ReplaceByServerID(server.ID, baselines); err != nil {
+			facades.Log().Warningf("保存服务器 %s 的指标基线失败: %v", server.ID, err)
+		}
+	}
+
+	facades.Log().Info("服务器指标基线计算完成")
+	return nil
+}
+
+// HourOfWeek 返回时间在一周中的小时序号，周日 0 点为 0，周六 23 点为 167
+func HourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// BuildBaselines 将按小时汇总的指标合并为一周中每个小时的均值和标准差
+func BuildBaselines(serverID string, stats []repositories.MetricHourlyStats, loc *time.Location) []*models.ServerMetricBaseline {
+	type accumulator struct {
+		samples int
+		sum, sq float64
+	}
+	acc := make(map[string]*[168]accumulator, len(BaselineMetrics))
+	for _, metric := range BaselineMetrics {
+		acc[metric] = &[168]accumulator{}
+	}
+
+	for _, stat := range stats {
+		hour := HourOfWeek(time.Unix(stat.Hour, 0).In(loc))
+		for metric, values := range map[string][2]float64{
+			"cpu":    {stat.CPUSum, stat.CPUSq},
+			"memory": {stat.MemSum, stat.MemSq},
+			"disk":   {stat.DiskSum, stat.DiskSq},
+		} {
+			bucket := &acc[metric][hour]
+			bucket.samples += stat.Samples
+			bucket.sum += values[0]
+			bucket.sq += values[1]
+		}
+	}
+
+	baselines := make([]*models.ServerMetricBaseline, 0)
+	for _, metric := range BaselineMetrics {
+		for hour, bucket := range acc[metric] {
+			if bucket.samples == 0 {
+				continue
+			}
+			mean := bucket.sum / float64(bucket.samples)
+			variance := math.Max(bucket.sq/float64(bucket.samples)-mean*mean, 0)
+			baselines = append(baselines, &models.ServerMetricBaseline{
+				ServerID:   serverID,
+				Metric:     metric,
+				HourOfWeek: hour,
+				Mean:       mean,
+				Stddev:     math.Sqrt(variance),
+				Samples:    bucket.samples,
+			})
+		}
+	}
+	return baselines
+}
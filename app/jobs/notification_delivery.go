@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/secret"
+
+	"github.com/goravel/framework/facades"
+)
+
+// maxRetryBackoff 重试间隔的上限
+const maxRetryBackoff = 6 * time.Hour
+
+// retryBackoff 第 attempts 次尝试失败后的重试间隔，从 base 开始每次翻倍
+func retryBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// startDelivery 创建或加载投递记录，记录失败时不影响发送
+func (receiver *SendAlertJob) startDelivery() *models.NotificationDelivery {
+	deliveryRepo := repositories.GetNotificationDeliveryRepository()
+	if receiver.DeliveryID > 0 {
+		delivery, err := deliveryRepo.GetByID(receiver.DeliveryID)
+		if err != nil || delivery == nil {
+			facades.Log().Warningf("获取通知投递记录 %d 失败: %v", receiver.DeliveryID, err)
+			return nil
+		}
+		return delivery
+	}
+
+	payload, err := json.Marshal(receiver)
+	if err != nil {
+		facades.Log().Warningf("序列化通知发送任务失败: %v", err)
+		return nil
+	}
+	delivery := &models.NotificationDelivery{
+		ChannelType: receiver.Channel,
+		ChannelName: receiver.Channel,
+		Target:      deliveryTarget(receiver.Channel, receiver.Config),
+		Subject:     receiver.Subject,
+		Payload:     string(payload),
+		Status:      models.DeliveryStatusPending,
+		ResendOf:    receiver.ResendOf,
+	}
+	if receiver.ChannelID > 0 {
+		channelID := receiver.ChannelID
+		delivery.ChannelID = &channelID
+		if channel, err := repositories.GetAlertNotificationRepository().GetByID(channelID); err == nil && channel != nil {
+			delivery.ChannelName = channel.Name
+		}
+	}
+	if err := deliveryRepo.Create(delivery); err != nil {
+		facades.Log().Warningf("创建通知投递记录失败: %v", err)
+		return nil
+	}
+	receiver.DeliveryID = delivery.ID
+	return delivery
+}
+
+// finishDelivery 记录本次尝试的结果，失败时按指数退避安排重试，达到最大尝试次数后不再重试
+func (receiver *SendAlertJob) finishDelivery(delivery *models.NotificationDelivery, sendErr error, latency time.Duration) {
+	if delivery == nil {
+		return
+	}
+	deliveryRepo := repositories.GetNotificationDeliveryRepository()
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LatencyMs = latency.Milliseconds()
+	attempt := &models.NotificationDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Success:    sendErr == nil,
+		LatencyMs:  delivery.LatencyMs,
+	}
+
+	if sendErr == nil {
+		delivery.Status = models.DeliveryStatusSent
+		delivery.LastError = ""
+		delivery.NextRetryAt = nil
+		delivery.DeliveredAt = &now
+	} else {
+		attempt.Error = sendErr.Error()
+		delivery.LastError = sendErr.Error()
+		settingRepo := repositories.GetSystemSettingRepository()
+		maxAttempts := max(settingRepo.GetInt("notification_retry_max_attempts", 5), 1)
+		if receiver.Test || delivery.Attempts >= maxAttempts {
+			delivery.Status = models.DeliveryStatusDead
+			delivery.NextRetryAt = nil
+		} else {
+			base := time.Duration(max(settingRepo.GetInt("notification_retry_backoff", 60), 1)) * time.Second
+			nextRetryAt := now.Add(retryBackoff(base, delivery.Attempts))
+			delivery.Status = models.DeliveryStatusRetrying
+			delivery.NextRetryAt = &nextRetryAt
+		}
+	}
+
+	if err := deliveryRepo.CreateAttempt(attempt); err != nil {
+		facades.Log().Warningf("记录通知发送尝试失败: %v", err)
+	}
+	if err := deliveryRepo.Save(delivery); err != nil {
+		facades.Log().Warningf("更新通知投递记录 %d 失败: %v", delivery.ID, err)
+	}
+}
+
+// NewSendAlertJobFromDelivery 从投递记录恢复发送任务
+func NewSendAlertJobFromDelivery(delivery *models.NotificationDelivery) (*SendAlertJob, error) {
+	var job SendAlertJob
+	if err := json.Unmarshal([]byte(delivery.Payload), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ApplyChannelConfig 使用渠道当前的配置发送，升级步骤覆盖的字段仍使用步骤中的值
+func (receiver *SendAlertJob) ApplyChannelConfig(channel *models.AlertNotification) {
+	receiver.Channel = channel.NotificationType
+	receiver.Config = channel.ConfigJson
+	if len(receiver.Override) == 0 {
+		return
+	}
+
+	config := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(channel.ConfigJson), &config); err != nil {
+		return
+	}
+	for key, value := range receiver.Override {
+		config[key] = value
+	}
+	configJson, _ := json.Marshal(config)
+	receiver.Config = string(configJson)
+}
+
+// deliveryTarget 投递记录中显示的接收方，不包含令牌等敏感信息
+func deliveryTarget(channel, configJSON string) string {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return ""
+	}
+	value := func(key string) string {
+		v, _ := config[key].(string)
+		return strings.TrimSpace(v)
+	}
+	host := func(rawURL, fallback string) string {
+		if dec, err := secret.DecryptStringWithAppKey(rawURL); err == nil {
+			rawURL = dec
+		}
+		if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return fallback
+	}
+
+	switch channel {
+	case "email":
+		recipients := []string{value("to")}
+		if cc := value("cc"); cc != "" {
+			recipients = append(recipients, cc)
+		}
+		return strings.Join(recipients, ", ")
+	case "webhook":
+		return host(value("webhook"), "")
+	case "telegram":
+		return value("chat_id")
+	case "ntfy":
+		return host(value("server_url"), "ntfy.sh") + "/" + value("topic")
+	case "gotify":
+		return host(value("server_url"), "")
+	case "bark":
+		return host(value("server_url"), "api.day.app")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/goravel/framework/facades"
+)
+
+// notificationDeliveryKeepDays 已发送和不再重试的投递记录的保留天数
+const notificationDeliveryKeepDays = 30
+
+// retryBatchSize 每次最多重试的投递记录数
+const retryBatchSize = 50
+
+// RetryNotificationDeliveriesJob 重试到达重试时间的通知投递，并清理过期的投递记录
+type RetryNotificationDeliveriesJob struct{}
+
+// Signature The name and signature of the job.
+func (r *RetryNotificationDeliveriesJob) Signature() string {
+	return "retry_notification_deliveries_job"
+}
+
+// Handle Execute the job.
+func (r *RetryNotificationDeliveriesJob) Handle(args ...any) error {
+	deliveryRepo := repositories.GetNotificationDeliveryRepository()
+	deliveries, err := deliveryRepo.GetDueRetries(time.Now(), retryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// giveUp 不再重试该投递
+	giveUp := func(delivery *models.NotificationDelivery, reason string) {
+		delivery.Status = models.DeliveryStatusDead
+		delivery.NextRetryAt = nil
+		delivery.LastError = reason
+		if err := deliveryRepo.Save(delivery); err != nil {
+			facades.Log().Warningf("更新通知投递记录 %d 失败: %v", delivery.ID, err)
+		}
+	}
+
+	channelRepo := repositories.GetAlertNotificationRepository()
+	for _, delivery := range deliveries {
+		job, err := NewSendAlertJobFromDelivery(delivery)
+		if err != nil {
+			// 任务无法恢复时不再重试
+			giveUp(delivery, "无法恢复发送任务: "+err.Error())
+			continue
+		}
+		// 按渠道当前的配置重试，升级通知保留步骤中的收件人和地址；渠道已删除或停用时不再重试
+		if delivery.ChannelID != nil {
+			channel, err := channelRepo.GetByID(*delivery.ChannelID)
+			if err != nil {
+				facades.Log().Warningf("获取通知渠道 %d 失败: %v", *delivery.ChannelID, err)
+				continue
+			}
+			if channel == nil {
+				giveUp(delivery, "通知渠道已删除")
+				continue
+			}
+			if !channel.Enabled {
+				giveUp(delivery, "通知渠道已停用")
+				continue
+			}
+			job.ApplyChannelConfig(channel)
+		}
+		job.DeliveryID = delivery.ID
+		if err := job.Handle(); err != nil {
+			facades.Log().Warningf("重试通知投递 %d 失败(第%d次): %v", delivery.ID, delivery.Attempts+1, err)
+		}
+	}
+
+	if deleted, err := deliveryRepo.DeleteBefore(time.Now().AddDate(0, 0, -notificationDeliveryKeepDays)); err != nil {
+		facades.Log().Warningf("清理通知投递记录失败: %v", err)
+	} else if deleted > 0 {
+		facades.Log().Infof("已清理 %d 条超过 %d 天的通知投递记录", deleted, notificationDeliveryKeepDays)
+	}
+
+	return nil
+}
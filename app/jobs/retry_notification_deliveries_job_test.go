@@ -0,0 +1,162 @@
+package jobs_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goravel/app/jobs"
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+// newWebhookServer 返回记录请求次数的 Webhook 服务
+func newWebhookServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func webhookConfig(t *testing.T, url string) string {
+	t.Helper()
+	config, err := json.Marshal(map[string]interface{}{"enabled": true, "webhook": url, "platform": "generic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(config)
+}
+
+// createRetryingDelivery 创建一条已到重试时间的投递记录，发送任务中保存的是 config 配置
+func createRetryingDelivery(t *testing.T, channelID uint, config string) *models.NotificationDelivery {
+	t.Helper()
+	return createRetryingJob(t, jobs.SendAlertJob{ChannelID: channelID, Channel: "webhook", Config: config, Subject: "告警", Content: "内容"})
+}
+
+// createRetryingJob 创建一条已到重试时间的投递记录，发送任务为 job
+func createRetryingJob(t *testing.T, job jobs.SendAlertJob) *models.NotificationDelivery {
+	t.Helper()
+	channelID := job.ChannelID
+	payload, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextRetryAt := time.Now().Add(-time.Minute)
+	delivery := &models.NotificationDelivery{
+		ChannelID:   &channelID,
+		ChannelType: "webhook",
+		Payload:     string(payload),
+		Status:      models.DeliveryStatusRetrying,
+		Attempts:    1,
+		NextRetryAt: &nextRetryAt,
+	}
+	if err := repositories.GetNotificationDeliveryRepository().Create(delivery); err != nil {
+		t.Fatalf("创建投递记录失败: %v", err)
+	}
+	return delivery
+}
+
+func TestRetryNotificationDeliveriesUsesCurrentChannel(t *testing.T) {
+	tests.NewDatabase(t)
+	oldServer, oldRequests := newWebhookServer(t)
+	newServer, newRequests := newWebhookServer(t)
+
+	// 投递失败后渠道的 Webhook 地址已修改，重试时使用修改后的地址
+	channel := &models.AlertNotification{Name: "webhook", NotificationType: "webhook", Enabled: true, ConfigJson: webhookConfig(t, newServer.URL)}
+	if err := facades.Orm().Query().Create(channel); err != nil {
+		t.Fatalf("创建通知渠道失败: %v", err)
+	}
+	delivery := createRetryingDelivery(t, channel.ID, webhookConfig(t, oldServer.URL))
+
+	if err := (&jobs.RetryNotificationDeliveriesJob{}).Handle(); err != nil {
+		t.Fatalf("重试失败: %v", err)
+	}
+
+	if oldRequests.Load() != 0 || newRequests.Load() != 1 {
+		t.Errorf("旧地址收到 %d 次请求，新地址收到 %d 次，期望只发送到新地址", oldRequests.Load(), newRequests.Load())
+	}
+	got, err := repositories.GetNotificationDeliveryRepository().GetByID(delivery.ID)
+	if err != nil || got == nil {
+		t.Fatalf("获取投递记录失败: %v", err)
+	}
+	if got.Status != models.DeliveryStatusSent || got.Attempts != 2 {
+		t.Errorf("投递记录为 %s(第%d次)，期望第 2 次发送成功", got.Status, got.Attempts)
+	}
+}
+
+func TestRetryNotificationDeliveriesKeepsEscalationOverride(t *testing.T) {
+	tests.NewDatabase(t)
+	channelServer, channelRequests := newWebhookServer(t)
+	stepServer, stepRequests := newWebhookServer(t)
+
+	// 升级步骤将通知发送到值班地址，重试时仍发送到该地址，渠道的其余配置使用当前配置
+	channel := &models.AlertNotification{Name: "webhook", NotificationType: "webhook", Enabled: true, ConfigJson: webhookConfig(t, channelServer.URL)}
+	if err := facades.Orm().Query().Create(channel); err != nil {
+		t.Fatalf("创建通知渠道失败: %v", err)
+	}
+	override := map[string]string{"webhook": stepServer.URL}
+	createRetryingJob(t, jobs.SendAlertJob{
+		ChannelID: channel.ID,
+		Channel:   "webhook",
+		Config:    webhookConfig(t, stepServer.URL),
+		Subject:   "告警",
+		Content:   "内容",
+		Override:  override,
+	})
+
+	if err := (&jobs.RetryNotificationDeliveriesJob{}).Handle(); err != nil {
+		t.Fatalf("重试失败: %v", err)
+	}
+
+	if channelRequests.Load() != 0 || stepRequests.Load() != 1 {
+		t.Errorf("渠道地址收到 %d 次请求，升级步骤地址收到 %d 次，期望只发送到升级步骤地址", channelRequests.Load(), stepRequests.Load())
+	}
+}
+
+func TestRetryNotificationDeliveriesChannelGone(t *testing.T) {
+	tests.NewDatabase(t)
+	server, requests := newWebhookServer(t)
+
+	disabled := &models.AlertNotification{Name: "webhook", NotificationType: "webhook", Enabled: false, ConfigJson: webhookConfig(t, server.URL)}
+	if err := facades.Orm().Query().Create(disabled); err != nil {
+		t.Fatalf("创建通知渠道失败: %v", err)
+	}
+	cases := []struct {
+		name      string
+		channelID uint
+		wantError string
+	}{
+		{"渠道已删除", disabled.ID + 1, "通知渠道已删除"},
+		{"渠道已停用", disabled.ID, "通知渠道已停用"},
+	}
+	deliveries := make([]*models.NotificationDelivery, len(cases))
+	for i, c := range cases {
+		deliveries[i] = createRetryingDelivery(t, c.channelID, webhookConfig(t, server.URL))
+	}
+
+	if err := (&jobs.RetryNotificationDeliveriesJob{}).Handle(); err != nil {
+		t.Fatalf("重试失败: %v", err)
+	}
+
+	if requests.Load() != 0 {
+		t.Errorf("发送了 %d 次请求，渠道已删除或停用时不应发送", requests.Load())
+	}
+	for i, c := range cases {
+		got, err := repositories.GetNotificationDeliveryRepository().GetByID(deliveries[i].ID)
+		if err != nil || got == nil {
+			t.Fatalf("获取投递记录失败: %v", err)
+		}
+		if got.Status != models.DeliveryStatusDead || got.NextRetryAt != nil || got.LastError != c.wantError {
+			t.Errorf("%s: 投递记录为 %s: %q，期望不再重试", c.name, got.Status, got.LastError)
+		}
+	}
+}
@@ -3,17 +3,31 @@ package jobs
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
 	"goravel/app/utils/notification"
+	"goravel/app/utils/notifytemplate"
 	"goravel/app/utils/secret"
 
 	"github.com/goravel/framework/facades"
 )
 
 type SendAlertJob struct {
-	Channel string
-	Config  string
-	Subject string
-	Content string
+	ChannelID uint // 通知渠道ID，用于记录投递
+	Channel   string
+	Config    string
+	Subject   string
+	Content   string               // 纯文本正文
+	HTML      string               // 邮件的 HTML 正文，为空时只发送纯文本
+	Color     string               // 状态颜色，Slack、Discord 等支持颜色的平台使用
+	Link      string               // 服务器详情页链接，为空时不显示
+	Event     string               // 事件类型，通用 Webhook 请求体模板使用
+	Data      *notifytemplate.Data // 事件数据，通用 Webhook 请求体模板使用，汇总通知为空
+	Override  map[string]string    // 升级步骤覆盖的渠道配置（收件人、Webhook 地址和平台），重试和重发时保留
+
+	DeliveryID uint  `json:"-"` // 投递记录ID，重试时指定，为 0 时创建新的投递记录
+	ResendOf   *uint `json:"-"` // 手动重发时原投递记录的ID
+	Test       bool  `json:"-"` // 测试消息，发送失败时不重试
 }
 
 // Signature The name and signature of the job.
@@ -25,6 +39,15 @@ func (receiver *SendAlertJob) Signature() string {
 func (receiver *SendAlertJob) Handle(args ...any) error {
 	facades.Log().Infof("Processing SendAlertJob: %s", receiver.Channel)
 
+	delivery := receiver.startDelivery()
+	start := time.Now()
+	err := receiver.send()
+	receiver.finishDelivery(delivery, err, time.Since(start))
+	return err
+}
+
+// send 按渠道类型解密配置并发送
+func (receiver *SendAlertJob) send() error {
 	switch receiver.Channel {
 	case "email":
 		var config notification.EmailConfig
@@ -37,7 +60,11 @@ func (receiver *SendAlertJob) Handle(args ...any) error {
 				config.Password = dec
 			}
 		}
-		return notification.SendEmail(config, receiver.Subject, receiver.Content)
+		return notification.SendEmailMessage(config, notification.EmailMessage{
+			Subject: receiver.Subject,
+			Text:    receiver.Content,
+			HTML:    receiver.HTML,
+		})
 	case "webhook":
 		var config notification.WebhookConfig
 		if err := json.Unmarshal([]byte(receiver.Config), &config); err != nil {
@@ -49,8 +76,78 @@ func (receiver *SendAlertJob) Handle(args ...any) error {
 				config.Webhook = dec
 			}
 		}
-		return notification.SendWebhook(config, receiver.Content)
+		if config.Secret != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.Secret); err == nil {
+				config.Secret = dec
+			}
+		}
+		return notification.SendWebhookMessage(config, receiver.message())
+	case "telegram":
+		var config notification.TelegramConfig
+		if err := json.Unmarshal([]byte(receiver.Config), &config); err != nil {
+			return err
+		}
+		// 解密敏感字段
+		if config.BotToken != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.BotToken); err == nil {
+				config.BotToken = dec
+			}
+		}
+		return notification.SendTelegram(config, receiver.Content)
+	case "ntfy":
+		var config notification.NtfyConfig
+		if err := json.Unmarshal([]byte(receiver.Config), &config); err != nil {
+			return err
+		}
+		// 解密敏感字段
+		if config.Token != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.Token); err == nil {
+				config.Token = dec
+			}
+		}
+		if config.Password != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.Password); err == nil {
+				config.Password = dec
+			}
+		}
+		return notification.SendNtfy(config, receiver.message())
+	case "gotify":
+		var config notification.GotifyConfig
+		if err := json.Unmarshal([]byte(receiver.Config), &config); err != nil {
+			return err
+		}
+		// 解密敏感字段
+		if config.AppToken != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.AppToken); err == nil {
+				config.AppToken = dec
+			}
+		}
+		return notification.SendGotify(config, receiver.message())
+	case "bark":
+		var config notification.BarkConfig
+		if err := json.Unmarshal([]byte(receiver.Config), &config); err != nil {
+			return err
+		}
+		// 解密敏感字段
+		if config.DeviceKey != "" {
+			if dec, err := secret.DecryptStringWithAppKey(config.DeviceKey); err == nil {
+				config.DeviceKey = dec
+			}
+		}
+		return notification.SendBark(config, receiver.message())
 	default:
 		return fmt.Errorf("unknown channel: %s", receiver.Channel)
 	}
 }
+
+// message 转换为 Webhook 和推送渠道使用的消息
+func (receiver *SendAlertJob) message() notification.Message {
+	return notification.Message{
+		Title:   receiver.Subject,
+		Content: receiver.Content,
+		Color:   receiver.Color,
+		Link:    receiver.Link,
+		Event:   receiver.Event,
+		Data:    receiver.Data,
+	}
+}
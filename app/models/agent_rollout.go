@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// AgentRollout Agent 分批发布计划模型
+type AgentRollout struct {
+	ID             uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name           string     `gorm:"column:name;size:255" json:"name"`
+	TargetVersion  string     `gorm:"column:target_version;size:50" json:"target_version"`
+	VersionType    string     `gorm:"column:version_type;size:20" json:"version_type"`
+	GroupID        *uint      `gorm:"column:group_id" json:"group_id"`
+	CanaryCount    int        `gorm:"column:canary_count;default:1" json:"canary_count"`
+	BatchSize      int        `gorm:"column:batch_size;default:5" json:"batch_size"`
+	MaxFailures    int        `gorm:"column:max_failures;default:0" json:"max_failures"`
+	BatchTimeout   int        `gorm:"column:batch_timeout;default:300" json:"batch_timeout"` // 秒
+	Status         string     `gorm:"column:status;size:20;default:pending" json:"status"`   // pending, running, completed, halted, cancelled
+	CurrentBatch   int        `gorm:"column:current_batch;default:0" json:"current_batch"`
+	TotalBatches   int        `gorm:"column:total_batches;default:0" json:"total_batches"`
+	TotalServers   int        `gorm:"column:total_servers;default:0" json:"total_servers"`
+	SucceededCount int        `gorm:"column:succeeded_count;default:0" json:"succeeded_count"`
+	FailedCount    int        `gorm:"column:failed_count;default:0" json:"failed_count"`
+	Error          string     `gorm:"column:error;type:text" json:"error"`
+	StartedAt      *time.Time `gorm:"column:started_at" json:"started_at"`
+	FinishedAt     *time.Time `gorm:"column:finished_at" json:"finished_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (r *AgentRollout) TableName() string {
+	return "agent_rollouts"
+}
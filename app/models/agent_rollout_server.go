@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// AgentRolloutServer 发布计划中单台服务器的更新记录
+type AgentRolloutServer struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RolloutID   uint       `gorm:"column:rollout_id;not null;index" json:"rollout_id"`
+	ServerID    string     `gorm:"column:server_id;not null;size:255" json:"server_id"`
+	BatchIndex  int        `gorm:"column:batch_index;default:0" json:"batch_index"`     // 0 为金丝雀批次
+	Status      string     `gorm:"column:status;size:20;default:pending" json:"status"` // pending, updating, succeeded, failed, skipped
+	FromVersion string     `gorm:"column:from_version;size:50" json:"from_version"`
+	Error       string     `gorm:"column:error;type:text" json:"error"`
+	StartedAt   *time.Time `gorm:"column:started_at" json:"started_at"`
+	FinishedAt  *time.Time `gorm:"column:finished_at" json:"finished_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (r *AgentRolloutServer) TableName() string {
+	return "agent_rollout_servers"
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// 告警实例状态
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// Alert 告警实例模型（一次告警从触发到恢复的完整记录）
+type Alert struct {
+	ID                 string     `gorm:"column:id;primaryKey" json:"id"`
+	ServerID           string     `gorm:"column:server_id;index" json:"server_id"`
+	RuleID             *uint      `gorm:"column:rule_id" json:"rule_id"`
+	RuleKey            string     `gorm:"column:rule_key" json:"rule_key"`                 // cpu, gpu_util:0, expression:xxx, server_offline
+	Type               string     `gorm:"column:type" json:"type"`                         // threshold, expression, anomaly, server_offline
+	Severity           string     `gorm:"column:severity;default:warning" json:"severity"` // warning, critical
+	Status             string     `gorm:"column:status;default:firing" json:"status"`      // firing, resolved
+	Title              string     `gorm:"column:title" json:"title"`
+	Message            string     `gorm:"column:message;type:text" json:"message"`
+	MetricValue        *float64   `gorm:"column:metric_value" json:"metric_value"`
+	Threshold          *float64   `gorm:"column:threshold" json:"threshold"`
+	Labels             string     `gorm:"column:labels;type:text" json:"labels"` // JSON 格式
+	IsRead             bool       `gorm:"column:is_read;default:false" json:"is_read"`
+	AcknowledgedAt     *time.Time `gorm:"column:acknowledged_at" json:"acknowledged_at"`
+	AcknowledgedBy     string     `gorm:"column:acknowledged_by" json:"acknowledged_by"`
+	ResolvedAt         *time.Time `gorm:"column:resolved_at" json:"resolved_at"`
+	ResolvedBy         string     `gorm:"column:resolved_by" json:"resolved_by"` // auto 为自动恢复
+	EscalationPolicyID *uint      `gorm:"column:escalation_policy_id" json:"escalation_policy_id"`
+	EscalationStep     int        `gorm:"column:escalation_step;default:0" json:"escalation_step"` // 已执行的升级步骤数
+	Timestamp          time.Time  `gorm:"column:timestamp;index" json:"timestamp"`                 // 告警开始时间
+	CreatedAt          time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (a *Alert) TableName() string {
+	return "alerts"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// AlertEscalationPolicy 告警升级策略，告警未确认时按步骤逐级通知
+type AlertEscalationPolicy struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"column:name;not null" json:"name"`
+	Description string    `gorm:"column:description;type:text" json:"description"`
+	Steps       string    `gorm:"column:steps;type:text" json:"steps"` // JSON 格式的升级步骤
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (p *AlertEscalationPolicy) TableName() string {
+	return "alert_escalation_policies"
+}
@@ -6,11 +6,13 @@ import (
 	"github.com/goravel/framework/database/orm"
 )
 
-// AlertNotification 告警通知配置模型
+// AlertNotification 告警通知渠道模型，同一类型可配置多个命名的渠道
 type AlertNotification struct {
 	ID               uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name             string    `gorm:"column:name;size:100" json:"name"`
 	NotificationType string    `gorm:"column:notification_type;not null;size:20" json:"notification_type"`
 	Enabled          bool      `gorm:"column:enabled;default:0" json:"enabled"`
+	IsDefault        bool      `gorm:"column:is_default" json:"is_default"` // 未订阅的服务器是否默认接收该渠道的通知
 	ConfigJson       string    `gorm:"column:config_json;type:text;not null" json:"config_json"`
 	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt        time.Time `gorm:"column:updated_at" json:"updated_at"`
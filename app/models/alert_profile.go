@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// AlertProfile 告警配置模板，保存一整套告警规则，可分配给多台服务器或分组共用
+type AlertProfile struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"column:name;not null" json:"name"`
+	Description string    `gorm:"column:description;type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (p *AlertProfile) TableName() string {
+	return "alert_profiles"
+}
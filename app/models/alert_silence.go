@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// AlertSilence 告警静默（维护窗口），生效期间告警照常记录但不发送通知
+type AlertSilence struct {
+	ID        uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name      string     `gorm:"column:name;not null" json:"name"`
+	Comment   string     `gorm:"column:comment;type:text" json:"comment"`
+	ServerID  *string    `gorm:"column:server_id;index" json:"server_id"` // NULL 表示不限服务器
+	GroupID   *uint      `gorm:"column:group_id;index" json:"group_id"`   // NULL 表示不限分组
+	RuleType  string     `gorm:"column:rule_type" json:"rule_type"`       // 空表示不限规则类型
+	Labels    string     `gorm:"column:labels;type:text" json:"labels"`   // JSON 格式，需全部匹配
+	StartsAt  *time.Time `gorm:"column:starts_at" json:"starts_at"`
+	EndsAt    *time.Time `gorm:"column:ends_at" json:"ends_at"`
+	Cron      string     `gorm:"column:cron" json:"cron"`                   // 周期维护窗口的开始时间
+	Duration  int        `gorm:"column:duration;default:0" json:"duration"` // 周期维护窗口时长(分钟)
+	CreatedBy string     `gorm:"column:created_by" json:"created_by"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (s *AlertSilence) TableName() string {
+	return "alert_silences"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// 通知投递状态
+const (
+	DeliveryStatusPending  = "pending"  // 发送中
+	DeliveryStatusSent     = "sent"     // 已发送
+	DeliveryStatusRetrying = "retrying" // 发送失败，等待重试
+	DeliveryStatusDead     = "dead"     // 达到最大尝试次数仍失败，不再重试
+)
+
+// NotificationDelivery 一条通知向一个通知渠道的投递记录
+type NotificationDelivery struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ChannelID   *uint      `gorm:"column:channel_id;index" json:"channel_id"`
+	ChannelType string     `gorm:"column:channel_type" json:"channel_type"`
+	ChannelName string     `gorm:"column:channel_name" json:"channel_name"`
+	Target      string     `gorm:"column:target" json:"target"` // 接收方，如收件人、Webhook 域名、Chat ID
+	Subject     string     `gorm:"column:subject" json:"subject"`
+	Payload     string     `gorm:"column:payload;type:text" json:"-"` // 发送任务的 JSON，用于重试和重发
+	Status      string     `gorm:"column:status;default:pending" json:"status"`
+	Attempts    int        `gorm:"column:attempts" json:"attempts"`
+	LastError   string     `gorm:"column:last_error;type:text" json:"last_error"`
+	LatencyMs   int64      `gorm:"column:latency_ms" json:"latency_ms"` // 最近一次尝试的耗时(毫秒)
+	NextRetryAt *time.Time `gorm:"column:next_retry_at" json:"next_retry_at"`
+	DeliveredAt *time.Time `gorm:"column:delivered_at" json:"delivered_at"`
+	ResendOf    *uint      `gorm:"column:resend_of" json:"resend_of"` // 手动重发时原投递记录的ID
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at" json:"updated_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (d *NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// NotificationDeliveryAttempt 通知投递的一次发送尝试
+type NotificationDeliveryAttempt struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	DeliveryID uint      `gorm:"column:delivery_id;index" json:"delivery_id"`
+	Attempt    int       `gorm:"column:attempt" json:"attempt"`
+	Success    bool      `gorm:"column:success" json:"success"`
+	Error      string    `gorm:"column:error;type:text" json:"error"`
+	LatencyMs  int64     `gorm:"column:latency_ms" json:"latency_ms"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (a *NotificationDeliveryAttempt) TableName() string {
+	return "notification_delivery_attempts"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// NotificationSubscription 服务器或分组对通知渠道的订阅，Enabled 为 false 时表示退订
+type NotificationSubscription struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ChannelID uint      `gorm:"column:channel_id;not null;index" json:"channel_id"`
+	ServerID  *string   `gorm:"column:server_id;size:36;index" json:"server_id"`
+	GroupID   *uint     `gorm:"column:group_id;index" json:"group_id"`
+	Enabled   bool      `gorm:"column:enabled" json:"enabled"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (n *NotificationSubscription) TableName() string {
+	return "notification_subscriptions"
+}
@@ -40,6 +40,10 @@ type Server struct {
 	AgentSystemInterval    int    `gorm:"column:agent_system_interval;default:30" json:"agent_system_interval"`
 	AgentHeartbeatInterval int    `gorm:"column:agent_heartbeat_interval;default:20" json:"agent_heartbeat_interval"`
 	AgentLogPath           string `gorm:"column:agent_log_path;size:255;default:logs" json:"agent_log_path"`
+	// 告警规则模式：override 服务器规则优先，inherit 仅继承分组和全局规则
+	AlertRulesMode string `gorm:"column:alert_rules_mode;size:20;default:override" json:"alert_rules_mode"`
+	// 服务器使用的告警配置模板，优先于分组规则和分组模板
+	AlertProfileID *uint `gorm:"column:alert_profile_id" json:"alert_profile_id"`
 	// 监控配置
 	MonitoredServices []string       `gorm:"column:monitored_services;serializer:json" json:"monitored_services"`
 	ServiceStatus     map[string]any `gorm:"column:service_status;serializer:json" json:"service_status"`
@@ -6,11 +6,19 @@ import (
 	"github.com/goravel/framework/database/orm"
 )
 
+// 服务器告警规则模式
+const (
+	AlertRulesModeOverride = "override" // 按服务器、服务器模板、分组、分组模板、全局的顺序取第一个配置的规则
+	AlertRulesModeInherit  = "inherit"  // 忽略服务器规则，仅继承模板、分组和全局规则
+)
+
 // ServerAlertRule 服务器告警规则模型
 type ServerAlertRule struct {
 	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	ServerID  *string   `gorm:"column:server_id;index;size:36" json:"server_id"` // NULL 表示全局规则
-	RuleType  string    `gorm:"column:rule_type;not null;size:50;index" json:"rule_type"` // cpu, memory, disk, bandwidth, traffic, expiration
+	ServerID  *string   `gorm:"column:server_id;index;size:36" json:"server_id"` // NULL 表示分组规则或全局规则
+	GroupID   *uint     `gorm:"column:group_id;index" json:"group_id"`            // 分组规则所属分组，与 server_id 均为 NULL 表示全局规则
+	ProfileID *uint     `gorm:"column:profile_id;index" json:"profile_id"`        // 模板规则所属告警配置模板
+	RuleType  string    `gorm:"column:rule_type;not null;size:50;index" json:"rule_type"` // cpu, memory, disk, bandwidth, traffic, expiration, service
 	Config    string    `gorm:"column:config;type:text;not null" json:"config"`             // JSON 格式配置
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
@@ -23,4 +31,18 @@ func (s *ServerAlertRule) TableName() string {
 	return "server_alert_rules"
 }
 
+// Source 规则来源：server、group、profile、global
+func (s *ServerAlertRule) Source() string {
+	if s.ServerID != nil {
+		return "server"
+	}
+	if s.GroupID != nil {
+		return "group"
+	}
+	if s.ProfileID != nil {
+		return "profile"
+	}
+	return "global"
+}
+
 
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// ServerGPUMetric 服务器GPU性能指标模型（每块GPU一条记录）
+type ServerGPUMetric struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ServerID    string    `gorm:"column:server_id;not null;size:255;index" json:"server_id"`
+	GPUIndex    int       `gorm:"column:gpu_index;default:0" json:"gpu_index"`
+	Name        string    `gorm:"column:name;size:255" json:"name"`
+	Utilization float64   `gorm:"column:utilization;type:decimal(5,2);default:0" json:"utilization"`
+	MemoryUsed  float64   `gorm:"column:memory_used;type:decimal(12,2);default:0" json:"memory_used"`
+	MemoryTotal float64   `gorm:"column:memory_total;type:decimal(12,2);default:0" json:"memory_total"`
+	Temperature float64   `gorm:"column:temperature;type:decimal(5,2);default:0" json:"temperature"`
+	PowerDraw   float64   `gorm:"column:power_draw;type:decimal(8,2);default:0" json:"power_draw"`
+	Timestamp   time.Time `gorm:"column:timestamp;index" json:"timestamp"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (s *ServerGPUMetric) TableName() string {
+	return "server_gpu_metrics"
+}
+
+// MemoryUsagePercent 显存使用率
+func (s *ServerGPUMetric) MemoryUsagePercent() float64 {
+	if s.MemoryTotal <= 0 {
+		return 0
+	}
+	return s.MemoryUsed / s.MemoryTotal * 100
+}
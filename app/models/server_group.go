@@ -8,12 +8,14 @@ import (
 
 // ServerGroup 服务器分组模型
 type ServerGroup struct {
-	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	Name        string    `gorm:"column:name;not null;size:100" json:"name"`
-	Description string    `gorm:"column:description;type:text" json:"description"`
-	Color       string    `gorm:"column:color;size:20" json:"color"`
-	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+	ID                 uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name               string    `gorm:"column:name;not null;size:100" json:"name"`
+	Description        string    `gorm:"column:description;type:text" json:"description"`
+	Color              string    `gorm:"column:color;size:20" json:"color"`
+	EscalationPolicyID *uint     `gorm:"column:escalation_policy_id" json:"escalation_policy_id"` // 分组默认的告警升级策略
+	AlertProfileID     *uint     `gorm:"column:alert_profile_id" json:"alert_profile_id"`         // 分组使用的告警配置模板
+	CreatedAt          time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"column:updated_at" json:"updated_at"`
 
 	orm.Model
 }
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/goravel/framework/database/orm"
+)
+
+// ServerMetricBaseline 服务器指标在一周中每个小时的历史基线，用于异常检测
+type ServerMetricBaseline struct {
+	ID         uint    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ServerID   string  `gorm:"column:server_id;not null;size:255" json:"server_id"`
+	Metric     string  `gorm:"column:metric;not null" json:"metric"`
+	HourOfWeek int     `gorm:"column:hour_of_week;not null" json:"hour_of_week"` // 周日 0 点为 0，周六 23 点为 167
+	Mean       float64 `gorm:"column:mean;type:decimal(10,4);default:0" json:"mean"`
+	Stddev     float64 `gorm:"column:stddev;type:decimal(10,4);default:0" json:"stddev"`
+	Samples    int     `gorm:"column:samples;default:0" json:"samples"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (s *ServerMetricBaseline) TableName() string {
+	return "server_metric_baselines"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// ServerSensorReading 服务器硬件传感器读数模型（每个传感器一条记录）
+type ServerSensorReading struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ServerID    string    `gorm:"column:server_id;not null;size:255;index" json:"server_id"`
+	SensorKey   string    `gorm:"column:sensor_key;size:255" json:"sensor_key"`
+	SensorType  string    `gorm:"column:sensor_type;size:50" json:"sensor_type"` // cpu_package, cpu_core, nvme, chassis
+	Label       string    `gorm:"column:label;size:255" json:"label"`
+	Temperature float64   `gorm:"column:temperature;type:decimal(5,2);default:0" json:"temperature"`
+	Timestamp   time.Time `gorm:"column:timestamp;index" json:"timestamp"`
+
+	orm.Model
+}
+
+// TableName 指定表名
+func (s *ServerSensorReading) TableName() string {
+	return "server_sensor_readings"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// ServiceMonitorAlert 服务监控告警事件模型（记录被监控服务的停止/恢复）
+type ServiceMonitorAlert struct {
+	ID           string    `gorm:"column:id;primaryKey" json:"id"`
+	RuleID       *uint     `gorm:"column:rule_id" json:"rule_id"`
+	ServerID     string    `gorm:"column:server_id;index" json:"server_id"`
+	ServiceName  string    `gorm:"column:service_name" json:"service_name"`
+	Type         string    `gorm:"column:type" json:"type"` // down, up
+	Title        string    `gorm:"column:title" json:"title"`
+	Message      string    `gorm:"column:message;type:text" json:"message"`
+	ResponseTime *int      `gorm:"column:response_time" json:"response_time"`
+	Duration     int64     `gorm:"column:duration;default:0" json:"duration"` // 恢复事件对应的故障持续时间(秒)
+	IsRead       bool      `gorm:"column:is_read;default:false" json:"is_read"`
+	Timestamp    time.Time `gorm:"column:timestamp;index" json:"timestamp"`
+}
+
+// TableName 指定表名
+func (s *ServiceMonitorAlert) TableName() string {
+	return "service_monitor_alerts"
+}
@@ -0,0 +1,19 @@
+package providers
+
+import (
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/foundation"
+)
+
+type AgentRolloutServiceProvider struct {
+}
+
+func (receiver *AgentRolloutServiceProvider) Register(app foundation.Application) {
+
+}
+
+func (receiver *AgentRolloutServiceProvider) Boot(app foundation.Application) {
+	// 服务重启后无法继续等待 Agent 重连，将执行中的发布计划标记为暂停
+	go services.GetAgentRolloutService().HaltInterrupted()
+}
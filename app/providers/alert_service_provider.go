@@ -0,0 +1,22 @@
+package providers
+
+import (
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/foundation"
+)
+
+type AlertServiceProvider struct {
+}
+
+func (receiver *AlertServiceProvider) Register(app foundation.Application) {
+
+}
+
+func (receiver *AlertServiceProvider) Boot(app foundation.Application) {
+	// 告警状态保存在内存缓存中，启动时从告警记录恢复
+	go services.NewAlertService().RestoreAlertStates()
+
+	// 启动告警升级服务
+	go services.NewAlertEscalationService().Start()
+}
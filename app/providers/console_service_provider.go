@@ -12,10 +12,13 @@ type ConsoleServiceProvider struct {
 
 func (receiver *ConsoleServiceProvider) Register(app foundation.Application) {
 	kernel := console.Kernel{}
-	// facades.Schedule().Register(kernel.Schedule())
 	facades.Artisan().Register(kernel.Commands())
 }
 
 func (receiver *ConsoleServiceProvider) Boot(app foundation.Application) {
-
+	// 调度任务依赖日志、队列等服务，在启动阶段注册
+	// 注意：此前调度任务从未注册，注册后 console.Kernel 中的所有任务都会运行，
+	// 包括原本不生效的每日 01:00 服务器到期检查（check_server_expiration）
+	kernel := console.Kernel{}
+	facades.Schedule().Register(kernel.Schedule())
 }
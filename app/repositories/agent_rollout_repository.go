@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// AgentRolloutRepository Agent 发布计划仓库
+type AgentRolloutRepository struct{}
+
+// NewAgentRolloutRepository 创建 Agent 发布计划仓库实例
+func NewAgentRolloutRepository() *AgentRolloutRepository {
+	return &AgentRolloutRepository{}
+}
+
+// GetAll 获取所有发布计划
+func (r *AgentRolloutRepository) GetAll() ([]*models.AgentRollout, error) {
+	var rollouts []*models.AgentRollout
+	err := facades.Orm().Query().OrderBy("id", "desc").Get(&rollouts)
+	if err != nil {
+		return nil, err
+	}
+	return rollouts, nil
+}
+
+// GetByID 根据ID获取发布计划，不存在时返回 nil
+func (r *AgentRolloutRepository) GetByID(id uint) (*models.AgentRollout, error) {
+	var rollout models.AgentRollout
+	err := facades.Orm().Query().Where("id = ?", id).First(&rollout)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.ID == 0 {
+		return nil, nil
+	}
+	return &rollout, nil
+}
+
+// GetByStatus 获取指定状态的发布计划
+func (r *AgentRolloutRepository) GetByStatus(status string) ([]*models.AgentRollout, error) {
+	var rollouts []*models.AgentRollout
+	err := facades.Orm().Query().Where("status = ?", status).Get(&rollouts)
+	if err != nil {
+		return nil, err
+	}
+	return rollouts, nil
+}
+
+// Create 创建发布计划及其服务器记录
+func (r *AgentRolloutRepository) Create(rollout *models.AgentRollout, servers []*models.AgentRolloutServer) error {
+	if err := facades.Orm().Query().Create(rollout); err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+
+	for _, server := range servers {
+		server.RolloutID = rollout.ID
+	}
+	if err := facades.Orm().Query().Create(&servers); err != nil {
+		// 服务器记录写入失败时删除发布计划，避免留下空计划
+		_, _ = facades.Orm().Query().Where("id = ?", rollout.ID).Delete(&models.AgentRollout{})
+		return err
+	}
+	return nil
+}
+
+// Update 更新发布计划
+func (r *AgentRolloutRepository) Update(rollout *models.AgentRollout) error {
+	return facades.Orm().Query().Save(rollout)
+}
+
+// GetServers 获取发布计划下的服务器记录
+func (r *AgentRolloutRepository) GetServers(rolloutID uint) ([]*models.AgentRolloutServer, error) {
+	var servers []*models.AgentRolloutServer
+	err := facades.Orm().Query().
+		Where("rollout_id = ?", rolloutID).
+		OrderBy("batch_index", "asc").
+		OrderBy("id", "asc").
+		Get(&servers)
+	if err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// UpdateServer 更新发布计划中的服务器记录
+func (r *AgentRolloutRepository) UpdateServer(server *models.AgentRolloutServer) error {
+	return facades.Orm().Query().Save(server)
+}
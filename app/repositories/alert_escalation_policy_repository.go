@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// AlertEscalationPolicyRepository 告警升级策略
+type AlertEscalationPolicyRepository struct{}
+
+// NewAlertEscalationPolicyRepository 创建告警升级策略实例
+func NewAlertEscalationPolicyRepository() *AlertEscalationPolicyRepository {
+	return &AlertEscalationPolicyRepository{}
+}
+
+// GetAll 获取所有升级策略
+func (r *AlertEscalationPolicyRepository) GetAll() ([]*models.AlertEscalationPolicy, error) {
+	var policies []*models.AlertEscalationPolicy
+	err := facades.Orm().Query().OrderBy("id", "asc").Get(&policies)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetByID 根据ID获取升级策略，不存在时返回 nil
+func (r *AlertEscalationPolicyRepository) GetByID(id uint) (*models.AlertEscalationPolicy, error) {
+	var policy models.AlertEscalationPolicy
+	if err := facades.Orm().Query().Where("id", id).First(&policy); err != nil {
+		return nil, err
+	}
+	if policy.ID == 0 {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+// Create 创建升级策略
+func (r *AlertEscalationPolicyRepository) Create(policy *models.AlertEscalationPolicy) error {
+	return facades.Orm().Query().Create(policy)
+}
+
+// Update 更新升级策略
+func (r *AlertEscalationPolicyRepository) Update(policy *models.AlertEscalationPolicy) error {
+	return facades.Orm().Query().Save(policy)
+}
+
+// Delete 删除升级策略，并解除服务器分组对该策略的引用
+func (r *AlertEscalationPolicyRepository) Delete(id uint) error {
+	if _, err := facades.Orm().Query().Model(&models.ServerGroup{}).
+		Where("escalation_policy_id", id).
+		Update("escalation_policy_id", nil); err != nil {
+		return err
+	}
+	_, err := facades.Orm().Query().Model(&models.AlertEscalationPolicy{}).Where("id", id).Delete()
+	return err
+}
@@ -7,9 +7,38 @@ import (
 	"goravel/app/models"
 	"goravel/app/utils/secret"
 
+	"github.com/goravel/framework/contracts/database/orm"
 	"github.com/goravel/framework/facades"
 )
 
+// notificationSecretFields 各类型通知渠道中需要加密保存的配置项
+var notificationSecretFields = map[string][]string{
+	"email":    {"password"},
+	"webhook":  {"webhook", "secret"},
+	"telegram": {"bot_token"},
+	"ntfy":     {"token", "password"},
+	"gotify":   {"app_token"},
+	"bark":     {"device_key"},
+}
+
+// NotificationSecretFields 获取通知渠道类型中需要加密保存的配置项
+func NotificationSecretFields(notificationType string) []string {
+	return notificationSecretFields[notificationType]
+}
+
+// encryptNotificationConfig 加密配置中的敏感字段，已加密的值保持不变
+func encryptNotificationConfig(notificationType string, config map[string]interface{}) {
+	for _, field := range notificationSecretFields[notificationType] {
+		if v, ok := config[field].(string); ok && v != "" {
+			if !strings.HasPrefix(v, "enc:") {
+				if enc, err := secret.EncryptStringWithAppKey(v); err == nil {
+					config[field] = enc
+				}
+			}
+		}
+	}
+}
+
 // AlertNotificationRepository 告警通知
 type AlertNotificationRepository struct{}
 
@@ -18,76 +47,155 @@ func NewAlertNotificationRepository() *AlertNotificationRepository {
 	return &AlertNotificationRepository{}
 }
 
-// GetByType 根据类型获取通知配置
+// GetByType 获取该类型最早创建的通知渠道，不存在时返回 nil
 func (r *AlertNotificationRepository) GetByType(notificationType string) (*models.AlertNotification, error) {
 	var notification models.AlertNotification
-	err := facades.Orm().Query().Where("notification_type", notificationType).First(&notification)
+	err := facades.Orm().Query().Where("notification_type", notificationType).OrderBy("id", "asc").First(&notification)
 	if err != nil {
 		return nil, err
 	}
+	if notification.ID == 0 {
+		return nil, nil
+	}
+	return &notification, nil
+}
+
+// GetByID 根据ID获取通知渠道，不存在时返回 nil
+func (r *AlertNotificationRepository) GetByID(id uint) (*models.AlertNotification, error) {
+	var notification models.AlertNotification
+	if err := facades.Orm().Query().Where("id", id).First(&notification); err != nil {
+		return nil, err
+	}
+	if notification.ID == 0 {
+		return nil, nil
+	}
 	return &notification, nil
 }
 
-// GetAll 获取所有通知配置
+// GetAll 获取所有通知渠道
 func (r *AlertNotificationRepository) GetAll() ([]*models.AlertNotification, error) {
 	var notifications []*models.AlertNotification
-	err := facades.Orm().Query().Get(&notifications)
+	err := facades.Orm().Query().OrderBy("id", "asc").Get(&notifications)
 	if err != nil {
 		return nil, err
 	}
 	return notifications, nil
 }
 
-// UpdateConfig 更新通知配置
-func (r *AlertNotificationRepository) UpdateConfig(notificationType string, config map[string]interface{}) error {
-	if notificationType == "email" {
-		if v, ok := config["password"].(string); ok && v != "" {
-			if !strings.HasPrefix(v, "enc:") {
-				if enc, err := secret.EncryptStringWithAppKey(v); err == nil {
-					config["password"] = enc
-				}
-			}
+// GetEnabled 获取所有已启用的通知渠道
+func (r *AlertNotificationRepository) GetEnabled() ([]*models.AlertNotification, error) {
+	var notifications []*models.AlertNotification
+	err := facades.Orm().Query().Where("enabled", true).OrderBy("id", "asc").Get(&notifications)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// GetEffectiveByServerID 获取服务器接收通知的渠道：服务器的订阅优先于分组的订阅，都未订阅时使用渠道的默认设置
+func (r *AlertNotificationRepository) GetEffectiveByServerID(serverID string) ([]*models.AlertNotification, error) {
+	notifications, err := r.GetEnabled()
+	if err != nil || len(notifications) == 0 {
+		return nil, err
+	}
+
+	var server models.Server
+	if err := facades.Orm().Query().Where("id", serverID).First(&server); err != nil {
+		return nil, err
+	}
+
+	subscribed := make(map[uint]bool)
+	subscriptionRepo := GetNotificationSubscriptionRepository()
+	if server.GroupID != nil {
+		groupSubscriptions, err := subscriptionRepo.GetByGroupID(*server.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, subscription := range groupSubscriptions {
+			subscribed[subscription.ChannelID] = subscription.Enabled
 		}
 	}
-	if notificationType == "webhook" {
-		if v, ok := config["webhook"].(string); ok && v != "" {
-			if !strings.HasPrefix(v, "enc:") {
-				if enc, err := secret.EncryptStringWithAppKey(v); err == nil {
-					config["webhook"] = enc
-				}
-			}
+	serverSubscriptions, err := subscriptionRepo.GetByServerID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range serverSubscriptions {
+		subscribed[subscription.ChannelID] = subscription.Enabled
+	}
+
+	effective := make([]*models.AlertNotification, 0, len(notifications))
+	for _, notification := range notifications {
+		enabled, ok := subscribed[notification.ID]
+		if !ok {
+			enabled = notification.IsDefault
+		}
+		if enabled {
+			effective = append(effective, notification)
 		}
 	}
+	return effective, nil
+}
+
+// Create 创建通知渠道，敏感字段加密保存
+func (r *AlertNotificationRepository) Create(notification *models.AlertNotification, config map[string]interface{}) error {
+	encryptNotificationConfig(notification.NotificationType, config)
 	configJson, err := json.Marshal(config)
 	if err != nil {
 		return err
 	}
+	notification.ConfigJson = string(configJson)
+	return facades.Orm().Query().Create(notification)
+}
 
-	var notification models.AlertNotification
-	err = facades.Orm().Query().Where("notification_type", notificationType).First(&notification)
+// Update 更新通知渠道，敏感字段加密保存
+func (r *AlertNotificationRepository) Update(notification *models.AlertNotification, config map[string]interface{}) error {
+	encryptNotificationConfig(notification.NotificationType, config)
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	notification.ConfigJson = string(configJson)
+	return facades.Orm().Query().Save(notification)
+}
+
+// Delete 删除通知渠道及其订阅
+func (r *AlertNotificationRepository) Delete(id uint) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Model(&models.NotificationSubscription{}).Where("channel_id", id).Delete(); err != nil {
+			return err
+		}
+		_, err := tx.Where("id", id).Delete(&models.AlertNotification{})
+		return err
+	})
+}
+
+// UpdateConfig 更新该类型最早创建的通知渠道的配置，不存在时创建默认渠道
+func (r *AlertNotificationRepository) UpdateConfig(notificationType string, config map[string]interface{}) error {
+	notification, err := r.GetByType(notificationType)
 	if err != nil {
+		return err
+	}
+	if notification == nil {
 		// 不存在则创建
-		notification = models.AlertNotification{
+		return r.Create(&models.AlertNotification{
+			Name:             notificationType,
 			NotificationType: notificationType,
 			Enabled:          true,
-			ConfigJson:       string(configJson),
-		}
-		return facades.Orm().Query().Create(&notification)
+			IsDefault:        true,
+		}, config)
 	}
 
 	// 存在则更新
-	notification.ConfigJson = string(configJson)
 	notification.Enabled = true
-	return facades.Orm().Query().Save(&notification)
+	return r.Update(notification, config)
 }
 
-// SetEnabled 设置启用状态
+// SetEnabled 设置该类型最早创建的通知渠道的启用状态
 func (r *AlertNotificationRepository) SetEnabled(notificationType string, enabled bool) error {
-	var notification models.AlertNotification
-	err := facades.Orm().Query().Where("notification_type", notificationType).First(&notification)
-	if err != nil {
+	notification, err := r.GetByType(notificationType)
+	if err != nil || notification == nil {
 		return err
 	}
 	notification.Enabled = enabled
-	return facades.Orm().Query().Save(&notification)
+	return facades.Orm().Query().Save(notification)
 }
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/orm"
+	"github.com/goravel/framework/facades"
+)
+
+// AlertProfileRepository 告警配置模板
+type AlertProfileRepository struct{}
+
+// NewAlertProfileRepository 创建告警配置模板实例
+func NewAlertProfileRepository() *AlertProfileRepository {
+	return &AlertProfileRepository{}
+}
+
+// GetAll 获取所有告警配置模板
+func (r *AlertProfileRepository) GetAll() ([]*models.AlertProfile, error) {
+	var profiles []*models.AlertProfile
+	err := facades.Orm().Query().OrderBy("id", "asc").Get(&profiles)
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetByID 根据ID获取告警配置模板，不存在时返回 nil
+func (r *AlertProfileRepository) GetByID(id uint) (*models.AlertProfile, error) {
+	var profile models.AlertProfile
+	if err := facades.Orm().Query().Where("id", id).First(&profile); err != nil {
+		return nil, err
+	}
+	if profile.ID == 0 {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+// Create 创建告警配置模板
+func (r *AlertProfileRepository) Create(profile *models.AlertProfile) error {
+	return facades.Orm().Query().Create(profile)
+}
+
+// CreateWithRules 在同一事务中创建告警配置模板及其规则，任一规则保存失败时不创建模板
+func (r *AlertProfileRepository) CreateWithRules(profile *models.AlertProfile, rules []*models.ServerAlertRule) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		if err := tx.Create(profile); err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			rule.ServerID, rule.GroupID, rule.ProfileID = nil, nil, &profile.ID
+			if err := tx.Create(rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update 更新告警配置模板
+func (r *AlertProfileRepository) Update(profile *models.AlertProfile) error {
+	return facades.Orm().Query().Save(profile)
+}
+
+// CountUsage 统计使用该模板的服务器和分组数量
+func (r *AlertProfileRepository) CountUsage(id uint) (int64, int64, error) {
+	servers, err := facades.Orm().Query().Model(&models.Server{}).Where("alert_profile_id", id).Count()
+	if err != nil {
+		return 0, 0, err
+	}
+	groups, err := facades.Orm().Query().Model(&models.ServerGroup{}).Where("alert_profile_id", id).Count()
+	if err != nil {
+		return 0, 0, err
+	}
+	return servers, groups, nil
+}
+
+// Assign 将服务器和分组的告警配置模板设置为 profileID，profileID 为空时取消模板
+func (r *AlertProfileRepository) Assign(profileID *uint, serverIDs []string, groupIDs []uint) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		if len(serverIDs) > 0 {
+			if _, err := tx.Model(&models.Server{}).
+				WhereIn("id", stringsToInterfaceSlice(serverIDs)).
+				Update("alert_profile_id", profileID); err != nil {
+				return err
+			}
+		}
+		if len(groupIDs) > 0 {
+			ids := make([]interface{}, len(groupIDs))
+			for i, groupID := range groupIDs {
+				ids[i] = groupID
+			}
+			if _, err := tx.Model(&models.ServerGroup{}).WhereIn("id", ids).Update("alert_profile_id", profileID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete 删除告警配置模板及其规则，并解除服务器和分组对该模板的引用
+func (r *AlertProfileRepository) Delete(id uint) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Model(&models.Server{}).Where("alert_profile_id", id).Update("alert_profile_id", nil); err != nil {
+			return err
+		}
+		if _, err := tx.Model(&models.ServerGroup{}).Where("alert_profile_id", id).Update("alert_profile_id", nil); err != nil {
+			return err
+		}
+		if _, err := tx.Model(&models.ServerAlertRule{}).
+			Where("server_id", nil).
+			Where("group_id", nil).
+			Where("profile_id", id).
+			Delete(); err != nil {
+			return err
+		}
+		_, err := tx.Model(&models.AlertProfile{}).Where("id", id).Delete()
+		return err
+	})
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// AlertRepository 告警实例
+type AlertRepository struct{}
+
+// NewAlertRepository 创建告警实例
+func NewAlertRepository() *AlertRepository {
+	return &AlertRepository{}
+}
+
+// AlertFilter 告警列表筛选条件
+type AlertFilter struct {
+	ServerID     string
+	Status       string
+	Severity     string
+	Type         string
+	RuleKey      string
+	Acknowledged *bool
+	StartTime    *time.Time
+	EndTime      *time.Time
+	Page         int
+	PageSize     int
+}
+
+// Create 创建告警记录
+func (r *AlertRepository) Create(alert *models.Alert) error {
+	return facades.Orm().Query().Create(alert)
+}
+
+// Update 更新告警记录
+func (r *AlertRepository) Update(alert *models.Alert) error {
+	alert.UpdatedAt = time.Now()
+	return facades.Orm().Query().Save(alert)
+}
+
+// GetByID 根据ID获取告警，不存在时返回 nil
+func (r *AlertRepository) GetByID(id string) (*models.Alert, error) {
+	var alert models.Alert
+	if err := facades.Orm().Query().Where("id", id).First(&alert); err != nil {
+		return nil, err
+	}
+	if alert.ID == "" {
+		return nil, nil
+	}
+	return &alert, nil
+}
+
+// GetFiring 获取指定规则正在告警中的记录，不存在时返回 nil
+func (r *AlertRepository) GetFiring(serverID, ruleKey string) (*models.Alert, error) {
+	var alert models.Alert
+	err := facades.Orm().Query().
+		Where("server_id", serverID).
+		Where("rule_key", ruleKey).
+		Where("status", models.AlertStatusFiring).
+		OrderBy("timestamp", "desc").
+		First(&alert)
+	if err != nil {
+		return nil, err
+	}
+	if alert.ID == "" {
+		return nil, nil
+	}
+	return &alert, nil
+}
+
+// GetAllFiring 获取所有告警中的记录
+func (r *AlertRepository) GetAllFiring() ([]*models.Alert, error) {
+	var alerts []*models.Alert
+	err := facades.Orm().Query().Where("status", models.AlertStatusFiring).Get(&alerts)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetEscalating 获取配置了升级策略且尚未确认的告警中记录
+func (r *AlertRepository) GetEscalating() ([]*models.Alert, error) {
+	var alerts []*models.Alert
+	err := facades.Orm().Query().
+		Where("status", models.AlertStatusFiring).
+		Where("escalation_policy_id IS NOT NULL").
+		Where("acknowledged_at IS NULL").
+		Get(&alerts)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// DeleteResolvedBefore 删除开始时间早于指定时间的已恢复告警，告警中的记录不删除，返回删除的数量
+func (r *AlertRepository) DeleteResolvedBefore(before time.Time) (int64, error) {
+	result, err := facades.Orm().Query().Model(&models.Alert{}).
+		Where("status <> ?", models.AlertStatusFiring).
+		Where(timestampUnixSQL+" < ?", before.Unix()).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
+
+// List 按条件分页获取告警（按开始时间倒序），返回记录和总数
+func (r *AlertRepository) List(filter AlertFilter) ([]*models.Alert, int64, error) {
+	query := facades.Orm().Query().Model(&models.Alert{})
+	if filter.ServerID != "" {
+		query = query.Where("server_id", filter.ServerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status", filter.Status)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity", filter.Severity)
+	}
+	if filter.Type != "" {
+		query = query.Where("type", filter.Type)
+	}
+	if filter.RuleKey != "" {
+		query = query.Where("rule_key", filter.RuleKey)
+	}
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+	if filter.StartTime != nil {
+		query = query.Where("timestamp >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("timestamp <= ?", *filter.EndTime)
+	}
+
+	var alerts []*models.Alert
+	var total int64
+	if err := query.OrderBy("timestamp", "desc").Paginate(filter.Page, filter.PageSize, &alerts, &total); err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// AlertSilenceRepository 告警静默
+type AlertSilenceRepository struct{}
+
+// NewAlertSilenceRepository 创建告警静默实例
+func NewAlertSilenceRepository() *AlertSilenceRepository {
+	return &AlertSilenceRepository{}
+}
+
+// GetAll 获取所有静默规则
+func (r *AlertSilenceRepository) GetAll() ([]*models.AlertSilence, error) {
+	var silences []*models.AlertSilence
+	err := facades.Orm().Query().OrderBy("id", "desc").Get(&silences)
+	if err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// GetByID 根据ID获取静默规则，不存在时返回 nil
+func (r *AlertSilenceRepository) GetByID(id uint) (*models.AlertSilence, error) {
+	var silence models.AlertSilence
+	if err := facades.Orm().Query().Where("id", id).First(&silence); err != nil {
+		return nil, err
+	}
+	if silence.ID == 0 {
+		return nil, nil
+	}
+	return &silence, nil
+}
+
+// Create 创建静默规则
+func (r *AlertSilenceRepository) Create(silence *models.AlertSilence) error {
+	return facades.Orm().Query().Create(silence)
+}
+
+// Update 更新静默规则
+func (r *AlertSilenceRepository) Update(silence *models.AlertSilence) error {
+	return facades.Orm().Query().Save(silence)
+}
+
+// Delete 删除静默规则
+func (r *AlertSilenceRepository) Delete(id uint) error {
+	_, err := facades.Orm().Query().Model(&models.AlertSilence{}).Where("id", id).Delete()
+	return err
+}
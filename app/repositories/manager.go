@@ -9,7 +9,17 @@ var (
 	alertNotificationRepoOnce          sync.Once
 	serverGroupRepoOnce                sync.Once
 	serverAlertRuleRepoOnce            sync.Once
-	serverNotificationChannelRepoOnce  sync.Once
+	notificationSubscriptionRepoOnce   sync.Once
+	serviceMonitorAlertRepoOnce        sync.Once
+	serverGPUMetricRepoOnce            sync.Once
+	serverSensorReadingRepoOnce        sync.Once
+	agentRolloutRepoOnce               sync.Once
+	alertRepoOnce                      sync.Once
+	alertSilenceRepoOnce               sync.Once
+	alertEscalationPolicyRepoOnce      sync.Once
+	serverMetricBaselineRepoOnce       sync.Once
+	alertProfileRepoOnce               sync.Once
+	notificationDeliveryRepoOnce       sync.Once
 
 	systemSettingRepoInstance             *SystemSettingRepository
 	serverRepoInstance                    *ServerRepository
@@ -17,7 +27,17 @@ var (
 	alertNotificationRepoInstance         *AlertNotificationRepository
 	serverGroupRepoInstance               *ServerGroupRepository
 	serverAlertRuleRepoInstance           *ServerAlertRuleRepository
-	serverNotificationChannelRepoInstance *ServerNotificationChannelRepository
+	notificationSubscriptionRepoInstance  *NotificationSubscriptionRepository
+	serviceMonitorAlertRepoInstance       *ServiceMonitorAlertRepository
+	serverGPUMetricRepoInstance           *ServerGPUMetricRepository
+	serverSensorReadingRepoInstance       *ServerSensorReadingRepository
+	agentRolloutRepoInstance              *AgentRolloutRepository
+	alertRepoInstance                     *AlertRepository
+	alertSilenceRepoInstance              *AlertSilenceRepository
+	alertEscalationPolicyRepoInstance     *AlertEscalationPolicyRepository
+	serverMetricBaselineRepoInstance      *ServerMetricBaselineRepository
+	alertProfileRepoInstance              *AlertProfileRepository
+	notificationDeliveryRepoInstance      *NotificationDeliveryRepository
 )
 
 // GetSystemSettingRepository 获取系统设置 Repository 单例
@@ -68,10 +88,90 @@ func GetServerAlertRuleRepository() *ServerAlertRuleRepository {
 	return serverAlertRuleRepoInstance
 }
 
-// GetServerNotificationChannelRepository 获取服务器通知渠道 Repository 单例
-func GetServerNotificationChannelRepository() *ServerNotificationChannelRepository {
-	serverNotificationChannelRepoOnce.Do(func() {
-		serverNotificationChannelRepoInstance = &ServerNotificationChannelRepository{}
+// GetNotificationSubscriptionRepository 获取通知渠道订阅 Repository 单例
+func GetNotificationSubscriptionRepository() *NotificationSubscriptionRepository {
+	notificationSubscriptionRepoOnce.Do(func() {
+		notificationSubscriptionRepoInstance = &NotificationSubscriptionRepository{}
 	})
-	return serverNotificationChannelRepoInstance
+	return notificationSubscriptionRepoInstance
+}
+
+// GetServiceMonitorAlertRepository 获取服务监控告警事件 Repository 单例
+func GetServiceMonitorAlertRepository() *ServiceMonitorAlertRepository {
+	serviceMonitorAlertRepoOnce.Do(func() {
+		serviceMonitorAlertRepoInstance = &ServiceMonitorAlertRepository{}
+	})
+	return serviceMonitorAlertRepoInstance
+}
+
+// GetServerGPUMetricRepository 获取服务器GPU指标 Repository 单例
+func GetServerGPUMetricRepository() *ServerGPUMetricRepository {
+	serverGPUMetricRepoOnce.Do(func() {
+		serverGPUMetricRepoInstance = &ServerGPUMetricRepository{}
+	})
+	return serverGPUMetricRepoInstance
+}
+
+// GetServerSensorReadingRepository 获取服务器传感器读数 Repository 单例
+func GetServerSensorReadingRepository() *ServerSensorReadingRepository {
+	serverSensorReadingRepoOnce.Do(func() {
+		serverSensorReadingRepoInstance = &ServerSensorReadingRepository{}
+	})
+	return serverSensorReadingRepoInstance
+}
+
+// GetAgentRolloutRepository 获取Agent发布计划 Repository 单例
+func GetAgentRolloutRepository() *AgentRolloutRepository {
+	agentRolloutRepoOnce.Do(func() {
+		agentRolloutRepoInstance = &AgentRolloutRepository{}
+	})
+	return agentRolloutRepoInstance
+}
+
+// GetAlertRepository 获取告警实例 Repository 单例
+func GetAlertRepository() *AlertRepository {
+	alertRepoOnce.Do(func() {
+		alertRepoInstance = &AlertRepository{}
+	})
+	return alertRepoInstance
+}
+
+// GetAlertSilenceRepository 获取告警静默 Repository 单例
+func GetAlertSilenceRepository() *AlertSilenceRepository {
+	alertSilenceRepoOnce.Do(func() {
+		alertSilenceRepoInstance = &AlertSilenceRepository{}
+	})
+	return alertSilenceRepoInstance
+}
+
+// GetAlertEscalationPolicyRepository 获取告警升级策略 Repository 单例
+func GetAlertEscalationPolicyRepository() *AlertEscalationPolicyRepository {
+	alertEscalationPolicyRepoOnce.Do(func() {
+		alertEscalationPolicyRepoInstance = &AlertEscalationPolicyRepository{}
+	})
+	return alertEscalationPolicyRepoInstance
+}
+
+// GetServerMetricBaselineRepository 获取服务器指标基线 Repository 单例
+func GetServerMetricBaselineRepository() *ServerMetricBaselineRepository {
+	serverMetricBaselineRepoOnce.Do(func() {
+		serverMetricBaselineRepoInstance = &ServerMetricBaselineRepository{}
+	})
+	return serverMetricBaselineRepoInstance
+}
+
+// GetAlertProfileRepository 获取告警配置模板 Repository 单例
+func GetAlertProfileRepository() *AlertProfileRepository {
+	alertProfileRepoOnce.Do(func() {
+		alertProfileRepoInstance = &AlertProfileRepository{}
+	})
+	return alertProfileRepoInstance
+}
+
+// GetNotificationDeliveryRepository 获取通知投递记录 Repository 单例
+func GetNotificationDeliveryRepository() *NotificationDeliveryRepository {
+	notificationDeliveryRepoOnce.Do(func() {
+		notificationDeliveryRepoInstance = &NotificationDeliveryRepository{}
+	})
+	return notificationDeliveryRepoInstance
 }
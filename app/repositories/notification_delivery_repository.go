@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// NotificationDeliveryRepository 通知投递记录
+type NotificationDeliveryRepository struct{}
+
+// NewNotificationDeliveryRepository 创建通知投递记录实例
+func NewNotificationDeliveryRepository() *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{}
+}
+
+// NotificationDeliveryFilter 投递记录查询条件
+type NotificationDeliveryFilter struct {
+	ChannelID   uint
+	ChannelType string
+	Status      string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Page        int
+	PageSize    int
+}
+
+// Create 创建投递记录
+func (r *NotificationDeliveryRepository) Create(delivery *models.NotificationDelivery) error {
+	return facades.Orm().Query().Create(delivery)
+}
+
+// Save 保存投递记录
+func (r *NotificationDeliveryRepository) Save(delivery *models.NotificationDelivery) error {
+	return facades.Orm().Query().Save(delivery)
+}
+
+// GetByID 根据ID获取投递记录，不存在时返回 nil
+func (r *NotificationDeliveryRepository) GetByID(id uint) (*models.NotificationDelivery, error) {
+	var delivery models.NotificationDelivery
+	if err := facades.Orm().Query().Where("id", id).First(&delivery); err != nil {
+		return nil, err
+	}
+	if delivery.ID == 0 {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+// List 分页查询投递记录，按创建时间倒序
+func (r *NotificationDeliveryRepository) List(filter NotificationDeliveryFilter) ([]*models.NotificationDelivery, int64, error) {
+	query := facades.Orm().Query().Model(&models.NotificationDelivery{})
+	if filter.ChannelID > 0 {
+		query = query.Where("channel_id", filter.ChannelID)
+	}
+	if filter.ChannelType != "" {
+		query = query.Where("channel_type", filter.ChannelType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status", filter.Status)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("created_at <= ?", *filter.EndTime)
+	}
+
+	var deliveries []*models.NotificationDelivery
+	var total int64
+	if err := query.OrderBy("id", "desc").Paginate(filter.Page, filter.PageSize, &deliveries, &total); err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// GetDueRetries 获取到达重试时间的投递记录
+func (r *NotificationDeliveryRepository) GetDueRetries(now time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	var deliveries []*models.NotificationDelivery
+	err := facades.Orm().Query().
+		Where("status", models.DeliveryStatusRetrying).
+		Where("next_retry_at <= ?", now).
+		OrderBy("next_retry_at", "asc").
+		Limit(limit).
+		Get(&deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// CreateAttempt 记录一次发送尝试
+func (r *NotificationDeliveryRepository) CreateAttempt(attempt *models.NotificationDeliveryAttempt) error {
+	return facades.Orm().Query().Create(attempt)
+}
+
+// GetAttempts 获取投递记录的所有发送尝试
+func (r *NotificationDeliveryRepository) GetAttempts(deliveryID uint) ([]*models.NotificationDeliveryAttempt, error) {
+	var attempts []*models.NotificationDeliveryAttempt
+	err := facades.Orm().Query().Where("delivery_id", deliveryID).OrderBy("attempt", "asc").Get(&attempts)
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// DeleteBefore 删除指定时间之前创建且不再重试的投递记录及其发送尝试
+func (r *NotificationDeliveryRepository) DeleteBefore(before time.Time) (int64, error) {
+	var ids []uint
+	err := facades.Orm().Query().Model(&models.NotificationDelivery{}).
+		Where("created_at < ?", before).
+		WhereIn("status", []any{models.DeliveryStatusSent, models.DeliveryStatusDead}).
+		Pluck("id", &ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for start := 0; start < len(ids); start += 500 {
+		batch := make([]any, 0, 500)
+		for _, id := range ids[start:min(start+500, len(ids))] {
+			batch = append(batch, id)
+		}
+		if _, err := facades.Orm().Query().Model(&models.NotificationDeliveryAttempt{}).WhereIn("delivery_id", batch).Delete(); err != nil {
+			return deleted, err
+		}
+		result, err := facades.Orm().Query().Model(&models.NotificationDelivery{}).WhereIn("id", batch).Delete()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
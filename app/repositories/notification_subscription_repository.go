@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/orm"
+	"github.com/goravel/framework/facades"
+)
+
+// NotificationSubscriptionRepository 通知渠道订阅
+type NotificationSubscriptionRepository struct{}
+
+// NewNotificationSubscriptionRepository 创建通知渠道订阅实例
+func NewNotificationSubscriptionRepository() *NotificationSubscriptionRepository {
+	return &NotificationSubscriptionRepository{}
+}
+
+// GetByServerID 获取服务器的通知渠道订阅
+func (r *NotificationSubscriptionRepository) GetByServerID(serverID string) ([]*models.NotificationSubscription, error) {
+	var subscriptions []*models.NotificationSubscription
+	err := facades.Orm().Query().Where("server_id", serverID).OrderBy("channel_id", "asc").Get(&subscriptions)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetByGroupID 获取分组的通知渠道订阅
+func (r *NotificationSubscriptionRepository) GetByGroupID(groupID uint) ([]*models.NotificationSubscription, error) {
+	var subscriptions []*models.NotificationSubscription
+	err := facades.Orm().Query().Where("group_id", groupID).OrderBy("channel_id", "asc").Get(&subscriptions)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Replace 替换服务器或分组的全部订阅，subscriptions 的键为渠道ID，值为订阅或退订
+func (r *NotificationSubscriptionRepository) Replace(serverID *string, groupID *uint, subscriptions map[uint]bool) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		query := tx.Model(&models.NotificationSubscription{})
+		if serverID != nil {
+			query = query.Where("server_id", *serverID)
+		} else {
+			query = query.Where("group_id", *groupID)
+		}
+		if _, err := query.Delete(); err != nil {
+			return err
+		}
+
+		for channelID, enabled := range subscriptions {
+			if err := tx.Create(&models.NotificationSubscription{
+				ChannelID: channelID,
+				ServerID:  serverID,
+				GroupID:   groupID,
+				Enabled:   enabled,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteByGroupID 删除分组的通知渠道订阅
+func (r *NotificationSubscriptionRepository) DeleteByGroupID(groupID uint) error {
+	_, err := facades.Orm().Query().Model(&models.NotificationSubscription{}).Where("group_id", groupID).Delete()
+	return err
+}
@@ -22,7 +22,7 @@ func (r *ServerAlertRuleRepository) GetByServerIDAndType(serverID *string, ruleT
 	var rule models.ServerAlertRule
 	query := facades.Orm().Query().Where("rule_type", ruleType)
 	if serverID == nil {
-		query = query.Where("server_id", nil)
+		query = query.Where("server_id", nil).Where("group_id", nil).Where("profile_id", nil)
 	} else {
 		query = query.Where("server_id", *serverID)
 	}
@@ -43,16 +43,106 @@ func (r *ServerAlertRuleRepository) GetByServerID(serverID string) ([]*models.Se
 	return rules, nil
 }
 
-// GetGlobalRules 获取所有全局规则（server_id 为 NULL）
+// GetGlobalRules 获取所有全局规则（server_id、group_id 和 profile_id 均为 NULL）
 func (r *ServerAlertRuleRepository) GetGlobalRules() ([]*models.ServerAlertRule, error) {
 	var rules []*models.ServerAlertRule
-	err := facades.Orm().Query().Where("server_id", nil).Get(&rules)
+	err := facades.Orm().Query().Where("server_id", nil).Where("group_id", nil).Where("profile_id", nil).Get(&rules)
 	if err != nil {
 		return nil, err
 	}
 	return rules, nil
 }
 
+// GetByGroupID 获取指定分组的所有规则
+func (r *ServerAlertRuleRepository) GetByGroupID(groupID uint) ([]*models.ServerAlertRule, error) {
+	var rules []*models.ServerAlertRule
+	err := facades.Orm().Query().Where("server_id", nil).Where("group_id", groupID).Get(&rules)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetByProfileID 获取指定告警配置模板的所有规则
+func (r *ServerAlertRuleRepository) GetByProfileID(profileID uint) ([]*models.ServerAlertRule, error) {
+	var rules []*models.ServerAlertRule
+	err := facades.Orm().Query().Where("server_id", nil).Where("group_id", nil).Where("profile_id", profileID).Get(&rules)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetEffectiveRules 获取服务器生效的规则，按 服务器 -> 服务器模板 -> 分组 -> 分组模板 -> 全局 的顺序取第一个配置的规则
+// 服务器为 inherit 模式时忽略服务器规则；返回以规则类型为键的规则记录，可通过 Source 判断来源
+func (r *ServerAlertRuleRepository) GetEffectiveRules(serverID string) (map[string]*models.ServerAlertRule, error) {
+	var server models.Server
+	if err := facades.Orm().Query().Where("id", serverID).First(&server); err != nil {
+		return nil, err
+	}
+
+	// 从优先级最低的全局规则开始，后面的规则覆盖前面的同类型规则
+	effective := make(map[string]*models.ServerAlertRule)
+	merge := func(rules []*models.ServerAlertRule) {
+		for _, rule := range rules {
+			effective[rule.RuleType] = rule
+		}
+	}
+
+	globalRules, err := r.GetGlobalRules()
+	if err != nil {
+		return nil, err
+	}
+	merge(globalRules)
+
+	if server.GroupID != nil {
+		var group models.ServerGroup
+		if err := facades.Orm().Query().Where("id", *server.GroupID).First(&group); err != nil {
+			return nil, err
+		}
+		if group.AlertProfileID != nil {
+			profileRules, err := r.GetByProfileID(*group.AlertProfileID)
+			if err != nil {
+				return nil, err
+			}
+			merge(profileRules)
+		}
+
+		groupRules, err := r.GetByGroupID(*server.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		merge(groupRules)
+	}
+
+	if server.AlertProfileID != nil {
+		profileRules, err := r.GetByProfileID(*server.AlertProfileID)
+		if err != nil {
+			return nil, err
+		}
+		merge(profileRules)
+	}
+
+	if server.AlertRulesMode != models.AlertRulesModeInherit {
+		serverRules, err := r.GetByServerID(serverID)
+		if err != nil {
+			return nil, err
+		}
+		merge(serverRules)
+	}
+
+	return effective, nil
+}
+
+// GetEffectiveByType 获取服务器指定类型生效的规则，没有配置时返回 nil
+func (r *ServerAlertRuleRepository) GetEffectiveByType(serverID, ruleType string) (*models.ServerAlertRule, error) {
+	rules, err := r.GetEffectiveRules(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return rules[ruleType], nil
+}
+
 // CreateOrUpdate 创建或更新规则
 func (r *ServerAlertRuleRepository) CreateOrUpdate(rule *models.ServerAlertRule) error {
 	// 验证必要字段
@@ -65,10 +155,14 @@ func (r *ServerAlertRuleRepository) CreateOrUpdate(rule *models.ServerAlertRule)
 
 	var existing models.ServerAlertRule
 	query := facades.Orm().Query().Where("rule_type", rule.RuleType)
-	if rule.ServerID == nil {
-		query = query.Where("server_id", nil)
-	} else {
+	if rule.ServerID != nil {
 		query = query.Where("server_id", *rule.ServerID)
+	} else if rule.GroupID != nil {
+		query = query.Where("server_id", nil).Where("group_id", *rule.GroupID)
+	} else if rule.ProfileID != nil {
+		query = query.Where("server_id", nil).Where("group_id", nil).Where("profile_id", *rule.ProfileID)
+	} else {
+		query = query.Where("server_id", nil).Where("group_id", nil).Where("profile_id", nil)
 	}
 	err := query.First(&existing)
 
@@ -102,10 +196,47 @@ func (r *ServerAlertRuleRepository) DeleteByServerID(serverID string) error {
 func (r *ServerAlertRuleRepository) DeleteByServerIDAndType(serverID *string, ruleType string) error {
 	query := facades.Orm().Query().Model(&models.ServerAlertRule{}).Where("rule_type", ruleType)
 	if serverID == nil {
-		query = query.Where("server_id", nil)
+		query = query.Where("server_id", nil).Where("group_id", nil).Where("profile_id", nil)
 	} else {
 		query = query.Where("server_id", *serverID)
 	}
 	_, err := query.Delete()
 	return err
 }
+
+// DeleteByGroupID 删除指定分组的所有规则
+func (r *ServerAlertRuleRepository) DeleteByGroupID(groupID uint) error {
+	_, err := facades.Orm().Query().Model(&models.ServerAlertRule{}).Where("server_id", nil).Where("group_id", groupID).Delete()
+	return err
+}
+
+// DeleteByGroupIDAndType 删除指定分组和规则类型的规则
+func (r *ServerAlertRuleRepository) DeleteByGroupIDAndType(groupID uint, ruleType string) error {
+	_, err := facades.Orm().Query().Model(&models.ServerAlertRule{}).
+		Where("server_id", nil).
+		Where("group_id", groupID).
+		Where("rule_type", ruleType).
+		Delete()
+	return err
+}
+
+// DeleteByProfileID 删除指定告警配置模板的所有规则
+func (r *ServerAlertRuleRepository) DeleteByProfileID(profileID uint) error {
+	_, err := facades.Orm().Query().Model(&models.ServerAlertRule{}).
+		Where("server_id", nil).
+		Where("group_id", nil).
+		Where("profile_id", profileID).
+		Delete()
+	return err
+}
+
+// DeleteByProfileIDAndType 删除指定告警配置模板和规则类型的规则
+func (r *ServerAlertRuleRepository) DeleteByProfileIDAndType(profileID uint, ruleType string) error {
+	_, err := facades.Orm().Query().Model(&models.ServerAlertRule{}).
+		Where("server_id", nil).
+		Where("group_id", nil).
+		Where("profile_id", profileID).
+		Where("rule_type", ruleType).
+		Delete()
+	return err
+}
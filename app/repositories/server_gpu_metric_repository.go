@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ServerGPUMetricRepository 服务器GPU指标
+type ServerGPUMetricRepository struct{}
+
+// NewServerGPUMetricRepository 创建服务器GPU指标实例
+func NewServerGPUMetricRepository() *ServerGPUMetricRepository {
+	return &ServerGPUMetricRepository{}
+}
+
+// BatchCreate 批量创建GPU指标记录
+func (r *ServerGPUMetricRepository) BatchCreate(metrics []*models.ServerGPUMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	return facades.Orm().Query().Create(&metrics)
+}
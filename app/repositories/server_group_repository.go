@@ -55,6 +55,13 @@ func (r *ServerGroupRepository) Delete(id uint) error {
 			return updateErr
 		}
 	}
+	// 删除分组的告警规则，分组内服务器改为继承全局规则
+	if err := GetServerAlertRuleRepository().DeleteByGroupID(id); err != nil {
+		return err
+	}
+	if err := GetNotificationSubscriptionRepository().DeleteByGroupID(id); err != nil {
+		return err
+	}
 	_, err = facades.Orm().Query().Where("id", id).Delete(&models.ServerGroup{})
 	return err
 }
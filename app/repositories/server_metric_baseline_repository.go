@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/orm"
+	"github.com/goravel/framework/facades"
+)
+
+// ServerMetricBaselineRepository 服务器指标基线
+type ServerMetricBaselineRepository struct{}
+
+// NewServerMetricBaselineRepository 创建服务器指标基线实例
+func NewServerMetricBaselineRepository() *ServerMetricBaselineRepository {
+	return &ServerMetricBaselineRepository{}
+}
+
+// GetByServerID 获取服务器的所有基线
+func (r *ServerMetricBaselineRepository) GetByServerID(serverID string) ([]*models.ServerMetricBaseline, error) {
+	var baselines []*models.ServerMetricBaseline
+	err := facades.Orm().Query().
+		Where("server_id", serverID).
+		OrderBy("metric").
+		OrderBy("hour_of_week").
+		Get(&baselines)
+	if err != nil {
+		return nil, err
+	}
+	return baselines, nil
+}
+
+// Get 获取服务器指标在一周中某个小时的基线，不存在时返回 nil
+func (r *ServerMetricBaselineRepository) Get(serverID, metric string, hourOfWeek int) (*models.ServerMetricBaseline, error) {
+	var baseline models.ServerMetricBaseline
+	err := facades.Orm().Query().
+		Where("server_id", serverID).
+		Where("metric", metric).
+		Where("hour_of_week", hourOfWeek).
+		First(&baseline)
+	if err != nil {
+		return nil, err
+	}
+	if baseline.ID == 0 {
+		return nil, nil
+	}
+	return &baseline, nil
+}
+
+// ReplaceByServerID 用新计算的基线替换服务器原有的基线
+func (r *ServerMetricBaselineRepository) ReplaceByServerID(serverID string, baselines []*models.ServerMetricBaseline) error {
+	return facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Model(&models.ServerMetricBaseline{}).Where("server_id", serverID).Delete(); err != nil {
+			return err
+		}
+		if len(baselines) == 0 {
+			return nil
+		}
+		return tx.Create(&baselines)
+	})
+}
@@ -69,16 +69,20 @@ func (r *ServerMetricRepository) Create(metric *models.ServerMetric) error {
 	return facades.Orm().Query().Create(metric)
 }
 
-// GetHistory 获取历史指标数据
+// GetHistory 获取历史指标数据，按转换后的 Unix 时间戳比较和排序
 func (r *ServerMetricRepository) GetHistory(serverID string, startTime, endTime time.Time) ([]*models.ServerMetric, error) {
+	sql := `SELECT * FROM (
+			SELECT
+				*,
+				` + timestampUnixSQL + ` AS timestamp_unix
+			FROM server_metrics
+			WHERE server_id = ?
+		)
+		WHERE timestamp_unix >= ? AND timestamp_unix <= ?
+		ORDER BY timestamp_unix ASC`
+
 	var metrics []*models.ServerMetric
-	err := facades.Orm().Query().
-		Where("server_id", serverID).
-		Where("timestamp", ">=", startTime).
-		Where("timestamp", "<=", endTime).
-		OrderBy("timestamp", "asc").
-		Get(&metrics)
-	if err != nil {
+	if err := facades.Orm().Query().Raw(sql, serverID, startTime.Unix(), endTime.Unix()).Scan(&metrics); err != nil {
 		return nil, err
 	}
 	return metrics, nil
@@ -91,3 +95,72 @@ func (r *ServerMetricRepository) BatchCreate(metrics []*models.ServerMetric) err
 	}
 	return facades.Orm().Query().Create(&metrics)
 }
+
+// MetricHourlyStats 指标在一个小时内的汇总，用于计算基线
+type MetricHourlyStats struct {
+	Hour    int64   `gorm:"column:hour"` // 小时起始时间的 Unix 时间戳
+	Samples int     `gorm:"column:samples"`
+	CPUSum  float64 `gorm:"column:cpu_sum"`
+	CPUSq   float64 `gorm:"column:cpu_sq"`
+	MemSum  float64 `gorm:"column:mem_sum"`
+	MemSq   float64 `gorm:"column:mem_sq"`
+	DiskSum float64 `gorm:"column:disk_sum"`
+	DiskSq  float64 `gorm:"column:disk_sq"`
+}
+
+// GetHourlyStats 按小时汇总服务器从 since 开始的指标，返回每小时的样本数、总和与平方和
+func (r *ServerMetricRepository) GetHourlyStats(serverID string, since time.Time) ([]MetricHourlyStats, error) {
+	sql := `SELECT
+			(timestamp_unix / 3600) * 3600 AS hour,
+			COUNT(*) AS samples,
+			SUM(cpu_usage) AS cpu_sum, SUM(cpu_usage * cpu_usage) AS cpu_sq,
+			SUM(memory_usage) AS mem_sum, SUM(memory_usage * memory_usage) AS mem_sq,
+			SUM(disk_usage) AS disk_sum, SUM(disk_usage * disk_usage) AS disk_sq
+		FROM (
+			SELECT
+				` + timestampUnixSQL + ` AS timestamp_unix,
+				cpu_usage, memory_usage, disk_usage
+			FROM server_metrics
+			WHERE server_id = ?
+		)
+		WHERE timestamp_unix >= ?
+		GROUP BY timestamp_unix / 3600
+		ORDER BY hour ASC`
+
+	var stats []MetricHourlyStats
+	if err := facades.Orm().Query().Raw(sql, serverID, since.Unix()).Scan(&stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// MetricPoint 时间序列中的一个点
+type MetricPoint struct {
+	Timestamp int64   `gorm:"column:timestamp"` // 时间段起始时间的 Unix 时间戳
+	Value     float64 `gorm:"column:value"`
+}
+
+// GetDiskUsageSeries 获取服务器从 since 开始的磁盘使用率序列，按 bucket 时长取平均值
+func (r *ServerMetricRepository) GetDiskUsageSeries(serverID string, since time.Time, bucket time.Duration) ([]MetricPoint, error) {
+	bucketSeconds := max(int64(bucket.Seconds()), 1)
+	sql := `SELECT
+			(timestamp_unix / ?) * ? AS timestamp,
+			AVG(disk_usage) AS value
+		FROM (
+			SELECT
+				` + timestampUnixSQL + ` AS timestamp_unix,
+				disk_usage
+			FROM server_metrics
+			WHERE server_id = ?
+		)
+		WHERE timestamp_unix >= ?
+		GROUP BY timestamp_unix / ?
+		ORDER BY timestamp ASC`
+
+	var points []MetricPoint
+	err := facades.Orm().Query().Raw(sql, bucketSeconds, bucketSeconds, serverID, since.Unix(), bucketSeconds).Scan(&points)
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
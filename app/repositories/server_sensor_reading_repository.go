@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ServerSensorReadingRepository 服务器传感器读数
+type ServerSensorReadingRepository struct{}
+
+// NewServerSensorReadingRepository 创建服务器传感器读数实例
+func NewServerSensorReadingRepository() *ServerSensorReadingRepository {
+	return &ServerSensorReadingRepository{}
+}
+
+// BatchCreate 批量创建传感器读数记录
+func (r *ServerSensorReadingRepository) BatchCreate(readings []*models.ServerSensorReading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	return facades.Orm().Query().Create(&readings)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ServiceMonitorAlertRepository 服务监控告警事件
+type ServiceMonitorAlertRepository struct{}
+
+// NewServiceMonitorAlertRepository 创建服务监控告警事件实例
+func NewServiceMonitorAlertRepository() *ServiceMonitorAlertRepository {
+	return &ServiceMonitorAlertRepository{}
+}
+
+// Create 创建事件记录
+func (r *ServiceMonitorAlertRepository) Create(alert *models.ServiceMonitorAlert) error {
+	return facades.Orm().Query().Create(alert)
+}
+
+// GetLatestByService 获取指定服务在某时间点之前的最后一条事件
+func (r *ServiceMonitorAlertRepository) GetLatestByService(serverID, serviceName string, before time.Time) (*models.ServiceMonitorAlert, error) {
+	var alert models.ServiceMonitorAlert
+	err := facades.Orm().Query().
+		Where("server_id", serverID).
+		Where("service_name", serviceName).
+		Where("timestamp < ?", before).
+		OrderBy("timestamp", "desc").
+		First(&alert)
+	if err != nil {
+		return nil, err
+	}
+	if alert.ID == "" {
+		return nil, nil
+	}
+	return &alert, nil
+}
+
+// GetByServerID 获取指定服务器在时间范围内的事件（按时间升序）
+func (r *ServiceMonitorAlertRepository) GetByServerID(serverID string, startTime, endTime time.Time) ([]*models.ServiceMonitorAlert, error) {
+	var alerts []*models.ServiceMonitorAlert
+	err := facades.Orm().Query().
+		Where("server_id", serverID).
+		Where("timestamp >= ?", startTime).
+		Where("timestamp <= ?", endTime).
+		OrderBy("timestamp", "asc").
+		Get(&alerts)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
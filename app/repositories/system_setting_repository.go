@@ -51,7 +51,8 @@ func (r *SystemSettingRepository) SetValue(key, value string) error {
 	var existing models.SystemSetting
 	err := facades.Orm().Query().Where("setting_key", key).First(&existing)
 
-	if err != nil {
+	// 记录不存在时 First 不一定返回错误，需同时检查 ID
+	if err != nil || existing.ID == 0 {
 		return facades.Orm().Query().Model(&models.SystemSetting{}).Create(map[string]any{
 			"setting_key":   key,
 			"setting_value": value,
@@ -12,3 +12,10 @@ func stringsToInterfaceSlice(strs []string) []interface{} {
 	return result
 }
 
+
+// timestampUnixSQL 将 timestamp 列转换为 Unix 时间戳的 SQL 表达式
+// timestamp 可能以整数或时间字符串保存，按时间比较、排序和分组前需先统一转换
+const timestampUnixSQL = `CASE
+	WHEN typeof(timestamp) = 'integer' THEN timestamp
+	ELSE CAST(strftime('%s', datetime(timestamp)) AS INTEGER)
+END`
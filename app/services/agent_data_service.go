@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"goravel/app/models"
+	"goravel/app/repositories"
 
 	"github.com/goravel/framework/facades"
 )
@@ -33,16 +34,19 @@ func (j *saveSystemInfoJob) Execute() error {
 	}
 
 	if hostname, ok := j.data["hostname"].(string); ok {
-		updates["name"] = hostname
+		updates["hostname"] = hostname
 	}
 	if osInfo, ok := j.data["os"].(string); ok {
-		updates["os_type"] = osInfo
+		updates["os"] = osInfo
 	}
 	if kernel, ok := j.data["kernel"].(string); ok {
-		updates["kernel_version"] = kernel
+		updates["kernel"] = kernel
 	}
 	if uptime, ok := j.data["uptime"].(float64); ok {
-		updates["uptime"] = int64(uptime)
+		updates["uptime_seconds"] = int64(uptime)
+	}
+	if version := ExtractAgentVersion(j.data); version != "" {
+		updates["agent_version"] = version
 	}
 	if bootTime, ok := j.data["boot_time"].(string); ok {
 		if t, err := time.Parse(time.RFC3339, bootTime); err == nil {
@@ -90,6 +94,22 @@ func (j *saveMetricsJob) Execute() error {
 
 	// 使用批量写入缓冲区代替直接写入数据库
 	GetMetricBuffer().Enqueue(metric)
+
+	// 记录到指标窗口供表达式规则使用，同时保留上报的原始数值字段
+	window := GetMetricWindow()
+	window.RecordFields(j.serverID, "metrics", j.data, metric.Timestamp)
+	window.Record(j.serverID, map[string]float64{
+		"cpu_usage":        cpuUsage,
+		"memory_usage":     memoryUsage,
+		"disk_usage":       diskUsage,
+		"network_upload":   netUp,
+		"network_download": netDown,
+	}, metric.Timestamp)
+
+	// 检查告警
+	if err := NewAlertService().CheckAndAlert(j.serverID, j.data); err != nil {
+		facades.Log().Warningf("告警检查失败: %v", err)
+	}
 	return nil
 }
 
@@ -155,6 +175,8 @@ func (j *saveAgentLogsJob) Execute() error {
 // SaveMemoryInfo 保存内存信息
 func SaveMemoryInfo(serverID string, data map[string]interface{}) error {
 	// TODO: 实现保存逻辑
+	// 数值字段记录到指标窗口，表达式规则中以 memory.字段名 引用
+	GetMetricWindow().RecordFields(serverID, "memory", data, time.Now())
 	return nil
 }
 
@@ -167,18 +189,24 @@ func SaveDiskInfo(serverID string, data []interface{}) error {
 // SaveDiskIO 保存磁盘IO信息
 func SaveDiskIO(serverID string, data map[string]interface{}) error {
 	// TODO: 实现保存逻辑
+	// 数值字段记录到指标窗口，表达式规则中以 disk_io.字段名 引用
+	GetMetricWindow().RecordFields(serverID, "disk_io", data, time.Now())
 	return nil
 }
 
 // SaveNetworkInfo 保存网络信息
 func SaveNetworkInfo(serverID string, data map[string]interface{}) error {
 	// TODO: 实现保存逻辑
+	// 数值字段记录到指标窗口，表达式规则中以 network.字段名 引用
+	GetMetricWindow().RecordFields(serverID, "network", data, time.Now())
 	return nil
 }
 
 // SaveSwapInfo 保存Swap信息
 func SaveSwapInfo(serverID string, data map[string]interface{}) error {
 	// TODO: 实现保存逻辑
+	// 数值字段记录到指标窗口，表达式规则中以 swap.字段名 引用
+	GetMetricWindow().RecordFields(serverID, "swap", data, time.Now())
 	return nil
 }
 
@@ -198,12 +226,24 @@ type saveProcessInfoJob struct {
 }
 
 func (j *saveProcessInfoJob) Execute() error {
-	// 更新 servers 表中的 service_status 字段
-	_, err := facades.Orm().Query().Model(&models.Server{}).Where("id = ?", j.serverID).Update(map[string]interface{}{
-		"service_status": j.data,
+	// 更新 servers 表中的 service_status 字段（map 更新不会经过模型的 json serializer，需手动序列化）
+	serviceStatusJson, err := json.Marshal(j.data)
+	if err != nil {
+		return err
+	}
+	_, err = facades.Orm().Query().Model(&models.Server{}).Where("id = ?", j.serverID).Update(map[string]interface{}{
+		"service_status": string(serviceStatusJson),
 		"updated_at":     time.Now(),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// 检查被监控服务的状态变化并触发告警
+	if err := NewAlertService().CheckServiceStatus(j.serverID, j.data); err != nil {
+		facades.Log().Warningf("服务监控告警检查失败: %v", err)
+	}
+	return nil
 }
 
 // SaveGPUInfo 保存GPU信息
@@ -223,11 +263,218 @@ type saveGPUInfoJob struct {
 
 func (j *saveGPUInfoJob) Execute() error {
 	// 更新 servers 表中的 gpu_info 字段
-	_, err := facades.Orm().Query().Model(&models.Server{}).Where("id = ?", j.serverID).Update(map[string]interface{}{
-		"gpu_info":   j.data,
+	gpuInfoJson, err := json.Marshal(j.data)
+	if err != nil {
+		return err
+	}
+	_, err = facades.Orm().Query().Model(&models.Server{}).Where("id = ?", j.serverID).Update(map[string]interface{}{
+		"gpu_info":   string(gpuInfoJson),
 		"updated_at": time.Now(),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// 保存每块GPU的时序指标
+	gpuMetrics := ParseGPUMetrics(j.serverID, j.data, time.Now())
+	if len(gpuMetrics) == 0 {
+		return nil
+	}
+	if err := repositories.GetServerGPUMetricRepository().BatchCreate(gpuMetrics); err != nil {
+		facades.Log().Errorf("保存GPU指标失败: %v", err)
+		return err
+	}
+
+	// 记录到指标窗口供表达式规则使用，例如 gpu_util:0
+	values := make(map[string]float64)
+	for _, metric := range gpuMetrics {
+		values[fmt.Sprintf("gpu_util:%d", metric.GPUIndex)] = metric.Utilization
+		values[fmt.Sprintf("gpu_temp:%d", metric.GPUIndex)] = metric.Temperature
+		values[fmt.Sprintf("gpu_power:%d", metric.GPUIndex)] = metric.PowerDraw
+		if metric.MemoryTotal > 0 {
+			values[fmt.Sprintf("gpu_mem:%d", metric.GPUIndex)] = metric.MemoryUsagePercent()
+		}
+	}
+	GetMetricWindow().Record(j.serverID, values, gpuMetrics[0].Timestamp)
+
+	// 检查GPU告警
+	if err := NewAlertService().CheckGPUMetrics(j.serverID, gpuMetrics); err != nil {
+		facades.Log().Warningf("GPU告警检查失败: %v", err)
+	}
+	return nil
+}
+
+// ParseGPUMetrics 解析 gpu_info 中的每块GPU指标
+// 兼容 {"gpus": [...]}、{"devices": [...]} 格式，字段名兼容 nvidia-smi 常见命名
+func ParseGPUMetrics(serverID string, data map[string]interface{}, timestamp time.Time) []*models.ServerGPUMetric {
+	var items []interface{}
+	for _, key := range []string{"gpus", "devices", "list"} {
+		if list, ok := data[key].([]interface{}); ok {
+			items = list
+			break
+		}
+	}
+
+	metrics := make([]*models.ServerGPUMetric, 0, len(items))
+	for i, item := range items {
+		gpu, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		metric := &models.ServerGPUMetric{
+			ServerID:    serverID,
+			GPUIndex:    i,
+			Utilization: firstFloat(gpu, "utilization", "utilization_gpu", "gpu_util", "usage"),
+			MemoryUsed:  firstFloat(gpu, "memory_used", "mem_used"),
+			MemoryTotal: firstFloat(gpu, "memory_total", "mem_total"),
+			Temperature: firstFloat(gpu, "temperature", "temperature_gpu", "temp"),
+			PowerDraw:   firstFloat(gpu, "power_draw", "power_usage", "power"),
+			Timestamp:   timestamp,
+		}
+		if index, ok := gpu["index"].(float64); ok {
+			metric.GPUIndex = int(index)
+		}
+		if name, ok := gpu["name"].(string); ok {
+			metric.Name = name
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
+
+// firstFloat 按顺序读取第一个存在的数值字段
+func firstFloat(data map[string]interface{}, keys ...string) float64 {
+	for _, key := range keys {
+		switch v := data[key].(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	}
+	return 0
+}
+
+// SaveSensors 保存硬件传感器数据
+func SaveSensors(serverID string, data map[string]interface{}) error {
+	worker := GetGlobalDataWorker()
+	worker.Enqueue(&saveSensorsJob{
+		serverID: serverID,
+		data:     data,
+	})
+	return nil
+}
+
+type saveSensorsJob struct {
+	serverID string
+	data     map[string]interface{}
+}
+
+func (j *saveSensorsJob) Execute() error {
+	readings := ParseSensorReadings(j.serverID, j.data, time.Now())
+	if len(readings) == 0 {
+		return nil
+	}
+	if err := repositories.GetServerSensorReadingRepository().BatchCreate(readings); err != nil {
+		facades.Log().Errorf("保存传感器数据失败: %v", err)
+		return err
+	}
+
+	// 按传感器类型记录最高温度到指标窗口，例如 temperature:cpu_package
+	values := make(map[string]float64)
+	for _, reading := range readings {
+		key := "temperature:" + reading.SensorType
+		if current, ok := values[key]; !ok || reading.Temperature > current {
+			values[key] = reading.Temperature
+		}
+	}
+	GetMetricWindow().Record(j.serverID, values, readings[0].Timestamp)
+
+	// 检查温度告警
+	if err := NewAlertService().CheckTemperature(j.serverID, readings); err != nil {
+		facades.Log().Warningf("温度告警检查失败: %v", err)
+	}
+	return nil
+}
+
+// ParseSensorReadings 解析 sensors 消息中的温度传感器读数
+// 支持列表格式 {"sensors": [{"type": "cpu_core", "label": "Core 0", "temperature": 55}]}，
+// 以及分组格式 {"cpu_package": 60, "cpu_cores": [55, 56], "nvme": [{"label": "nvme0", "temperature": 40}], "chassis": 35}
+func ParseSensorReadings(serverID string, data map[string]interface{}, timestamp time.Time) []*models.ServerSensorReading {
+	readings := make([]*models.ServerSensorReading, 0)
+	add := func(sensorType string, index int, value interface{}) {
+		label := ""
+		var temperature float64
+		switch v := value.(type) {
+		case float64:
+			temperature = v
+		case map[string]interface{}:
+			temperature = firstFloat(v, "temperature", "temp", "value", "current")
+			if l, ok := v["label"].(string); ok {
+				label = l
+			} else if l, ok := v["name"].(string); ok {
+				label = l
+			}
+			if t, ok := v["type"].(string); ok && t != "" {
+				sensorType = t
+			}
+		default:
+			return
+		}
+		if sensorType == "" {
+			sensorType = "other"
+		}
+
+		key := fmt.Sprintf("%s:%d", sensorType, index)
+		if label != "" {
+			key = fmt.Sprintf("%s:%s", sensorType, label)
+		} else {
+			label = fmt.Sprintf("%s %d", sensorType, index)
+		}
+		readings = append(readings, &models.ServerSensorReading{
+			ServerID:    serverID,
+			SensorKey:   key,
+			SensorType:  sensorType,
+			Label:       label,
+			Temperature: temperature,
+			Timestamp:   timestamp,
+		})
+	}
+	addAll := func(sensorType string, value interface{}) {
+		if list, ok := value.([]interface{}); ok {
+			for i, item := range list {
+				add(sensorType, i, item)
+			}
+			return
+		}
+		add(sensorType, 0, value)
+	}
+
+	if sensors, ok := data["sensors"]; ok {
+		addAll("", sensors)
+		return readings
+	}
+
+	groups := map[string][]string{
+		"cpu_package": {"cpu_package", "package"},
+		"cpu_core":    {"cpu_cores", "cores", "cpu_core"},
+		"nvme":        {"nvme"},
+		"chassis":     {"chassis", "board"},
+	}
+	for _, sensorType := range []string{"cpu_package", "cpu_core", "nvme", "chassis"} {
+		for _, key := range groups[sensorType] {
+			if value, ok := data[key]; ok {
+				addAll(sensorType, value)
+				break
+			}
+		}
+	}
+
+	return readings
 }
 
 // CalculateUptime 计算运行时间
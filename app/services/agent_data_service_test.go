@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+// gpuInfoPayload Agent 上报的 gpu_info 消息，0 号GPU超过全部阈值，1 号GPU正常
+const gpuInfoPayload = `{
+	"gpus": [
+		{"index": 0, "name": "NVIDIA A100", "utilization": 99, "memory_used": 78000, "memory_total": 80000, "temperature": 92, "power_draw": 380},
+		{"index": 1, "name": "NVIDIA A100", "utilization": 12, "memory_used": 4000, "memory_total": 80000, "temperature": 45, "power_draw": 60}
+	]
+}`
+
+func TestSaveGPUInfo(t *testing.T) {
+	tests.NewDatabase(t)
+
+	serverID := "gpu-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "gpu-node", IP: "10.0.0.8", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	alertService := services.NewAlertService()
+	if err := alertService.SaveServerRules(&serverID, map[string]services.Rule{
+		"gpu_util": {Enabled: true, Warning: 90, Critical: 98},
+		"gpu_mem":  {Enabled: true, Warning: 90, Critical: 98},
+		"gpu_temp": {Enabled: true, Warning: 80, Critical: 90},
+	}); err != nil {
+		t.Fatalf("保存GPU告警规则失败: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(gpuInfoPayload), &data); err != nil {
+		t.Fatal(err)
+	}
+	if err := services.ExecuteSaveGPUInfo(serverID, data); err != nil {
+		t.Fatalf("保存GPU信息失败: %v", err)
+	}
+
+	// 每块GPU按序号保存一条指标
+	var metrics []models.ServerGPUMetric
+	if err := facades.Orm().Query().Where("server_id", serverID).Order("gpu_index").Find(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("GPU指标数量为 %d，期望 2", len(metrics))
+	}
+	wantMetrics := []struct {
+		index       int
+		utilization float64
+		memoryUsed  float64
+		temperature float64
+	}{
+		{0, 99, 78000, 92},
+		{1, 12, 4000, 45},
+	}
+	for i, want := range wantMetrics {
+		got := metrics[i]
+		if got.GPUIndex != want.index || got.Utilization != want.utilization || got.MemoryUsed != want.memoryUsed || got.Temperature != want.temperature {
+			t.Errorf("GPU %d 指标为 %+v，期望 %+v", i, got, want)
+		}
+		if got.Name != "NVIDIA A100" || got.MemoryTotal != 80000 {
+			t.Errorf("GPU %d 名称或显存总量错误: %+v", i, got)
+		}
+	}
+
+	// 0 号GPU的三项指标分别产生告警并关联对应的规则记录，1 号GPU不产生告警
+	var alerts []models.Alert
+	if err := facades.Orm().Query().Where("server_id", serverID).Find(&alerts); err != nil {
+		t.Fatal(err)
+	}
+	severities := make(map[string]string)
+	for _, alert := range alerts {
+		if alert.Status != "firing" {
+			t.Errorf("告警 %s 状态为 %s，期望 firing", alert.RuleKey, alert.Status)
+		}
+		if alert.RuleID == nil {
+			t.Errorf("告警 %s 未关联规则记录", alert.RuleKey)
+		}
+		severities[alert.RuleKey] = alert.Severity
+	}
+	wantAlerts := map[string]string{
+		"gpu_util:0": "critical",
+		"gpu_mem:0":  "warning",
+		"gpu_temp:0": "critical",
+	}
+	if len(severities) != len(wantAlerts) {
+		t.Errorf("告警为 %v，期望 %v", severities, wantAlerts)
+	}
+	for ruleKey, severity := range wantAlerts {
+		if severities[ruleKey] != severity {
+			t.Errorf("告警 %s 级别为 %q，期望 %q", ruleKey, severities[ruleKey], severity)
+		}
+	}
+}
+
+func TestSaveSystemInfo(t *testing.T) {
+	tests.NewDatabase(t)
+
+	serverID := "system-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "生产数据库", IP: "10.0.0.11", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+
+	if err := services.ExecuteSaveSystemInfo(serverID, map[string]interface{}{
+		"hostname":      "db-01",
+		"os":            "Ubuntu 24.04",
+		"kernel":        "6.8.0-45-generic",
+		"uptime":        float64(3600),
+		"agent_version": "v1.4.2",
+		"boot_time":     "2026-01-01T00:00:00Z",
+	}); err != nil {
+		t.Fatalf("保存系统信息失败: %v", err)
+	}
+
+	var server models.Server
+	if err := facades.Orm().Query().Where("id", serverID).First(&server); err != nil {
+		t.Fatal(err)
+	}
+	// 主机名写入 hostname，不覆盖用户设置的服务器名称
+	if server.Name != "生产数据库" || server.Hostname != "db-01" {
+		t.Errorf("名称为 %q，主机名为 %q", server.Name, server.Hostname)
+	}
+	if server.OS != "Ubuntu 24.04" || server.Kernel != "6.8.0-45-generic" || server.AgentVersion != "1.4.2" {
+		t.Errorf("系统信息为 os=%q kernel=%q agent_version=%q", server.OS, server.Kernel, server.AgentVersion)
+	}
+	if server.BootTime == nil || !server.BootTime.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("启动时间为 %v", server.BootTime)
+	}
+	var uptime int64
+	if err := facades.Orm().Query().Table("servers").Where("id", serverID).Pluck("uptime_seconds", &uptime); err != nil || uptime != 3600 {
+		t.Errorf("uptime_seconds 为 %d (%v)，期望 3600", uptime, err)
+	}
+}
@@ -0,0 +1,536 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/goravel/framework/facades"
+)
+
+// 发布计划状态
+const (
+	RolloutStatusPending   = "pending"
+	RolloutStatusRunning   = "running"
+	RolloutStatusCompleted = "completed"
+	RolloutStatusHalted    = "halted"
+	RolloutStatusCancelled = "cancelled"
+)
+
+// 发布计划中服务器的更新状态
+const (
+	RolloutServerPending   = "pending"
+	RolloutServerUpdating  = "updating"
+	RolloutServerSucceeded = "succeeded"
+	RolloutServerFailed    = "failed"
+	RolloutServerSkipped   = "skipped"
+)
+
+// rolloutPollInterval 等待 Agent 重连时的检查间隔
+const rolloutPollInterval = 5 * time.Second
+
+// AgentRolloutOptions 创建发布计划的参数
+type AgentRolloutOptions struct {
+	Name         string
+	Version      string
+	VersionType  string
+	GroupID      *uint
+	ServerIDs    []string
+	CanaryCount  int
+	BatchSize    int
+	MaxFailures  int
+	BatchTimeout int // 秒
+}
+
+// AgentRolloutService Agent 分批发布服务
+type AgentRolloutService struct {
+	mu      sync.Mutex
+	running map[uint]*rolloutRun
+}
+
+// rolloutRun 执行中的发布计划，关闭 stop 通知执行协程退出，协程退出后关闭 done
+type rolloutRun struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+var (
+	agentRolloutService     *AgentRolloutService
+	agentRolloutServiceOnce sync.Once
+)
+
+// GetAgentRolloutService 获取 Agent 分批发布服务单例
+func GetAgentRolloutService() *AgentRolloutService {
+	agentRolloutServiceOnce.Do(func() {
+		agentRolloutService = &AgentRolloutService{
+			running: make(map[uint]*rolloutRun),
+		}
+	})
+	return agentRolloutService
+}
+
+// SendAgentUpdateCommand 向 Agent 发送更新命令
+func SendAgentUpdateCommand(serverID, version, versionType string) error {
+	message := map[string]interface{}{
+		"type":    "command",
+		"command": "update",
+		"data": map[string]interface{}{
+			"version":      version,
+			"version_type": versionType,
+		},
+	}
+	return GetWebSocketService().SendMessage(serverID, message)
+}
+
+// Create 创建发布计划并立即开始执行
+func (s *AgentRolloutService) Create(opts AgentRolloutOptions) (*models.AgentRollout, error) {
+	version := NormalizeAgentVersion(opts.Version)
+	if version == "" {
+		return nil, errors.New("缺少目标版本")
+	}
+
+	servers, err := s.resolveServers(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("没有可发布的服务器")
+	}
+
+	if opts.CanaryCount < 0 {
+		opts.CanaryCount = 0
+	}
+	if opts.CanaryCount > len(servers) {
+		opts.CanaryCount = len(servers)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5
+	}
+	if opts.MaxFailures < 0 {
+		opts.MaxFailures = 0
+	}
+	if opts.BatchTimeout <= 0 {
+		opts.BatchTimeout = 300
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("Agent %s 发布", version)
+	}
+	if opts.VersionType == "" {
+		_, opts.VersionType, _ = NewUpdateService().ParseVersion(version)
+	}
+
+	// 金丝雀批次为 0，其余服务器按批次大小依次分配到 1..N
+	rolloutServers := make([]*models.AgentRolloutServer, 0, len(servers))
+	batches := make(map[int]bool)
+	for i, server := range servers {
+		batchIndex := 0
+		if i >= opts.CanaryCount {
+			batchIndex = (i-opts.CanaryCount)/opts.BatchSize + 1
+		}
+		batches[batchIndex] = true
+
+		fromVersion := NormalizeAgentVersion(server.AgentVersion)
+		status := RolloutServerPending
+		if fromVersion == version {
+			status = RolloutServerSkipped
+		}
+		rolloutServers = append(rolloutServers, &models.AgentRolloutServer{
+			ServerID:    server.ID,
+			BatchIndex:  batchIndex,
+			Status:      status,
+			FromVersion: fromVersion,
+		})
+	}
+
+	rollout := &models.AgentRollout{
+		Name:          opts.Name,
+		TargetVersion: version,
+		VersionType:   opts.VersionType,
+		GroupID:       opts.GroupID,
+		CanaryCount:   opts.CanaryCount,
+		BatchSize:     opts.BatchSize,
+		MaxFailures:   opts.MaxFailures,
+		BatchTimeout:  opts.BatchTimeout,
+		Status:        RolloutStatusPending,
+		TotalBatches:  len(batches),
+		TotalServers:  len(rolloutServers),
+	}
+
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	if err := rolloutRepo.Create(rollout, rolloutServers); err != nil {
+		return nil, err
+	}
+
+	if err := s.Start(rollout.ID); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// resolveServers 根据分组或服务器ID列表获取目标服务器
+func (s *AgentRolloutService) resolveServers(opts AgentRolloutOptions) ([]*models.Server, error) {
+	serverRepo := repositories.GetServerRepository()
+	if opts.GroupID != nil {
+		return serverRepo.GetByGroupID(*opts.GroupID)
+	}
+
+	servers := make([]*models.Server, 0, len(opts.ServerIDs))
+	seen := make(map[string]bool)
+	for _, id := range opts.ServerIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		server, err := serverRepo.GetByID(id)
+		if err != nil || server == nil || server.ID == "" {
+			return nil, fmt.Errorf("服务器不存在: %s", id)
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// Start 开始或继续执行发布计划，继续已暂停的计划时会重试失败的服务器
+func (s *AgentRolloutService) Start(rolloutID uint) error {
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	rollout, err := rolloutRepo.GetByID(rolloutID)
+	if err != nil {
+		return err
+	}
+	if rollout == nil {
+		return errors.New("发布计划不存在")
+	}
+	if rollout.Status != RolloutStatusPending && rollout.Status != RolloutStatusHalted {
+		return fmt.Errorf("当前状态无法开始发布: %s", rollout.Status)
+	}
+
+	s.mu.Lock()
+	if _, ok := s.running[rolloutID]; ok {
+		s.mu.Unlock()
+		return errors.New("发布计划正在执行")
+	}
+	run := &rolloutRun{stop: make(chan struct{}), done: make(chan struct{})}
+	s.running[rolloutID] = run
+	s.mu.Unlock()
+
+	if rollout.Status == RolloutStatusHalted {
+		servers, err := rolloutRepo.GetServers(rolloutID)
+		if err != nil {
+			s.release(rolloutID, run)
+			return err
+		}
+		for _, server := range servers {
+			if server.Status == RolloutServerFailed {
+				server.Status = RolloutServerPending
+				server.Error = ""
+				if err := rolloutRepo.UpdateServer(server); err != nil {
+					s.release(rolloutID, run)
+					return err
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	rollout.Status = RolloutStatusRunning
+	rollout.Error = ""
+	rollout.FinishedAt = nil
+	if rollout.StartedAt == nil {
+		rollout.StartedAt = &now
+	}
+	if err := rolloutRepo.Update(rollout); err != nil {
+		s.release(rolloutID, run)
+		return err
+	}
+
+	go s.run(rolloutID, run)
+	return nil
+}
+
+// Cancel 取消发布计划，已发送的更新命令不会撤回
+func (s *AgentRolloutService) Cancel(rolloutID uint) error {
+	s.mu.Lock()
+	run, ok := s.running[rolloutID]
+	s.mu.Unlock()
+	if ok {
+		// 正在执行的计划由执行协程写入取消状态，等待协程退出后才能重新开始
+		run.stopOnce.Do(func() { close(run.stop) })
+		<-run.done
+		return nil
+	}
+
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	rollout, err := rolloutRepo.GetByID(rolloutID)
+	if err != nil {
+		return err
+	}
+	if rollout == nil {
+		return errors.New("发布计划不存在")
+	}
+	if rollout.Status == RolloutStatusCompleted || rollout.Status == RolloutStatusCancelled {
+		return fmt.Errorf("当前状态无法取消: %s", rollout.Status)
+	}
+
+	now := time.Now()
+	rollout.Status = RolloutStatusCancelled
+	rollout.FinishedAt = &now
+	if err := rolloutRepo.Update(rollout); err != nil {
+		return err
+	}
+	s.broadcast(rollout, nil)
+	return nil
+}
+
+// HaltInterrupted 将服务重启前未执行完的发布计划标记为暂停，等待管理员确认后继续
+func (s *AgentRolloutService) HaltInterrupted() {
+	if !facades.Schema().HasTable("agent_rollouts") {
+		return
+	}
+
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	rollouts, err := rolloutRepo.GetByStatus(RolloutStatusRunning)
+	if err != nil {
+		facades.Log().Errorf("获取执行中的发布计划失败: %v", err)
+		return
+	}
+
+	for _, rollout := range rollouts {
+		servers, err := rolloutRepo.GetServers(rollout.ID)
+		if err == nil {
+			for _, server := range servers {
+				if server.Status == RolloutServerUpdating {
+					server.Status = RolloutServerPending
+					_ = rolloutRepo.UpdateServer(server)
+				}
+			}
+		}
+
+		rollout.Status = RolloutStatusHalted
+		rollout.Error = "服务重启，发布已中断"
+		if err := rolloutRepo.Update(rollout); err != nil {
+			facades.Log().Errorf("暂停发布计划失败: rollout_id=%d, error=%v", rollout.ID, err)
+		}
+	}
+}
+
+// release 移除执行中的标记并通知等待的 Cancel
+func (s *AgentRolloutService) release(rolloutID uint, run *rolloutRun) {
+	s.mu.Lock()
+	delete(s.running, rolloutID)
+	s.mu.Unlock()
+	close(run.done)
+}
+
+// run 按批次执行发布计划
+func (s *AgentRolloutService) run(rolloutID uint, run *rolloutRun) {
+	defer s.release(rolloutID, run)
+	stop := run.stop
+
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	rollout, err := rolloutRepo.GetByID(rolloutID)
+	if err != nil || rollout == nil {
+		facades.Log().Errorf("加载发布计划失败: rollout_id=%d, error=%v", rolloutID, err)
+		return
+	}
+	servers, err := rolloutRepo.GetServers(rolloutID)
+	if err != nil {
+		s.finish(rollout, servers, RolloutStatusHalted, fmt.Sprintf("加载发布服务器失败: %v", err))
+		return
+	}
+
+	batches := make(map[int][]*models.AgentRolloutServer)
+	batchIndexes := make([]int, 0)
+	for _, server := range servers {
+		if _, ok := batches[server.BatchIndex]; !ok {
+			batchIndexes = append(batchIndexes, server.BatchIndex)
+		}
+		batches[server.BatchIndex] = append(batches[server.BatchIndex], server)
+	}
+	sort.Ints(batchIndexes)
+
+	s.countResults(rollout, servers)
+	s.broadcast(rollout, servers)
+
+	// 从仍有待更新服务器的最小批次继续，已完成的批次不再执行；
+	// 继续暂停的计划时失败的服务器已重置为待更新，所在批次即使早于 CurrentBatch 也会重新执行
+	for _, batchIndex := range batchIndexes {
+		if !hasPendingServers(batches[batchIndex]) {
+			continue
+		}
+		select {
+		case <-stop:
+			s.finish(rollout, servers, RolloutStatusCancelled, "")
+			return
+		default:
+		}
+		rollout.CurrentBatch = batchIndex
+		_ = rolloutRepo.Update(rollout)
+
+		if cancelled := s.runBatch(rollout, batches[batchIndex], servers, stop); cancelled {
+			s.finish(rollout, servers, RolloutStatusCancelled, "")
+			return
+		}
+
+		s.countResults(rollout, servers)
+		batchFailures := 0
+		for _, server := range batches[batchIndex] {
+			if server.Status == RolloutServerFailed {
+				batchFailures++
+			}
+		}
+
+		// 金丝雀批次出现失败或累计失败数超过阈值时自动暂停
+		if batchIndex == 0 && batchFailures > 0 {
+			s.finish(rollout, servers, RolloutStatusHalted, fmt.Sprintf("金丝雀批次有 %d 台服务器更新失败，发布已暂停", batchFailures))
+			return
+		}
+		if rollout.FailedCount > rollout.MaxFailures {
+			s.finish(rollout, servers, RolloutStatusHalted, fmt.Sprintf("失败数 %d 超过阈值 %d，发布已暂停", rollout.FailedCount, rollout.MaxFailures))
+			return
+		}
+
+		_ = rolloutRepo.Update(rollout)
+		s.broadcast(rollout, servers)
+	}
+
+	s.finish(rollout, servers, RolloutStatusCompleted, "")
+}
+
+// runBatch 向批次内的服务器发送更新命令并等待 Agent 以新版本重新连接，返回是否被取消
+func (s *AgentRolloutService) runBatch(rollout *models.AgentRollout, batch []*models.AgentRolloutServer, all []*models.AgentRolloutServer, stop chan struct{}) bool {
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+
+	waiting := make([]*models.AgentRolloutServer, 0, len(batch))
+	for _, server := range batch {
+		if server.Status != RolloutServerPending && server.Status != RolloutServerUpdating {
+			continue
+		}
+
+		now := time.Now()
+		server.StartedAt = &now
+		server.FinishedAt = nil
+		if err := SendAgentUpdateCommand(server.ServerID, rollout.TargetVersion, rollout.VersionType); err != nil {
+			server.Status = RolloutServerFailed
+			server.Error = fmt.Sprintf("发送更新命令失败: %v", err)
+			server.FinishedAt = &now
+		} else {
+			server.Status = RolloutServerUpdating
+			waiting = append(waiting, server)
+		}
+		_ = rolloutRepo.UpdateServer(server)
+	}
+	s.broadcast(rollout, all)
+
+	if len(waiting) == 0 {
+		return false
+	}
+
+	serverRepo := repositories.GetServerRepository()
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(time.Duration(rollout.BatchTimeout) * time.Second)
+
+	for len(waiting) > 0 {
+		select {
+		case <-stop:
+			return true
+		case <-deadline:
+			now := time.Now()
+			for _, server := range waiting {
+				server.Status = RolloutServerFailed
+				server.Error = "等待Agent以新版本重新连接超时"
+				server.FinishedAt = &now
+				_ = rolloutRepo.UpdateServer(server)
+			}
+			return false
+		case <-ticker.C:
+			remaining := waiting[:0]
+			changed := false
+			for _, server := range waiting {
+				record, err := serverRepo.GetByID(server.ServerID)
+				if err == nil && record != nil && record.Status == "online" && NormalizeAgentVersion(record.AgentVersion) == rollout.TargetVersion {
+					now := time.Now()
+					server.Status = RolloutServerSucceeded
+					server.FinishedAt = &now
+					_ = rolloutRepo.UpdateServer(server)
+					changed = true
+					continue
+				}
+				remaining = append(remaining, server)
+			}
+			waiting = remaining
+			if changed {
+				s.countResults(rollout, all)
+				s.broadcast(rollout, all)
+			}
+		}
+	}
+
+	return false
+}
+
+// hasPendingServers 批次中是否有待更新或更新中的服务器
+func hasPendingServers(batch []*models.AgentRolloutServer) bool {
+	for _, server := range batch {
+		if server.Status == RolloutServerPending || server.Status == RolloutServerUpdating {
+			return true
+		}
+	}
+	return false
+}
+
+// countResults 统计成功和失败的服务器数量
+func (s *AgentRolloutService) countResults(rollout *models.AgentRollout, servers []*models.AgentRolloutServer) {
+	rollout.SucceededCount = 0
+	rollout.FailedCount = 0
+	for _, server := range servers {
+		switch server.Status {
+		case RolloutServerSucceeded, RolloutServerSkipped:
+			rollout.SucceededCount++
+		case RolloutServerFailed:
+			rollout.FailedCount++
+		}
+	}
+}
+
+// finish 结束本次执行并写入最终状态
+func (s *AgentRolloutService) finish(rollout *models.AgentRollout, servers []*models.AgentRolloutServer, status, reason string) {
+	s.countResults(rollout, servers)
+	rollout.Status = status
+	rollout.Error = reason
+	if status != RolloutStatusHalted {
+		now := time.Now()
+		rollout.FinishedAt = &now
+	}
+
+	if err := repositories.GetAgentRolloutRepository().Update(rollout); err != nil {
+		facades.Log().Errorf("更新发布计划状态失败: rollout_id=%d, error=%v", rollout.ID, err)
+	}
+	if status == RolloutStatusHalted {
+		facades.Log().Warningf("Agent发布计划已暂停: rollout_id=%d, reason=%s", rollout.ID, reason)
+	} else {
+		facades.Log().Infof("Agent发布计划已结束: rollout_id=%d, status=%s", rollout.ID, status)
+	}
+	s.broadcast(rollout, servers)
+}
+
+// broadcast 向前端推送发布进度
+func (s *AgentRolloutService) broadcast(rollout *models.AgentRollout, servers []*models.AgentRolloutServer) {
+	data := map[string]interface{}{
+		"rollout": rollout,
+	}
+	if servers != nil {
+		data["servers"] = servers
+	}
+	GetWebSocketService().BroadcastToFrontend(map[string]interface{}{
+		"type": "agent_rollout_update",
+		"data": data,
+	})
+}
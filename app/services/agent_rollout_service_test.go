@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+// waitRolloutStopped 等待发布计划执行结束或暂停
+func waitRolloutStopped(t *testing.T, rolloutID uint) *models.AgentRollout {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		rollout, err := repositories.GetAgentRolloutRepository().GetByID(rolloutID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rollout.Status != services.RolloutStatusRunning {
+			return rollout
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("发布计划未在 5 秒内结束")
+	return nil
+}
+
+func TestStartHaltedRolloutRetriesEarlierBatches(t *testing.T) {
+	tests.NewDatabase(t)
+
+	// 计划在第 2 批暂停，第 1 批有一台服务器更新失败
+	statuses := []string{services.RolloutServerSucceeded, services.RolloutServerFailed, services.RolloutServerPending}
+	rolloutServers := make([]*models.AgentRolloutServer, 0, len(statuses))
+	for i, status := range statuses {
+		serverID := fmt.Sprintf("rollout-server-%d", i)
+		if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: serverID, IP: "10.0.1.1", AgentKey: serverID, Status: "online", AgentVersion: "1.0.0"}); err != nil {
+			t.Fatalf("创建服务器失败: %v", err)
+		}
+		rolloutServers = append(rolloutServers, &models.AgentRolloutServer{ServerID: serverID, BatchIndex: i, Status: status, FromVersion: "1.0.0"})
+	}
+	rollout := &models.AgentRollout{
+		Name:          "resume",
+		TargetVersion: "1.1.0",
+		VersionType:   "release",
+		CanaryCount:   1,
+		BatchSize:     1,
+		MaxFailures:   5,
+		BatchTimeout:  1,
+		Status:        services.RolloutStatusHalted,
+		CurrentBatch:  2,
+		TotalBatches:  3,
+		TotalServers:  3,
+	}
+	rolloutRepo := repositories.GetAgentRolloutRepository()
+	if err := rolloutRepo.Create(rollout, rolloutServers); err != nil {
+		t.Fatalf("创建发布计划失败: %v", err)
+	}
+
+	if err := services.GetAgentRolloutService().Start(rollout.ID); err != nil {
+		t.Fatalf("继续发布失败: %v", err)
+	}
+	rollout = waitRolloutStopped(t, rollout.ID)
+
+	servers, err := rolloutRepo.GetServers(rollout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 测试中没有 Agent 连接，发送更新命令失败；第 1 批的服务器虽早于 CurrentBatch，也应重新发送
+	for _, server := range servers {
+		if server.BatchIndex == 0 {
+			if server.Status != services.RolloutServerSucceeded {
+				t.Errorf("已成功的服务器状态变为 %s", server.Status)
+			}
+			continue
+		}
+		if server.Status != services.RolloutServerFailed || !strings.Contains(server.Error, "发送更新命令失败") {
+			t.Errorf("第 %d 批的服务器为 %s: %q，期望重新发送更新命令", server.BatchIndex, server.Status, server.Error)
+		}
+	}
+	if rollout.Status != services.RolloutStatusCompleted || rollout.FailedCount != 2 {
+		t.Errorf("发布计划为 %s，失败 %d 台", rollout.Status, rollout.FailedCount)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Agent 版本状态
+const (
+	AgentVersionUpToDate    = "up_to_date"
+	AgentVersionOutdated    = "outdated"
+	AgentVersionUnsupported = "unsupported"
+	AgentVersionUnknown     = "unknown"
+)
+
+// agentLatestVersionCacheKey 最新 Agent 版本缓存键
+const agentLatestVersionCacheKey = "agent_latest_version"
+
+// AgentVersionServer 版本报告中的服务器信息
+type AgentVersionServer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	IP     string `json:"ip"`
+	Status string `json:"status"`
+}
+
+// AgentVersionGroup 同一 Agent 版本的服务器分组
+type AgentVersionGroup struct {
+	Version     string                `json:"version"`
+	Status      string                `json:"status"`
+	ServerCount int                   `json:"server_count"`
+	Servers     []*AgentVersionServer `json:"servers"`
+}
+
+// AgentVersionReport 全部服务器的 Agent 版本报告
+type AgentVersionReport struct {
+	LatestVersion       string               `json:"latest_version"`
+	MinSupportedVersion string               `json:"min_supported_version"`
+	Total               int                  `json:"total"`
+	UpToDate            int                  `json:"up_to_date"`
+	Outdated            int                  `json:"outdated"`
+	Unsupported         int                  `json:"unsupported"`
+	Unknown             int                  `json:"unknown"`
+	Versions            []*AgentVersionGroup `json:"versions"`
+}
+
+// NormalizeAgentVersion 统一版本号格式（去除空白和 v 前缀）
+func NormalizeAgentVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if len(version) > 0 && (version[0] == 'v' || version[0] == 'V') {
+		version = version[1:]
+	}
+	return version
+}
+
+// ExtractAgentVersion 从 Agent 上报数据中提取版本号
+func ExtractAgentVersion(data map[string]interface{}) string {
+	for _, key := range []string{"agent_version", "version"} {
+		if version, ok := data[key].(string); ok {
+			if normalized := NormalizeAgentVersion(version); normalized != "" {
+				return normalized
+			}
+		}
+	}
+	return ""
+}
+
+// UpdateAgentVersion 更新服务器记录的 Agent 版本，版本未变化时不写库
+func UpdateAgentVersion(serverID, version string) error {
+	version = NormalizeAgentVersion(version)
+	if serverID == "" || version == "" {
+		return nil
+	}
+
+	cacheKey := "agent_version:" + serverID
+	if cached, ok := facades.Cache().Get(cacheKey).(string); ok && cached == version {
+		return nil
+	}
+
+	_, err := facades.Orm().Query().Model(&models.Server{}).Where("id = ?", serverID).Update("agent_version", version)
+	if err != nil {
+		return err
+	}
+	return facades.Cache().Put(cacheKey, version, 24*time.Hour)
+}
+
+// GetLatestAgentVersion 获取最新发布的 Agent 版本，结果缓存一小时，获取失败时返回空字符串
+func GetLatestAgentVersion(releaseUrl string) string {
+	if cached, ok := facades.Cache().Get(agentLatestVersionCacheKey).(string); ok && cached != "" {
+		return cached
+	}
+
+	releaseInfo, err := NewUpdateService().FetchLatestRelease(releaseUrl)
+	if err != nil {
+		facades.Log().Warningf("获取最新Agent版本失败: %v", err)
+		return ""
+	}
+
+	_ = facades.Cache().Put(agentLatestVersionCacheKey, releaseInfo.NormalizedTagName, time.Hour)
+	return releaseInfo.NormalizedTagName
+}
+
+// GetMinSupportedAgentVersion 获取最低支持的 Agent 版本，未配置时返回空字符串
+func GetMinSupportedAgentVersion() string {
+	settingRepo := repositories.GetSystemSettingRepository()
+	return NormalizeAgentVersion(settingRepo.GetValue("agent_min_supported_version", ""))
+}
+
+// GetAgentVersionStatus 判断 Agent 版本状态
+// 低于最低支持版本为 unsupported，低于最新版本为 outdated，无法获取最新版本时为 unknown
+func GetAgentVersionStatus(version, latestVersion, minSupportedVersion string) string {
+	if version == "" {
+		return AgentVersionUnknown
+	}
+
+	updateService := NewUpdateService()
+	if minSupportedVersion != "" && updateService.CompareVersions(version, minSupportedVersion) {
+		return AgentVersionUnsupported
+	}
+	if latestVersion == "" {
+		return AgentVersionUnknown
+	}
+	if updateService.CompareVersions(version, latestVersion) {
+		return AgentVersionOutdated
+	}
+	return AgentVersionUpToDate
+}
+
+// BuildAgentVersionReport 按 Agent 版本对服务器分组并统计各状态数量
+func BuildAgentVersionReport(servers []*models.Server, latestVersion, minSupportedVersion string) *AgentVersionReport {
+	report := &AgentVersionReport{
+		LatestVersion:       latestVersion,
+		MinSupportedVersion: minSupportedVersion,
+		Total:               len(servers),
+		Versions:            []*AgentVersionGroup{},
+	}
+
+	groups := make(map[string]*AgentVersionGroup)
+	for _, server := range servers {
+		version := NormalizeAgentVersion(server.AgentVersion)
+		group, ok := groups[version]
+		if !ok {
+			group = &AgentVersionGroup{
+				Version: version,
+				Status:  GetAgentVersionStatus(version, latestVersion, minSupportedVersion),
+				Servers: []*AgentVersionServer{},
+			}
+			groups[version] = group
+			report.Versions = append(report.Versions, group)
+		}
+
+		group.ServerCount++
+		group.Servers = append(group.Servers, &AgentVersionServer{
+			ID:     server.ID,
+			Name:   server.Name,
+			IP:     server.IP,
+			Status: server.Status,
+		})
+
+		switch group.Status {
+		case AgentVersionUpToDate:
+			report.UpToDate++
+		case AgentVersionOutdated:
+			report.Outdated++
+		case AgentVersionUnsupported:
+			report.Unsupported++
+		default:
+			report.Unknown++
+		}
+	}
+
+	// 新版本在前，未知版本排在最后
+	updateService := NewUpdateService()
+	sort.SliceStable(report.Versions, func(i, j int) bool {
+		a, b := report.Versions[i].Version, report.Versions[j].Version
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		return updateService.CompareVersions(b, a)
+	})
+
+	return report
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/expression"
+)
+
+const (
+	// backtestMaxRange 单次回测允许的最大时间范围
+	backtestMaxRange = 7 * 24 * time.Hour
+	// backtestMaxServers 单次回测允许的最大服务器数量
+	backtestMaxServers = 50
+)
+
+// backtestMetricFields 支持回测的指标及其在 server_metrics 中的取值
+var backtestMetricFields = map[string]func(metric *models.ServerMetric) float64{
+	"cpu":    func(metric *models.ServerMetric) float64 { return metric.CPUUsage },
+	"memory": func(metric *models.ServerMetric) float64 { return metric.MemoryUsage },
+	"disk":   func(metric *models.ServerMetric) float64 { return metric.DiskUsage },
+}
+
+// BacktestOptions 规则回测参数
+type BacktestOptions struct {
+	Metric    string // cpu、memory、disk
+	Rule      Rule
+	ServerIDs []string
+	Start     time.Time
+	End       time.Time
+}
+
+// validate 校验回测参数
+func (o *BacktestOptions) validate() error {
+	if _, ok := backtestMetricFields[o.Metric]; !ok {
+		return errors.New("回测只支持 cpu、memory、disk 指标")
+	}
+	if o.Rule.Warning <= 0 || o.Rule.Critical <= 0 {
+		return errors.New("警告阈值和严重阈值必须大于0")
+	}
+	if o.Rule.Critical < o.Rule.Warning {
+		return errors.New("严重阈值不能低于警告阈值")
+	}
+	if err := o.Rule.Validate(); err != nil {
+		return err
+	}
+	if len(o.ServerIDs) == 0 {
+		return errors.New("至少需要选择一台服务器")
+	}
+	if len(o.ServerIDs) > backtestMaxServers {
+		return fmt.Errorf("单次最多回测 %d 台服务器", backtestMaxServers)
+	}
+	if !o.End.After(o.Start) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	if o.End.Sub(o.Start) > backtestMaxRange {
+		return errors.New("回测时间范围不能超过 7 天")
+	}
+	return nil
+}
+
+// BacktestEvent 回测中规则的一次状态变化，即实际运行时会发送的告警或恢复通知
+type BacktestEvent struct {
+	ServerID  string     `json:"server_id"`
+	Type      string     `json:"type"` // firing：进入告警，recovery：恢复，severity_change：告警级别变化
+	From      AlertState `json:"from"`
+	To        AlertState `json:"to"`
+	Value     float64    `json:"value"`
+	Threshold float64    `json:"threshold"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// BacktestServerSummary 单台服务器的回测汇总
+type BacktestServerSummary struct {
+	ServerID      string `json:"server_id"`
+	ServerName    string `json:"server_name"`
+	Samples       int    `json:"samples"`
+	FireCount     int    `json:"fire_count"`
+	RecoveryCount int    `json:"recovery_count"`
+	FiringSeconds int64  `json:"firing_seconds"` // 处于告警状态的总时长
+}
+
+// BacktestResult 规则回测结果
+type BacktestResult struct {
+	Events        []BacktestEvent         `json:"events"`
+	Servers       []BacktestServerSummary `json:"servers"`
+	FireCount     int                     `json:"fire_count"`
+	RecoveryCount int                     `json:"recovery_count"`
+}
+
+// BacktestRule 用历史指标回放规则，返回规则在该时间段内会产生的告警和恢复事件，不记录告警也不发送通知
+func (s *AlertService) BacktestRule(opts BacktestOptions) (*BacktestResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	serverRepo := repositories.GetServerRepository()
+	metricRepo := repositories.GetServerMetricRepository()
+	field := backtestMetricFields[opts.Metric]
+
+	result := &BacktestResult{
+		Events:  make([]BacktestEvent, 0),
+		Servers: make([]BacktestServerSummary, 0, len(opts.ServerIDs)),
+	}
+	for _, serverID := range opts.ServerIDs {
+		server, err := serverRepo.GetByID(serverID)
+		if err != nil || server == nil {
+			return nil, fmt.Errorf("服务器 %s 不存在", serverID)
+		}
+
+		history, err := metricRepo.GetHistory(serverID, opts.Start, opts.End)
+		if err != nil {
+			return nil, err
+		}
+		points := make([]repositories.MetricPoint, 0, len(history))
+		for _, metric := range history {
+			points = append(points, repositories.MetricPoint{Timestamp: metric.Timestamp.Unix(), Value: field(metric)})
+		}
+
+		events, summary := ReplayRule(opts.Rule, serverID, points, opts.End)
+		summary.ServerName = server.Name
+		result.Events = append(result.Events, events...)
+		result.Servers = append(result.Servers, summary)
+		result.FireCount += summary.FireCount
+		result.RecoveryCount += summary.RecoveryCount
+	}
+
+	return result, nil
+}
+
+// ReplayRule 按时间顺序将指标序列交给规则评估，状态变化规则与 evaluateRule 相同
+// 回放从正常状态开始，结束时仍在告警的时长计算到 end
+func ReplayRule(rule Rule, serverID string, points []repositories.MetricPoint, end time.Time) ([]BacktestEvent, BacktestServerSummary) {
+	forDuration, _ := expression.ParseDuration(rule.For)
+	summary := BacktestServerSummary{ServerID: serverID, Samples: len(points)}
+	events := make([]BacktestEvent, 0)
+
+	state := AlertStateNormal
+	var pendingSince, firingSince int64
+	for _, point := range points {
+		at := time.Unix(point.Timestamp, 0)
+		newState, ok := rule.nextState(point.Value, state)
+		if !ok {
+			continue
+		}
+		newState, pendingSince = pendingState(state, newState, pendingSince, forDuration, at)
+		if newState == state {
+			continue
+		}
+
+		event := BacktestEvent{
+			ServerID:  serverID,
+			From:      state,
+			To:        newState,
+			Value:     point.Value,
+			Threshold: rule.Critical,
+			Timestamp: at,
+		}
+		switch {
+		case newState == AlertStateNormal:
+			event.Type = "recovery"
+			summary.RecoveryCount++
+			summary.FiringSeconds += point.Timestamp - firingSince
+		case state == AlertStateNormal:
+			event.Type = "firing"
+			summary.FireCount++
+			firingSince = point.Timestamp
+		default:
+			event.Type = "severity_change"
+		}
+		// 告警事件记录触发的阈值，恢复事件记录恢复阈值
+		switch {
+		case newState == AlertStateWarning:
+			event.Threshold = rule.Warning
+		case newState == AlertStateNormal && rule.Recovery != nil:
+			event.Threshold = *rule.Recovery
+		case newState == AlertStateNormal:
+			event.Threshold = rule.Warning
+		}
+		events = append(events, event)
+		state = newState
+	}
+
+	if state != AlertStateNormal {
+		summary.FiringSeconds += end.Unix() - firingSince
+	}
+	return events, summary
+}
@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestBacktestRule(t *testing.T) {
+	tests.NewDatabase(t)
+
+	serverID := "backtest-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "web-1", IP: "10.0.0.9", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+
+	// 指标的 timestamp 有的以时间字符串保存，有的以整数时间戳保存，回测需按时间顺序合并
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	samples := []struct {
+		offset  time.Duration
+		cpu     float64
+		integer bool
+	}{
+		{-10 * time.Minute, 99, true}, // 早于回测范围，不参与回放
+		{0, 50, false},
+		{10 * time.Minute, 95, true},
+		{20 * time.Minute, 96, false},
+		{30 * time.Minute, 40, true},
+	}
+	for _, sample := range samples {
+		timestamp := start.Add(sample.offset)
+		if sample.integer {
+			if _, err := facades.Orm().Query().Exec(
+				"INSERT INTO server_metrics (server_id, cpu_usage, memory_usage, disk_usage, timestamp, created_at, updated_at) VALUES (?, ?, 0, 0, ?, ?, ?)",
+				serverID, sample.cpu, timestamp.Unix(), timestamp, timestamp,
+			); err != nil {
+				t.Fatalf("写入指标失败: %v", err)
+			}
+			continue
+		}
+		if err := facades.Orm().Query().Create(&models.ServerMetric{ServerID: serverID, CPUUsage: sample.cpu, Timestamp: timestamp}); err != nil {
+			t.Fatalf("写入指标失败: %v", err)
+		}
+	}
+
+	result, err := services.NewAlertService().BacktestRule(services.BacktestOptions{
+		Metric:    "cpu",
+		Rule:      services.Rule{Enabled: true, Warning: 80, Critical: 90},
+		ServerIDs: []string{serverID},
+		Start:     start,
+		End:       start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("回测失败: %v", err)
+	}
+
+	if len(result.Servers) != 1 || result.Servers[0].Samples != 4 {
+		t.Fatalf("回测汇总为 %+v，期望 4 个样本", result.Servers)
+	}
+	if result.FireCount != 1 || result.RecoveryCount != 1 {
+		t.Errorf("触发 %d 次、恢复 %d 次，期望各 1 次", result.FireCount, result.RecoveryCount)
+	}
+	wantEvents := []struct {
+		eventType string
+		at        time.Time
+	}{
+		{"firing", start.Add(10 * time.Minute)},
+		{"recovery", start.Add(30 * time.Minute)},
+	}
+	if len(result.Events) != len(wantEvents) {
+		t.Fatalf("回测事件为 %+v，期望 %d 个", result.Events, len(wantEvents))
+	}
+	for i, want := range wantEvents {
+		got := result.Events[i]
+		if got.Type != want.eventType || !got.Timestamp.Equal(want.at) {
+			t.Errorf("第 %d 个事件为 %s@%s，期望 %s@%s", i+1, got.Type, got.Timestamp, want.eventType, want.at)
+		}
+	}
+}
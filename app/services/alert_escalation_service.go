@@ -0,0 +1,362 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goravel/app/jobs"
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils"
+	"goravel/app/utils/notifytemplate"
+
+	"github.com/goravel/framework/facades"
+)
+
+// EscalationStep 升级策略中的一个步骤，告警开始后持续未确认达到 Delay 分钟时执行
+type EscalationStep struct {
+	Delay      int      `json:"delay"`                 // 告警开始后的分钟数，0 表示立即通知
+	Channels   []string `json:"channels"`              // 通知渠道类型：email、webhook、telegram、ntfy、gotify、bark，发送到该类型的所有默认渠道
+	ChannelIDs []uint   `json:"channel_ids,omitempty"` // 指定的通知渠道，按渠道自身的配置发送
+	EmailTo    string   `json:"email_to,omitempty"`    // 邮件收件人，设置后只通过第一个邮件渠道发送给该收件人
+	Webhook    string   `json:"webhook,omitempty"`     // Webhook 地址，与 Platform 任一设置后只通过第一个 Webhook 渠道发送
+	Platform   string   `json:"platform,omitempty"`    // Webhook 平台，为空时使用渠道的配置
+}
+
+// EscalationPolicyOptions 创建或更新升级策略的参数
+type EscalationPolicyOptions struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Steps       []EscalationStep `json:"steps"`
+}
+
+// validate 校验升级策略参数，并按延迟时间排序步骤
+func (o *EscalationPolicyOptions) validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("策略名称不能为空")
+	}
+	if len(o.Steps) == 0 {
+		return errors.New("至少需要一个升级步骤")
+	}
+	for i, step := range o.Steps {
+		if step.Delay < 0 {
+			return fmt.Errorf("第 %d 步的延迟时间不能为负数", i+1)
+		}
+		if len(step.Channels) == 0 && len(step.ChannelIDs) == 0 {
+			return fmt.Errorf("第 %d 步至少需要一个通知渠道", i+1)
+		}
+		for _, channel := range step.Channels {
+			if !slices.Contains(notificationChannelTypes, channel) {
+				return fmt.Errorf("第 %d 步的通知渠道 %s 不支持", i+1, channel)
+			}
+		}
+		for _, channelID := range step.ChannelIDs {
+			channel, err := repositories.GetAlertNotificationRepository().GetByID(channelID)
+			if err != nil {
+				return err
+			}
+			if channel == nil {
+				return fmt.Errorf("第 %d 步的通知渠道 %d 不存在", i+1, channelID)
+			}
+		}
+	}
+	sort.SliceStable(o.Steps, func(i, j int) bool {
+		return o.Steps[i].Delay < o.Steps[j].Delay
+	})
+	return nil
+}
+
+// CreateEscalationPolicy 创建升级策略
+func (s *AlertService) CreateEscalationPolicy(opts EscalationPolicyOptions) (*models.AlertEscalationPolicy, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	stepsJson, err := json.Marshal(opts.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &models.AlertEscalationPolicy{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Steps:       string(stepsJson),
+	}
+	if err := repositories.GetAlertEscalationPolicyRepository().Create(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdateEscalationPolicy 更新升级策略，进行中的告警从已执行的步骤继续升级
+func (s *AlertService) UpdateEscalationPolicy(id uint, opts EscalationPolicyOptions) (*models.AlertEscalationPolicy, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	policyRepo := repositories.GetAlertEscalationPolicyRepository()
+	policy, err := policyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, errors.New("升级策略不存在")
+	}
+
+	stepsJson, err := json.Marshal(opts.Steps)
+	if err != nil {
+		return nil, err
+	}
+	policy.Name = opts.Name
+	policy.Description = opts.Description
+	policy.Steps = string(stepsJson)
+	if err := policyRepo.Update(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// getEscalationSteps 获取升级策略的步骤，策略不存在时返回 nil
+func (s *AlertService) getEscalationSteps(policyID uint) ([]EscalationStep, error) {
+	policy, err := repositories.GetAlertEscalationPolicyRepository().GetByID(policyID)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	var steps []EscalationStep
+	if err := json.Unmarshal([]byte(policy.Steps), &steps); err != nil {
+		return nil, fmt.Errorf("解析升级策略 %d 失败: %v", policyID, err)
+	}
+	return steps, nil
+}
+
+// resolveEscalationPolicy 获取告警使用的升级策略：优先使用规则配置的策略，其次使用服务器所在分组的策略
+func (s *AlertService) resolveEscalationPolicy(serverID string, rulePolicyID *uint) *uint {
+	if rulePolicyID != nil {
+		return rulePolicyID
+	}
+
+	server, err := repositories.GetServerRepository().GetByID(serverID)
+	if err != nil || server == nil || server.GroupID == nil {
+		return nil
+	}
+	group, err := repositories.GetServerGroupRepository().GetByID(*server.GroupID)
+	if err != nil || group == nil {
+		return nil
+	}
+	return group.EscalationPolicyID
+}
+
+// deliver 发送告警或恢复通知，告警配置了升级策略时按策略步骤通知，否则按服务器通知配置合并发送
+func (s *AlertService) deliver(event *alertEvent, msg notificationMessage) {
+	alert := event.Alert
+	if alert == nil && !event.IsRecovery {
+		alert, _ = repositories.GetAlertRepository().GetFiring(event.ServerID, event.RuleKey)
+	}
+	if alert == nil || alert.EscalationPolicyID == nil {
+		s.groupNotifications(event, msg)
+		return
+	}
+
+	steps, err := s.getEscalationSteps(*alert.EscalationPolicyID)
+	if err != nil || len(steps) == 0 {
+		// 策略已删除或无效时退回服务器通知配置
+		if err != nil {
+			facades.Log().Warningf("获取升级策略失败: %v", err)
+		}
+		s.groupNotifications(event, msg)
+		return
+	}
+
+	// 持续告警期间不再按冷却期重复通知，由升级策略的后续步骤接管
+	if event.Alert == nil {
+		return
+	}
+
+	// 恢复、升级或降级时通知已执行步骤的渠道
+	for _, step := range steps[:min(alert.EscalationStep, len(steps))] {
+		s.dispatchEscalationStep(step, msg)
+	}
+	if !event.IsRecovery && alert.AcknowledgedAt == nil {
+		s.advanceEscalation(alert, steps, msg, time.Now())
+	}
+}
+
+// advanceEscalation 执行告警已到期但尚未执行的升级步骤，并记录执行进度
+func (s *AlertService) advanceEscalation(alert *models.Alert, steps []EscalationStep, msg notificationMessage, now time.Time) {
+	elapsed := now.Sub(alert.Timestamp)
+	next := alert.EscalationStep
+	for next < len(steps) && elapsed >= time.Duration(steps[next].Delay)*time.Minute {
+		s.dispatchEscalationStep(steps[next], msg)
+		next++
+	}
+	if next == alert.EscalationStep {
+		return
+	}
+
+	alert.EscalationStep = next
+	if err := repositories.GetAlertRepository().Update(alert); err != nil {
+		facades.Log().Errorf("更新告警升级进度失败: %v", err)
+		return
+	}
+	broadcastAlert(alert)
+}
+
+// dispatchEscalationStep 按升级步骤分发通知，步骤中的收件人和 Webhook 地址覆盖渠道配置
+func (s *AlertService) dispatchEscalationStep(step EscalationStep, msg notificationMessage) {
+	channels, err := repositories.GetAlertNotificationRepository().GetEnabled()
+	if err != nil {
+		facades.Log().Warningf("获取通知渠道失败: %v", err)
+		return
+	}
+
+	var targets []*jobs.SendAlertJob
+	for _, channelType := range step.Channels {
+		override := escalationOverride(channelType, step)
+		for _, channel := range channels {
+			if channel.NotificationType != channelType {
+				continue
+			}
+			if len(override) > 0 {
+				// 覆盖收件人或地址时只需要一个渠道的发送配置
+				job := notificationJob(channel, msg)
+				job.Override = override
+				job.ApplyChannelConfig(channel)
+				targets = append(targets, job)
+				break
+			}
+			if channel.IsDefault {
+				targets = append(targets, notificationJob(channel, msg))
+			}
+		}
+	}
+	for _, channel := range channels {
+		if slices.Contains(step.ChannelIDs, channel.ID) {
+			targets = append(targets, notificationJob(channel, msg))
+		}
+	}
+
+	for _, job := range targets {
+		if err := facades.Queue().Job(job).Dispatch(); err != nil {
+			facades.Log().Errorf("分发升级通知任务失败: %v", err)
+		}
+	}
+}
+
+// escalationOverride 升级步骤中覆盖渠道配置的收件人、Webhook 地址和平台，不覆盖时返回空
+func escalationOverride(channelType string, step EscalationStep) map[string]string {
+	override := make(map[string]string)
+	switch channelType {
+	case "email":
+		if step.EmailTo != "" {
+			override["to"] = step.EmailTo
+		}
+	case "webhook":
+		if step.Webhook != "" {
+			override["webhook"] = step.Webhook
+		}
+		if step.Platform != "" {
+			override["platform"] = step.Platform
+		}
+	}
+	return override
+}
+
+// ProcessEscalations 检查所有未确认的告警，执行到期的升级步骤
+func (s *AlertService) ProcessEscalations(now time.Time) {
+	alerts, err := repositories.GetAlertRepository().GetEscalating()
+	if err != nil {
+		facades.Log().Errorf("获取待升级告警失败: %v", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		steps, err := s.getEscalationSteps(*alert.EscalationPolicyID)
+		if err != nil || alert.EscalationStep >= len(steps) {
+			continue
+		}
+		if now.Sub(alert.Timestamp) < time.Duration(steps[alert.EscalationStep].Delay)*time.Minute {
+			continue
+		}
+
+		// 离线告警遵循离线通知开关，静默期内暂停升级
+		if alert.RuleKey == serverOfflineRuleKey && !utils.GetSettingBool("alert_server_offline_enabled", false) {
+			continue
+		}
+		var labels map[string]string
+		if alert.Labels != "" {
+			_ = json.Unmarshal([]byte(alert.Labels), &labels)
+		}
+		if s.isSilenced(alert.ServerID, alert.RuleKey, labels) {
+			continue
+		}
+
+		s.advanceEscalation(alert, steps, s.escalationMessage(alert, now), now)
+	}
+}
+
+// escalationMessage 使用升级提醒的通知模板构建标题和内容
+func (s *AlertService) escalationMessage(alert *models.Alert, now time.Time) notificationMessage {
+	severity := "警告"
+	if alert.Severity == string(AlertStateCritical) {
+		severity = "严重"
+	}
+
+	data := s.notificationData(alert.ServerID)
+	data.Timestamp = alert.Timestamp.Format("2006-01-02 15:04:05")
+	data.MetricLabel = alert.Title
+	data.Severity = severity
+	data.StatusText = severity
+	data.Color = severityColor(severity, false)
+	data.Detail = alert.Message
+	data.Extra["Elapsed"] = strconv.Itoa(int(now.Sub(alert.Timestamp).Minutes()))
+	if alert.MetricValue != nil {
+		data.CurrentValue = *alert.MetricValue
+	}
+	if alert.Threshold != nil {
+		data.Threshold = *alert.Threshold
+	}
+	return s.renderNotification(notifytemplate.EventEscalation, data)
+}
+
+// AlertEscalationService 告警升级服务，定期执行到期的升级步骤
+type AlertEscalationService struct {
+	stopChan chan struct{}
+}
+
+// NewAlertEscalationService 创建告警升级服务实例
+func NewAlertEscalationService() *AlertEscalationService {
+	return &AlertEscalationService{
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start 启动告警升级服务
+func (s *AlertEscalationService) Start() {
+	facades.Log().Info("告警升级服务已启动")
+
+	// 每30秒检查一次
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	alertService := NewAlertService()
+	for {
+		select {
+		case now := <-ticker.C:
+			alertService.ProcessEscalations(now)
+		case <-s.stopChan:
+			facades.Log().Info("告警升级服务已停止")
+			return
+		}
+	}
+}
+
+// Stop 停止告警升级服务
+func (s *AlertEscalationService) Stop() {
+	close(s.stopChan)
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"goravel/app/jobs"
+	"goravel/app/repositories"
+	"goravel/app/utils/notifytemplate"
+
+	"github.com/goravel/framework/facades"
+)
+
+// alertGroupMaxWindow 告警合并窗口上限
+const alertGroupMaxWindow = 10 * time.Minute
+
+// groupedNotification 等待合并发送的单条通知
+type groupedNotification struct {
+	ServerName string
+	ServerIP   string
+	Summary    string               // 单行摘要，用于汇总通知
+	Subject    string               // 单独发送时的标题
+	Content    string               // 单独发送时的内容
+	HTML       string               // 单独发送时的邮件 HTML 正文
+	Color      string               // 单独发送时的状态颜色
+	Link       string               // 单独发送时的服务器详情页链接
+	Event      string               // 单独发送时的事件类型
+	Data       *notifytemplate.Data // 单独发送时的事件数据
+	At         time.Time
+}
+
+// notificationGroup 同一渠道、规则类型和服务器分组的待发送通知
+type notificationGroup struct {
+	ChannelID  uint
+	Channel    string
+	Config     string
+	GroupName  string
+	RuleLabel  string
+	IsRecovery bool
+	Items      []groupedNotification
+}
+
+// AlertGrouper 告警合并器：在合并窗口内缓存通知，按渠道、规则类型和服务器分组合并为一条汇总通知发送
+type AlertGrouper struct {
+	mu     sync.Mutex
+	groups map[string]*notificationGroup
+}
+
+var (
+	globalAlertGrouper *AlertGrouper
+	alertGrouperOnce   sync.Once
+)
+
+// GetAlertGrouper 获取全局告警合并器（单例）
+func GetAlertGrouper() *AlertGrouper {
+	alertGrouperOnce.Do(func() {
+		globalAlertGrouper = &AlertGrouper{
+			groups: make(map[string]*notificationGroup),
+		}
+	})
+	return globalAlertGrouper
+}
+
+// alertGroupWindow 获取告警合并窗口，为 0 时不合并
+func alertGroupWindow() time.Duration {
+	seconds := repositories.GetSystemSettingRepository().GetInt("alert_group_window", 0)
+	window := time.Duration(seconds) * time.Second
+	if window < 0 {
+		return 0
+	}
+	return min(window, alertGroupMaxWindow)
+}
+
+// Add 加入待发送通知，窗口内第一条通知到达时开始计时，窗口结束后统一发送
+func (g *AlertGrouper) Add(key string, group notificationGroup, item groupedNotification, window time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	pending, ok := g.groups[key]
+	if !ok {
+		pending = &group
+		g.groups[key] = pending
+		time.AfterFunc(window, func() {
+			g.flush(key)
+		})
+	}
+	pending.Items = append(pending.Items, item)
+}
+
+// Flush 立即发送所有合并窗口内的通知，服务关闭时调用，避免缓存的通知丢失
+func (g *AlertGrouper) Flush() {
+	g.mu.Lock()
+	keys := make([]string, 0, len(g.groups))
+	for key := range g.groups {
+		keys = append(keys, key)
+	}
+	g.mu.Unlock()
+
+	for _, key := range keys {
+		g.flush(key)
+	}
+}
+
+// flush 发送合并窗口内的通知：只有一条时按原通知发送，多条时发送汇总通知
+func (g *AlertGrouper) flush(key string) {
+	g.mu.Lock()
+	pending, ok := g.groups[key]
+	delete(g.groups, key)
+	g.mu.Unlock()
+	if !ok || len(pending.Items) == 0 {
+		return
+	}
+
+	item := pending.Items[0]
+	job := &jobs.SendAlertJob{
+		ChannelID: pending.ChannelID,
+		Channel:   pending.Channel,
+		Config:    pending.Config,
+		Subject:   item.Subject,
+		Content:   item.Content,
+		HTML:      item.HTML,
+		Color:     item.Color,
+		Link:      item.Link,
+		Event:     item.Event,
+		Data:      item.Data,
+	}
+	if len(pending.Items) > 1 {
+		job.Subject, job.Content = pending.digest()
+		job.HTML, job.Color, job.Link, job.Event, job.Data = "", pending.color(), "", "", nil
+	}
+
+	if err := facades.Queue().Job(job).Dispatch(); err != nil {
+		facades.Log().Errorf("分发汇总通知任务失败: %v", err)
+	}
+}
+
+// color 汇总通知的状态颜色，包含严重告警时为红色
+func (n *notificationGroup) color() string {
+	if n.IsRecovery {
+		return severityColor("", true)
+	}
+	critical := severityColor("严重", false)
+	for _, item := range n.Items {
+		if item.Color == critical {
+			return critical
+		}
+	}
+	return severityColor("警告", false)
+}
+
+// digest 构建汇总通知的标题和内容
+func (n *notificationGroup) digest() (string, string) {
+	var builder strings.Builder
+	first, last := n.Items[0].At, n.Items[0].At
+	for _, item := range n.Items {
+		builder.WriteString(fmt.Sprintf("- %s (%s): %s\n", item.ServerName, item.ServerIP, item.Summary))
+		if item.At.Before(first) {
+			first = item.At
+		}
+		if item.At.After(last) {
+			last = item.At
+		}
+	}
+
+	if n.IsRecovery {
+		subject := fmt.Sprintf("[恢复汇总] %s - %s (%d台服务器)", n.GroupName, n.RuleLabel, len(n.Items))
+		content := fmt.Sprintf("✅ 批量恢复\n\n分组: %s\n告警: %s\n恢复服务器: %d台\n\n%s\n恢复时间: %s ~ %s",
+			n.GroupName, n.RuleLabel, len(n.Items), builder.String(),
+			first.Format("2006-01-02 15:04:05"), last.Format("2006-01-02 15:04:05"))
+		return subject, content
+	}
+
+	subject := fmt.Sprintf("[告警汇总] %s - %s (%d台服务器)", n.GroupName, n.RuleLabel, len(n.Items))
+	content := fmt.Sprintf("🚨 批量告警\n\n分组: %s\n告警: %s\n受影响服务器: %d台\n\n%s\n触发时间: %s ~ %s",
+		n.GroupName, n.RuleLabel, len(n.Items), builder.String(),
+		first.Format("2006-01-02 15:04:05"), last.Format("2006-01-02 15:04:05"))
+	return subject, content
+}
+
+// summary 告警的单行摘要，用于汇总通知
+func (e *alertEvent) summary() string {
+	if e.IsRecovery {
+		return "已恢复"
+	}
+	switch {
+	case e.Message != "":
+		return fmt.Sprintf("%s %s", e.Severity, e.MetricLabel)
+	case e.Expression != "":
+		return fmt.Sprintf("%s %s", e.Severity, e.formatValues())
+	default:
+		return fmt.Sprintf("%s %s %.2f%s", e.Severity, e.MetricLabel, e.Value, e.Unit)
+	}
+}
+
+// groupRuleLabel 汇总通知中展示的规则名称，GPU、温度等按设备拆分的规则合并为同一类
+func (e *alertEvent) groupRuleLabel() (string, string) {
+	if alertType(e.RuleKey) == "threshold" {
+		baseName, _, _ := strings.Cut(e.RuleKey, ":")
+		label, _ := metricDisplay(baseName)
+		return baseName, label
+	}
+	return e.RuleKey, e.MetricLabel
+}
+
+// groupNotifications 按服务器通知配置发送通知，开启告警合并时先进入合并窗口
+func (s *AlertService) groupNotifications(event *alertEvent, msg notificationMessage) {
+	window := alertGroupWindow()
+	if window <= 0 {
+		s.dispatchNotifications(event.ServerID, msg)
+		return
+	}
+
+	channels, err := s.getNotificationChannels(event.ServerID)
+	if err != nil {
+		facades.Log().Warningf("获取通知渠道失败: %v", err)
+		return
+	}
+
+	serverName, serverIP, groupID, groupName := event.ServerID, "未知", uint(0), "未分组"
+	if server, err := repositories.GetServerRepository().GetByID(event.ServerID); err == nil && server != nil {
+		serverName, serverIP = server.Name, server.IP
+		if server.GroupID != nil {
+			if group, err := repositories.GetServerGroupRepository().GetByID(*server.GroupID); err == nil && group != nil && group.ID > 0 {
+				groupID, groupName = group.ID, group.Name
+			}
+		}
+	}
+
+	ruleType, ruleLabel := event.groupRuleLabel()
+	kind := "firing"
+	if event.IsRecovery {
+		kind = "recovery"
+	}
+
+	for _, channel := range channels {
+		job := notificationJob(channel, msg)
+		key := fmt.Sprintf("%d:%s:%d:%s", channel.ID, ruleType, groupID, kind)
+		GetAlertGrouper().Add(key, notificationGroup{
+			ChannelID:  job.ChannelID,
+			Channel:    job.Channel,
+			Config:     job.Config,
+			GroupName:  groupName,
+			RuleLabel:  ruleLabel,
+			IsRecovery: event.IsRecovery,
+		}, groupedNotification{
+			ServerName: serverName,
+			ServerIP:   serverIP,
+			Summary:    event.summary(),
+			Subject:    job.Subject,
+			Content:    job.Content,
+			HTML:       job.HTML,
+			Color:      job.Color,
+			Link:       job.Link,
+			Event:      job.Event,
+			Data:       job.Data,
+			At:         time.Now(),
+		}, window)
+	}
+}
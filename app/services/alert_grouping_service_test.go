@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"testing"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestAlertGrouperFlush(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	requests := newWebhookChannel(t, alertService)
+	// 合并窗口为 10 分钟，窗口结束前通知缓存在内存中
+	if err := repositories.GetSystemSettingRepository().SetValue("alert_group_window", "600"); err != nil {
+		t.Fatal(err)
+	}
+
+	serverID := "grouped-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "web-01", IP: "10.0.3.1", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{}, map[string]interface{}{
+		"cpu": map[string]interface{}{"enabled": true},
+	}); err != nil {
+		t.Fatalf("保存规则失败: %v", err)
+	}
+
+	if err := alertService.CheckAndAlert(serverID, map[string]interface{}{"cpu_usage": 95.0}); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Fatalf("合并窗口内发送了 %d 条通知", got)
+	}
+
+	// 服务关闭时立即发送窗口内的通知
+	services.GetAlertGrouper().Flush()
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("关闭时发送了 %d 条通知，期望 1", got)
+	}
+	services.GetAlertGrouper().Flush()
+	if got := requests.Load(); got != 1 {
+		t.Errorf("再次发送后共发送 %d 条通知，已发送的通知不应重复发送", got)
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/google/uuid"
+	"github.com/goravel/framework/facades"
+)
+
+// serverOfflineRuleKey 服务器离线告警的状态标识
+const serverOfflineRuleKey = "server_offline"
+
+// alertType 根据状态标识获取告警类型
+func alertType(ruleKey string) string {
+	if ruleKey == serverOfflineRuleKey {
+		return "server_offline"
+	}
+	if strings.HasPrefix(ruleKey, ExpressionRuleTypePrefix) {
+		return "expression"
+	}
+	if strings.HasPrefix(ruleKey, AnomalyRuleKeyPrefix) {
+		return "anomaly"
+	}
+	return "threshold"
+}
+
+// recordAlert 记录告警状态变化：进入告警时创建告警实例，升级或降级时更新级别，恢复时结束告警实例
+func (s *AlertService) recordAlert(event *alertEvent, newState AlertState) {
+	if newState == AlertStateNormal {
+		event.Alert = s.resolveAlert(event.ServerID, event.RuleKey, "auto")
+		return
+	}
+
+	alertRepo := repositories.GetAlertRepository()
+	alert, err := alertRepo.GetFiring(event.ServerID, event.RuleKey)
+	if err != nil {
+		facades.Log().Warningf("获取进行中的告警失败: %v", err)
+		return
+	}
+
+	if alert == nil {
+		alert = &models.Alert{
+			ID:        uuid.New().String(),
+			ServerID:  event.ServerID,
+			RuleKey:   event.RuleKey,
+			Type:      alertType(event.RuleKey),
+			Status:    models.AlertStatusFiring,
+			Timestamp: time.Now(),
+		}
+		// 关联 server_alert_rules 中触发告警的规则，使用默认规则时为空
+		alert.RuleID = event.RuleID
+		alert.EscalationPolicyID = s.resolveEscalationPolicy(event.ServerID, event.PolicyID)
+	}
+
+	alert.Severity = string(newState)
+	alert.Title = event.MetricLabel
+	alert.Message = event.detail()
+	if alert.Type == "threshold" || alert.Type == "anomaly" {
+		value, threshold := event.Value, event.Threshold
+		alert.MetricValue = &value
+		alert.Threshold = &threshold
+	}
+	if len(event.Labels) > 0 {
+		labelsJson, _ := json.Marshal(event.Labels)
+		alert.Labels = string(labelsJson)
+	}
+
+	if alert.CreatedAt.IsZero() {
+		err = alertRepo.Create(alert)
+	} else {
+		err = alertRepo.Update(alert)
+	}
+	if err != nil {
+		facades.Log().Errorf("保存告警记录失败: %v", err)
+		return
+	}
+	event.Alert = alert
+	broadcastAlert(alert)
+}
+
+// resolveAlert 结束指定规则进行中的告警实例，返回结束的告警实例，没有进行中的告警时返回 nil
+func (s *AlertService) resolveAlert(serverID, ruleKey, resolvedBy string) *models.Alert {
+	alertRepo := repositories.GetAlertRepository()
+	alert, err := alertRepo.GetFiring(serverID, ruleKey)
+	if err != nil || alert == nil {
+		return nil
+	}
+
+	now := time.Now()
+	alert.Status = models.AlertStatusResolved
+	alert.ResolvedAt = &now
+	alert.ResolvedBy = resolvedBy
+	if err := alertRepo.Update(alert); err != nil {
+		facades.Log().Errorf("更新告警记录失败: %v", err)
+		return nil
+	}
+	broadcastAlert(alert)
+	return alert
+}
+
+// clearAlertState 清除规则的告警状态并结束进行中的告警实例（规则停用、删除或手动处理时调用）
+func (s *AlertService) clearAlertState(serverID, ruleKey, resolvedBy string) {
+	for _, prefix := range []string{"alert_state", "alert_cooldown", "alert_pending"} {
+		_ = facades.Cache().Forget(fmt.Sprintf("%s:%s:%s", prefix, serverID, ruleKey))
+	}
+	s.resolveAlert(serverID, ruleKey, resolvedBy)
+}
+
+// AcknowledgeAlert 确认告警
+func (s *AlertService) AcknowledgeAlert(id, acknowledgedBy string) (*models.Alert, error) {
+	alertRepo := repositories.GetAlertRepository()
+	alert, err := alertRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil {
+		return nil, errors.New("告警不存在")
+	}
+	if alert.AcknowledgedAt != nil {
+		return alert, nil
+	}
+
+	now := time.Now()
+	alert.AcknowledgedAt = &now
+	alert.AcknowledgedBy = acknowledgedBy
+	alert.IsRead = true
+	if err := alertRepo.Update(alert); err != nil {
+		return nil, err
+	}
+	broadcastAlert(alert)
+	return alert, nil
+}
+
+// ResolveAlert 手动恢复告警，同时重置规则状态，若指标仍超出阈值将重新触发新的告警
+func (s *AlertService) ResolveAlert(id, resolvedBy string) (*models.Alert, error) {
+	alert, err := repositories.GetAlertRepository().GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil {
+		return nil, errors.New("告警不存在")
+	}
+	if alert.Status == models.AlertStatusResolved {
+		return nil, errors.New("告警已恢复")
+	}
+
+	s.clearAlertState(alert.ServerID, alert.RuleKey, resolvedBy)
+	return repositories.GetAlertRepository().GetByID(id)
+}
+
+// RestoreAlertStates 启动时根据 alerts 表中进行中的告警恢复规则状态，避免重启后重复告警或丢失恢复通知
+func (s *AlertService) RestoreAlertStates() {
+	alerts, err := repositories.GetAlertRepository().GetAllFiring()
+	if err != nil {
+		facades.Log().Errorf("恢复告警状态失败: %v", err)
+		return
+	}
+
+	restored := 0
+	for _, alert := range alerts {
+		// 离线状态由连接管理器维护
+		if alert.RuleKey == serverOfflineRuleKey {
+			continue
+		}
+		cacheKey := fmt.Sprintf("alert_state:%s:%s", alert.ServerID, alert.RuleKey)
+		if facades.Cache().Forever(cacheKey, alert.Severity) {
+			restored++
+		}
+	}
+	if restored > 0 {
+		facades.Log().Infof("已从告警记录恢复 %d 个告警状态", restored)
+	}
+}
+
+// broadcastAlert 向前端推送告警变化
+func broadcastAlert(alert *models.Alert) {
+	GetWebSocketService().BroadcastToFrontend(map[string]interface{}{
+		"type": "alert_update",
+		"data": alert,
+	})
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+)
+
+// AlertProfileOptions 创建或更新告警配置模板的参数
+type AlertProfileOptions struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Rules       map[string]interface{} `json:"rules"` // 与服务器告警规则相同的格式，只保存包含的规则类型
+}
+
+// validate 校验告警配置模板参数
+func (o *AlertProfileOptions) validate(s *AlertService) error {
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("模板名称不能为空")
+	}
+	return s.ValidateAlertRules(o.Rules)
+}
+
+// CreateAlertProfile 创建告警配置模板及其规则
+func (s *AlertService) CreateAlertProfile(opts AlertProfileOptions) (*models.AlertProfile, error) {
+	if err := opts.validate(s); err != nil {
+		return nil, err
+	}
+
+	// 模板ID在创建时生成，规则的作用范围由 CreateWithRules 设置
+	rules, err := buildAlertRules(RuleScope{}, opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	profile := &models.AlertProfile{
+		Name:        opts.Name,
+		Description: opts.Description,
+	}
+	if err := repositories.GetAlertProfileRepository().CreateWithRules(profile, rules); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateAlertProfile 更新告警配置模板，修改的规则对所有使用该模板的服务器立即生效
+func (s *AlertService) UpdateAlertProfile(id uint, opts AlertProfileOptions) (*models.AlertProfile, error) {
+	if err := opts.validate(s); err != nil {
+		return nil, err
+	}
+
+	profileRepo := repositories.GetAlertProfileRepository()
+	profile, err := profileRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, errors.New("告警配置模板不存在")
+	}
+
+	profile.Name = opts.Name
+	profile.Description = opts.Description
+	if err := profileRepo.Update(profile); err != nil {
+		return nil, err
+	}
+	if err := s.SaveAlertRules(RuleScope{ProfileID: &profile.ID}, opts.Rules); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// AssignAlertProfile 为服务器和分组设置告警配置模板，profileID 为空时取消模板
+// 服务器自身配置的规则仍优先于模板规则，作为单台服务器的覆盖
+func (s *AlertService) AssignAlertProfile(profileID *uint, serverIDs []string, groupIDs []uint) error {
+	if profileID != nil {
+		profile, err := repositories.GetAlertProfileRepository().GetByID(*profileID)
+		if err != nil {
+			return err
+		}
+		if profile == nil {
+			return errors.New("告警配置模板不存在")
+		}
+	}
+
+	return repositories.GetAlertProfileRepository().Assign(profileID, serverIDs, groupIDs)
+}
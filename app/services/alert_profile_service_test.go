@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"testing"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestCreateAlertProfile(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+
+	profile, err := alertService.CreateAlertProfile(services.AlertProfileOptions{
+		Name: "Web 服务器",
+		Rules: map[string]interface{}{
+			"cpu":       map[string]interface{}{"enabled": true, "warning": 70.0},
+			"bandwidth": map[string]interface{}{"enabled": true, "threshold": 200.0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建模板失败: %v", err)
+	}
+	rules, err := repositories.GetServerAlertRuleRepository().GetByProfileID(profile.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("模板规则数为 %d，期望 2", len(rules))
+	}
+	for _, rule := range rules {
+		if rule.Source() != "profile" || rule.CreatedAt.IsZero() {
+			t.Errorf("规则 %s 来源为 %s，创建时间 %v", rule.RuleType, rule.Source(), rule.CreatedAt)
+		}
+	}
+}
+
+func TestCreateAlertProfileRollsBack(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	profileRepo := repositories.GetAlertProfileRepository()
+
+	// 规则无效时不创建模板
+	if _, err := alertService.CreateAlertProfile(services.AlertProfileOptions{
+		Name: "表达式",
+		Rules: map[string]interface{}{
+			"cpu":                map[string]interface{}{"enabled": true},
+			"expression:cpu_mem": map[string]interface{}{"name": "CPU和内存", "expression": "cpu_usage > 90 and memory_usage > 80"},
+		},
+	}); err == nil {
+		t.Error("模板中包含表达式规则时应返回错误")
+	}
+
+	// 规则写入失败时回滚已创建的模板
+	existing := &models.ServerAlertRule{RuleType: "cpu", Config: "{}"}
+	if err := facades.Orm().Query().Create(existing); err != nil {
+		t.Fatal(err)
+	}
+	err := profileRepo.CreateWithRules(&models.AlertProfile{Name: "冲突"}, []*models.ServerAlertRule{
+		{RuleType: "memory", Config: "{}"},
+		{ID: existing.ID, RuleType: "disk", Config: "{}"},
+	})
+	if err == nil {
+		t.Error("规则主键冲突时应返回错误")
+	}
+
+	profiles, err := profileRepo.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("创建失败后仍有 %d 个模板", len(profiles))
+	}
+	count, err := facades.Orm().Query().Model(&models.ServerAlertRule{}).Count()
+	if err != nil || count != 1 {
+		t.Errorf("规则数为 %d (%v)，创建失败后不应写入模板规则", count, err)
+	}
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/cooldown"
+)
+
+// thresholdRuleDefaults 阈值规则的默认警告和严重阈值
+var thresholdRuleDefaults = map[string][2]float64{
+	"cpu":         {80, 90},
+	"memory":      {85, 95},
+	"disk":        {85, 95},
+	"gpu_util":    {90, 98},
+	"gpu_mem":     {90, 98},
+	"gpu_temp":    {80, 90},
+	"temperature": {75, 90},
+}
+
+// configRuleDefaults 按 JSON 原样保存的规则类型及其默认配置
+var configRuleDefaults = map[string]map[string]interface{}{
+	"bandwidth": {"enabled": false, "threshold": 100},
+	// 流量耗尽告警需要按重置周期统计的流量用量，目前尚未统计，规则暂不参与检查
+	"traffic":    {"enabled": false, "threshold_percent": 80},
+	"expiration": {"enabled": false, "alert_days": 7},
+	"service":    {"enabled": false},
+	// 异常检测规则，未配置的字段使用 defaultAnomalyRule 的默认值
+	"anomaly": {"enabled": false},
+	// 磁盘写满预测规则，未配置的字段使用 defaultDiskForecastRule 的默认值
+	"disk_forecast": {"enabled": false},
+	// 离线告警由系统设置开关控制，规则只用于配置频繁断线重连时的重复通知
+	"server_offline": {"repeat_interval": "5m"},
+}
+
+// RuleScope 规则的作用范围，ServerID、GroupID 和 ProfileID 均为空时为全局规则
+type RuleScope struct {
+	ServerID  *string
+	GroupID   *uint
+	ProfileID *uint
+}
+
+// EffectiveRule 服务器生效的规则及其来源
+type EffectiveRule struct {
+	Config   interface{} `json:"config"`
+	Source   string      `json:"source"`              // server、group、profile、global、default
+	SourceID string      `json:"source_id,omitempty"` // 来源为 server、group 或 profile 时的服务器ID、分组ID或模板ID
+}
+
+// ValidateAlertRules 校验请求数据中的各规则，阈值规则按保存时的默认值校验完整的规则
+func (s *AlertService) ValidateAlertRules(data map[string]interface{}) error {
+	for ruleType, value := range data {
+		ruleData, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := validateRuleConfig(ruleType, ruleData); err != nil {
+			return fmt.Errorf("告警规则 %s 无效: %v", ruleType, err)
+		}
+	}
+	return nil
+}
+
+// validateRuleConfig 按规则类型校验单条规则的配置，不支持的规则类型不校验
+func validateRuleConfig(ruleType string, ruleData map[string]interface{}) error {
+	if defaults, ok := thresholdRuleDefaults[ruleType]; ok {
+		rule := newThresholdRule(defaults, ruleData)
+		return rule.Validate()
+	}
+
+	configJson, _ := json.Marshal(ruleData)
+	switch ruleType {
+	case "anomaly":
+		rule, err := parseAnomalyRule(string(configJson))
+		if err != nil {
+			return err
+		}
+		return rule.Validate()
+	case diskForecastRuleKey:
+		rule, err := parseDiskForecastRule(string(configJson))
+		if err != nil {
+			return err
+		}
+		return rule.Validate()
+	}
+
+	if _, ok := configRuleDefaults[ruleType]; ok {
+		return cooldown.FromConfig(ruleData).Validate()
+	}
+	return nil
+}
+
+// newThresholdRule 从请求数据中解析阈值规则，未设置阈值时使用默认值
+func newThresholdRule(defaults [2]float64, ruleData map[string]interface{}) Rule {
+	enabled, _ := ruleData["enabled"].(bool)
+	warning, _ := ruleData["warning"].(float64)
+	critical, _ := ruleData["critical"].(float64)
+	if warning == 0 {
+		warning = defaults[0]
+	}
+	if critical == 0 {
+		critical = defaults[1]
+	}
+	rule := Rule{
+		Enabled:  enabled,
+		Warning:  warning,
+		Critical: critical,
+	}
+	// 可选的持续时间、恢复阈值、标签和升级策略
+	ApplyRuleOptions(&rule, ruleData)
+	return rule
+}
+
+// SaveAlertRules 从请求数据中解析并保存规则，只保存请求中包含的规则类型
+// 阈值规则未设置阈值时使用默认值，调用前应先通过 ValidateAlertRules 校验
+func (s *AlertService) SaveAlertRules(scope RuleScope, data map[string]interface{}) error {
+	rules, err := buildAlertRules(scope, data)
+	if err != nil {
+		return err
+	}
+
+	ruleRepo := repositories.GetServerAlertRuleRepository()
+	for _, rule := range rules {
+		if err := ruleRepo.CreateOrUpdate(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildAlertRules 从请求数据中解析作用范围内的规则记录，任一规则无效时返回错误
+func buildAlertRules(scope RuleScope, data map[string]interface{}) ([]*models.ServerAlertRule, error) {
+	// 表达式规则只能配置在服务器上，通过 SaveExpressionRule 保存
+	if scope.ServerID == nil {
+		for ruleType := range data {
+			if strings.HasPrefix(ruleType, ExpressionRuleTypePrefix) {
+				return nil, errors.New("表达式规则只能配置在服务器上，不支持分组、模板和全局规则")
+			}
+		}
+	}
+
+	var rules []*models.ServerAlertRule
+	for ruleType, defaults := range thresholdRuleDefaults {
+		ruleData, ok := data[ruleType].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rule := newThresholdRule(defaults, ruleData)
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("告警规则 %s 无效: %v", ruleType, err)
+		}
+
+		ruleJson, err := json.Marshal(rule)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &models.ServerAlertRule{
+			ServerID:  scope.ServerID,
+			GroupID:   scope.GroupID,
+			ProfileID: scope.ProfileID,
+			RuleType:  ruleType,
+			Config:    string(ruleJson),
+		})
+	}
+
+	for ruleType := range configRuleDefaults {
+		ruleData, ok := data[ruleType].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		configJson, err := json.Marshal(ruleData)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &models.ServerAlertRule{
+			ServerID:  scope.ServerID,
+			GroupID:   scope.GroupID,
+			ProfileID: scope.ProfileID,
+			RuleType:  ruleType,
+			Config:    string(configJson),
+		})
+	}
+
+	return rules, nil
+}
+
+// GetScopeAlertRules 获取服务器、分组、模板或全局直接配置的规则（不含继承的规则）
+func (s *AlertService) GetScopeAlertRules(scope RuleScope) (map[string]interface{}, error) {
+	ruleRepo := repositories.GetServerAlertRuleRepository()
+
+	var ruleRecords []*models.ServerAlertRule
+	var err error
+	switch {
+	case scope.ServerID != nil:
+		ruleRecords, err = ruleRepo.GetByServerID(*scope.ServerID)
+	case scope.GroupID != nil:
+		ruleRecords, err = ruleRepo.GetByGroupID(*scope.GroupID)
+	case scope.ProfileID != nil:
+		ruleRecords, err = ruleRepo.GetByProfileID(*scope.ProfileID)
+	default:
+		ruleRecords, err = ruleRepo.GetGlobalRules()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, ruleRecord := range ruleRecords {
+		if strings.HasPrefix(ruleRecord.RuleType, ExpressionRuleTypePrefix) {
+			continue
+		}
+		var config interface{}
+		if err := json.Unmarshal([]byte(ruleRecord.Config), &config); err == nil {
+			result[ruleRecord.RuleType] = config
+		}
+	}
+	return result, nil
+}
+
+// DeleteAlertRule 删除分组、模板或全局配置的规则，使用该规则的服务器改为继承下一级规则
+func (s *AlertService) DeleteAlertRule(scope RuleScope, ruleType string) error {
+	if _, ok := thresholdRuleDefaults[ruleType]; !ok {
+		if _, ok := configRuleDefaults[ruleType]; !ok {
+			return errors.New("不支持的规则类型")
+		}
+	}
+
+	ruleRepo := repositories.GetServerAlertRuleRepository()
+	if scope.GroupID != nil {
+		return ruleRepo.DeleteByGroupIDAndType(*scope.GroupID, ruleType)
+	}
+	if scope.ProfileID != nil {
+		return ruleRepo.DeleteByProfileIDAndType(*scope.ProfileID, ruleType)
+	}
+	return ruleRepo.DeleteByServerIDAndType(scope.ServerID, ruleType)
+}
+
+// GetEffectiveAlertRules 获取服务器生效的规则及每条规则的来源
+func (s *AlertService) GetEffectiveAlertRules(serverID string) (map[string]EffectiveRule, error) {
+	ruleRecords, err := repositories.GetServerAlertRuleRepository().GetEffectiveRules(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]EffectiveRule)
+	for ruleType, defaults := range thresholdRuleDefaults {
+		result[ruleType] = EffectiveRule{
+			Config: Rule{Warning: defaults[0], Critical: defaults[1]},
+			Source: "default",
+		}
+	}
+	for ruleType, config := range configRuleDefaults {
+		result[ruleType] = EffectiveRule{Config: config, Source: "default"}
+	}
+
+	for ruleType, ruleRecord := range ruleRecords {
+		if _, ok := result[ruleType]; !ok {
+			continue
+		}
+		var config interface{}
+		if err := json.Unmarshal([]byte(ruleRecord.Config), &config); err != nil {
+			continue
+		}
+
+		effective := EffectiveRule{Config: config, Source: ruleRecord.Source()}
+		switch effective.Source {
+		case "server":
+			effective.SourceID = *ruleRecord.ServerID
+		case "group":
+			effective.SourceID = strconv.FormatUint(uint64(*ruleRecord.GroupID), 10)
+		case "profile":
+			effective.SourceID = strconv.FormatUint(uint64(*ruleRecord.ProfileID), 10)
+		}
+		result[ruleType] = effective
+	}
+
+	return result, nil
+}
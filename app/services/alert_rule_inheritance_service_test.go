@@ -0,0 +1,227 @@
+package services_test
+
+import (
+	"strconv"
+	"testing"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestValidateAlertRules(t *testing.T) {
+	tests.NewApp(t)
+	alertService := services.NewAlertService()
+
+	cases := []struct {
+		name  string
+		rules map[string]interface{}
+		valid bool
+	}{
+		{"默认阈值", map[string]interface{}{"cpu": map[string]interface{}{"enabled": true}}, true},
+		{"完整的阈值规则", map[string]interface{}{"memory": map[string]interface{}{"enabled": true, "warning": 70.0, "critical": 90.0, "recovery": 60.0, "for": "5m"}}, true},
+		{"恢复阈值高于警告阈值", map[string]interface{}{"cpu": map[string]interface{}{"warning": 70.0, "critical": 90.0, "recovery": 75.0}}, false},
+		{"警告阈值高于严重阈值", map[string]interface{}{"disk": map[string]interface{}{"warning": 95.0, "critical": 90.0}}, false},
+		{"只设置警告阈值时与默认严重阈值比较", map[string]interface{}{"cpu": map[string]interface{}{"warning": 95.0}}, false},
+		{"持续时间无法解析", map[string]interface{}{"gpu_temp": map[string]interface{}{"for": "five minutes"}}, false},
+		{"重复通知间隔无法解析", map[string]interface{}{"temperature": map[string]interface{}{"repeat_interval": "soon"}}, false},
+		{"配置类规则", map[string]interface{}{"traffic": map[string]interface{}{"enabled": true, "threshold_percent": 80.0}}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := alertService.ValidateAlertRules(c.rules)
+			if c.valid && err != nil {
+				t.Fatalf("规则应有效: %v", err)
+			}
+			if !c.valid && err == nil {
+				t.Fatal("规则应无效")
+			}
+		})
+	}
+}
+
+func TestSaveAlertRulesRejectsScopedExpressionRules(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+
+	groupID, profileID := uint(1), uint(1)
+	rules := map[string]interface{}{
+		"cpu":                map[string]interface{}{"enabled": true},
+		"expression:cpu_mem": map[string]interface{}{"name": "CPU和内存", "expression": "cpu_usage > 90 and memory_usage > 80", "severity": "critical"},
+	}
+	for name, scope := range map[string]services.RuleScope{
+		"分组": {GroupID: &groupID},
+		"模板": {ProfileID: &profileID},
+		"全局": {},
+	} {
+		if err := alertService.SaveAlertRules(scope, rules); err == nil {
+			t.Errorf("%s规则中包含表达式规则时应返回错误", name)
+		}
+		saved, err := alertService.GetScopeAlertRules(scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved) != 0 {
+			t.Errorf("%s规则保存失败时不应写入任何规则，实际为 %v", name, saved)
+		}
+	}
+}
+
+// ruleLevel 规则继承的一级及其作用范围
+type ruleLevel struct {
+	name     string
+	source   string
+	sourceID string
+	scope    services.RuleScope
+}
+
+// newInheritanceServer 创建使用服务器模板、所属分组使用分组模板的服务器
+// 返回按优先级从高到低排列的各级规则范围：服务器、服务器模板、分组、分组模板、全局
+func newInheritanceServer(t *testing.T, mode string) (string, []ruleLevel) {
+	t.Helper()
+	serverProfile := &models.AlertProfile{Name: "服务器模板"}
+	groupProfile := &models.AlertProfile{Name: "分组模板"}
+	for _, profile := range []*models.AlertProfile{serverProfile, groupProfile} {
+		if err := facades.Orm().Query().Create(profile); err != nil {
+			t.Fatalf("创建告警模板失败: %v", err)
+		}
+	}
+	group := &models.ServerGroup{Name: "web", AlertProfileID: &groupProfile.ID}
+	if err := facades.Orm().Query().Create(group); err != nil {
+		t.Fatalf("创建分组失败: %v", err)
+	}
+	serverID := "inherit-server"
+	server := &models.Server{ID: serverID, Name: "web-01", IP: "10.0.2.1", AgentKey: "key", Status: "online", GroupID: &group.ID, AlertProfileID: &serverProfile.ID, AlertRulesMode: mode}
+	if err := facades.Orm().Query().Create(server); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+
+	formatID := func(id uint) string { return strconv.FormatUint(uint64(id), 10) }
+	return serverID, []ruleLevel{
+		{"服务器", "server", serverID, services.RuleScope{ServerID: &serverID}},
+		{"服务器模板", "profile", formatID(serverProfile.ID), services.RuleScope{ProfileID: &serverProfile.ID}},
+		{"分组", "group", formatID(group.ID), services.RuleScope{GroupID: &group.ID}},
+		{"分组模板", "profile", formatID(groupProfile.ID), services.RuleScope{ProfileID: &groupProfile.ID}},
+		{"全局", "global", "", services.RuleScope{}},
+	}
+}
+
+// saveCPUWarning 在指定范围保存 CPU 规则，各级使用不同的警告阈值以区分生效的规则
+func saveCPUWarning(t *testing.T, alertService *services.AlertService, scope services.RuleScope, warning float64) {
+	t.Helper()
+	if err := alertService.SaveAlertRules(scope, map[string]interface{}{
+		"cpu": map[string]interface{}{"enabled": true, "warning": warning, "critical": 95.0},
+	}); err != nil {
+		t.Fatalf("保存规则失败: %v", err)
+	}
+}
+
+// assertEffectiveCPU 检查服务器生效的 CPU 规则的来源和警告阈值
+func assertEffectiveCPU(t *testing.T, alertService *services.AlertService, serverID string, want ruleLevel, wantWarning float64) {
+	t.Helper()
+	rule, err := repositories.GetServerAlertRuleRepository().GetEffectiveByType(serverID, "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.source == "default" {
+		if rule != nil {
+			t.Errorf("没有配置规则时应使用默认规则，实际来源为 %s", rule.Source())
+		}
+	} else if rule == nil || rule.Source() != want.source {
+		t.Errorf("规则记录来源为 %v，期望 %s", rule, want.source)
+	}
+
+	effective, err := alertService.GetEffectiveAlertRules(serverID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpu := effective["cpu"]
+	var warning float64
+	switch config := cpu.Config.(type) {
+	case map[string]interface{}:
+		warning, _ = config["warning"].(float64)
+	case services.Rule:
+		warning = config.Warning
+	}
+	if cpu.Source != want.source || cpu.SourceID != want.sourceID || warning != wantWarning {
+		t.Errorf("生效规则来源为 %s(%s)，警告阈值 %.0f，期望 %s(%s)，警告阈值 %.0f", cpu.Source, cpu.SourceID, warning, want.source, want.sourceID, wantWarning)
+	}
+}
+
+func TestGetEffectiveAlertRulesOrder(t *testing.T) {
+	defaultLevel := ruleLevel{name: "默认", source: "default"}
+	names := []string{"服务器", "服务器模板", "分组", "分组模板", "全局", defaultLevel.name}
+	// 从第 from 级开始的各级都配置规则时，生效的是第 from 级的规则
+	for from, name := range names {
+		t.Run(name, func(t *testing.T) {
+			tests.NewDatabase(t)
+			alertService := services.NewAlertService()
+			serverID, levels := newInheritanceServer(t, models.AlertRulesModeOverride)
+			for i := from; i < len(levels); i++ {
+				saveCPUWarning(t, alertService, levels[i].scope, float64(10*(i+1)))
+			}
+
+			if from == len(levels) {
+				assertEffectiveCPU(t, alertService, serverID, defaultLevel, 80)
+				return
+			}
+			assertEffectiveCPU(t, alertService, serverID, levels[from], float64(10*(from+1)))
+		})
+	}
+}
+
+func TestGetEffectiveAlertRulesInheritMode(t *testing.T) {
+	cases := []struct {
+		name      string
+		configure []int // 配置规则的级别
+		want      int   // 生效的级别
+	}{
+		{"忽略服务器规则使用服务器模板", []int{0, 1, 2}, 1},
+		{"忽略服务器规则使用分组", []int{0, 2, 4}, 2},
+		{"忽略服务器规则使用全局", []int{0, 4}, 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tests.NewDatabase(t)
+			alertService := services.NewAlertService()
+			serverID, levels := newInheritanceServer(t, models.AlertRulesModeInherit)
+			for _, i := range c.configure {
+				saveCPUWarning(t, alertService, levels[i].scope, float64(10*(i+1)))
+			}
+			assertEffectiveCPU(t, alertService, serverID, levels[c.want], float64(10*(c.want+1)))
+		})
+	}
+
+	t.Run("只有服务器规则时使用默认规则", func(t *testing.T) {
+		tests.NewDatabase(t)
+		alertService := services.NewAlertService()
+		serverID, levels := newInheritanceServer(t, models.AlertRulesModeInherit)
+		saveCPUWarning(t, alertService, levels[0].scope, 10)
+		assertEffectiveCPU(t, alertService, serverID, ruleLevel{source: "default"}, 80)
+	})
+}
+
+func TestDeleteAlertRuleFallsBack(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	serverID, levels := newInheritanceServer(t, models.AlertRulesModeOverride)
+	for i, level := range levels {
+		saveCPUWarning(t, alertService, level.scope, float64(10*(i+1)))
+	}
+
+	// 依次删除生效的规则，服务器改为使用下一级的规则，全部删除后使用默认规则
+	for i, level := range levels {
+		if err := alertService.DeleteAlertRule(level.scope, "cpu"); err != nil {
+			t.Fatalf("删除%s规则失败: %v", level.name, err)
+		}
+		if i+1 < len(levels) {
+			assertEffectiveCPU(t, alertService, serverID, levels[i+1], float64(10*(i+2)))
+		} else {
+			assertEffectiveCPU(t, alertService, serverID, ruleLevel{source: "default"}, 80)
+		}
+	}
+}
@@ -1,16 +1,17 @@
 package services
 
 import (
-	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
-	"goravel/app/jobs"
 	"goravel/app/models"
 	"goravel/app/repositories"
 	"goravel/app/utils"
-	"goravel/app/utils/notification"
-	"html/template"
+	"goravel/app/utils/cooldown"
+	"goravel/app/utils/expression"
+	"goravel/app/utils/notifytemplate"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/goravel/framework/facades"
@@ -33,11 +34,62 @@ type Rule struct {
 	Enabled  bool    `json:"enabled"`
 	Warning  float64 `json:"warning"`
 	Critical float64 `json:"critical"`
+	// 以下字段可选，旧规则不包含时保持单次采样即触发、低于阈值即恢复的行为
+	For      string            `json:"for,omitempty"`      // 触发前需持续的时间，如 5m
+	Recovery *float64          `json:"recovery,omitempty"` // 恢复阈值，告警后需低于该值才恢复
+	Labels   map[string]string `json:"labels,omitempty"`   // 附加在通知中的标签
+	// 告警升级策略，为空时使用服务器所在分组的策略
+	EscalationPolicyID *uint `json:"escalation_policy_id,omitempty"`
+	// 重复通知配置，为空时持续告警每2分钟重复通知一次
+	cooldown.Options
+	// 规则所在的规则记录，使用默认规则时为空
+	RuleID *uint `json:"-"`
+}
+
+// ApplyRuleOptions 从请求数据中读取规则的可选字段（for、recovery、labels、escalation_policy_id 及重复通知配置）
+func ApplyRuleOptions(rule *Rule, data map[string]interface{}) {
+	if forDuration, ok := data["for"].(string); ok {
+		rule.For = forDuration
+	}
+	if recovery, ok := data["recovery"].(float64); ok {
+		rule.Recovery = &recovery
+	}
+	if labels, ok := data["labels"].(map[string]interface{}); ok {
+		rule.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			rule.Labels[key] = fmt.Sprint(value)
+		}
+	}
+	if policyID, ok := data["escalation_policy_id"].(float64); ok && policyID > 0 {
+		id := uint(policyID)
+		rule.EscalationPolicyID = &id
+	}
+	rule.Options = cooldown.FromConfig(data)
+}
+
+// Validate 校验规则的可选字段
+func (r Rule) Validate() error {
+	if _, err := expression.ParseDuration(r.For); err != nil {
+		return err
+	}
+	if r.Warning > r.Critical {
+		return fmt.Errorf("警告阈值不能高于严重阈值")
+	}
+	if r.Recovery != nil && *r.Recovery > r.Warning {
+		return fmt.Errorf("恢复阈值不能高于警告阈值")
+	}
+	return r.Options.Validate()
 }
 
 // AlertState 告警状态
 type AlertState string
 
+// defaultRepeatInterval 未配置重复通知间隔时，持续告警的重复通知间隔
+const defaultRepeatInterval = 2 * time.Minute
+
+// defaultOfflineRepeatInterval 未配置重复通知间隔时，服务器频繁断线重连的离线通知间隔
+const defaultOfflineRepeatInterval = 5 * time.Minute
+
 const (
 	AlertStateNormal   AlertState = "normal"
 	AlertStateWarning  AlertState = "warning"
@@ -46,13 +98,13 @@ const (
 
 // CheckAndAlert 检查指标并触发告警
 func (s *AlertService) CheckAndAlert(serverID string, metrics map[string]interface{}) error {
-	// 获取告警规则（使用服务器特定规则）
-	serverIDPtr := &serverID
-	rules, err := s.GetServerRules(serverIDPtr)
+	// 获取服务器生效的规则，本次检查的各项告警共用
+	ruleRecords, err := s.LoadServerRules(serverID)
 	if err != nil {
 		facades.Log().Warningf("获取告警规则失败: %v", err)
 		return err
 	}
+	rules := ruleRecords.thresholdRules()
 
 	// 检查 CPU 告警
 	if cpuUsage, ok := metrics["cpu_usage"].(float64); ok {
@@ -75,14 +127,99 @@ func (s *AlertService) CheckAndAlert(serverID string, metrics map[string]interfa
 		}
 	}
 
+	// 检查带宽峰值，上传和下载速率（字节/秒）取较大者换算为 Mbps
+	sentRate, sentOK := metrics["net_bytes_sent_rate"].(float64)
+	recvRate, recvOK := metrics["net_bytes_recv_rate"].(float64)
+	if sentOK || recvOK {
+		if err := s.checkBandwidth(serverID, ruleRecords["bandwidth"], max(sentRate, recvRate)*8/1e6); err != nil {
+			facades.Log().Warningf("带宽峰值告警检查失败: %v", err)
+		}
+	}
+
+	// 检查表达式规则
+	if err := s.CheckExpressionRules(serverID); err != nil {
+		facades.Log().Warningf("表达式告警检查失败: %v", err)
+	}
+
+	// 检查基线异常
+	if err := s.CheckAnomalies(serverID, ruleRecords.anomalyRule(), metrics, time.Now()); err != nil {
+		facades.Log().Warningf("异常检测告警检查失败: %v", err)
+	}
+
+	// 检查磁盘写满预测
+	if err := s.CheckDiskForecast(serverID, ruleRecords.diskForecastRule(), time.Now()); err != nil {
+		facades.Log().Warningf("磁盘写满预测告警检查失败: %v", err)
+	}
+
+	return nil
+}
+
+// CheckGPUMetrics 检查每块GPU的使用率、显存和温度告警
+func (s *AlertService) CheckGPUMetrics(serverID string, metrics []*models.ServerGPUMetric) error {
+	rules, err := s.GetServerRules(&serverID)
+	if err != nil {
+		facades.Log().Warningf("获取告警规则失败: %v", err)
+		return err
+	}
+	if !rules.GPUUtil.Enabled && !rules.GPUMem.Enabled && !rules.GPUTemp.Enabled {
+		return nil
+	}
+
+	for _, metric := range metrics {
+		// 每块GPU单独维护告警状态，例如 gpu_util:0
+		if err := s.evaluateRule(serverID, fmt.Sprintf("gpu_util:%d", metric.GPUIndex), metric.Utilization, rules.GPUUtil); err != nil {
+			facades.Log().Warningf("GPU使用率告警检查失败: %v", err)
+		}
+		if metric.MemoryTotal > 0 {
+			if err := s.evaluateRule(serverID, fmt.Sprintf("gpu_mem:%d", metric.GPUIndex), metric.MemoryUsagePercent(), rules.GPUMem); err != nil {
+				facades.Log().Warningf("GPU显存告警检查失败: %v", err)
+			}
+		}
+		if err := s.evaluateRule(serverID, fmt.Sprintf("gpu_temp:%d", metric.GPUIndex), metric.Temperature, rules.GPUTemp); err != nil {
+			facades.Log().Warningf("GPU温度告警检查失败: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// CheckTemperature 检查温度告警，按传感器类型取最高温度分别评估
+func (s *AlertService) CheckTemperature(serverID string, readings []*models.ServerSensorReading) error {
+	rules, err := s.GetServerRules(&serverID)
+	if err != nil {
+		facades.Log().Warningf("获取告警规则失败: %v", err)
+		return err
+	}
+	if !rules.Temperature.Enabled {
+		return nil
+	}
+
+	maxBySensorType := make(map[string]float64)
+	for _, reading := range readings {
+		if current, ok := maxBySensorType[reading.SensorType]; !ok || reading.Temperature > current {
+			maxBySensorType[reading.SensorType] = reading.Temperature
+		}
+	}
+
+	for sensorType, temperature := range maxBySensorType {
+		if err := s.evaluateRule(serverID, "temperature:"+sensorType, temperature, rules.Temperature); err != nil {
+			facades.Log().Warningf("温度告警检查失败: %v", err)
+		}
+	}
+
 	return nil
 }
 
 // Rules 所有告警规则
 type Rules struct {
-	CPU    Rule `json:"cpu"`
-	Memory Rule `json:"memory"`
-	Disk   Rule `json:"disk"`
+	CPU     Rule `json:"cpu"`
+	Memory  Rule `json:"memory"`
+	Disk    Rule `json:"disk"`
+	GPUUtil Rule `json:"gpu_util"`
+	GPUMem  Rule `json:"gpu_mem"`
+	GPUTemp Rule `json:"gpu_temp"`
+	// 硬件传感器温度（°C）
+	Temperature Rule `json:"temperature"`
 }
 
 // getRules 获取所有告警规则（兼容旧接口，使用全局规则）
@@ -92,38 +229,61 @@ func (s *AlertService) getRules() (*Rules, error) {
 
 // GetServerRules 获取指定服务器的告警规则
 func (s *AlertService) GetServerRules(serverID *string) (*Rules, error) {
-	// 默认规则（禁用状态）
-	defaultRules := &Rules{
-		CPU:    Rule{Enabled: false, Warning: 80, Critical: 90},
-		Memory: Rule{Enabled: false, Warning: 85, Critical: 95},
-		Disk:   Rule{Enabled: false, Warning: 85, Critical: 95},
-	}
+	ruleRepo := repositories.GetServerAlertRuleRepository()
 
-	// 如果没有指定服务器ID，返回禁用状态的默认规则
+	// 获取生效的规则：未指定服务器时使用全局规则，否则按 服务器 -> 分组 -> 全局 的顺序继承
+	ruleRecords := make(ServerRules)
 	if serverID == nil {
-		return defaultRules, nil
+		globalRules, err := ruleRepo.GetGlobalRules()
+		if err == nil {
+			for _, ruleRecord := range globalRules {
+				ruleRecords[ruleRecord.RuleType] = ruleRecord
+			}
+		}
+	} else if effectiveRules, err := ruleRepo.GetEffectiveRules(*serverID); err == nil {
+		ruleRecords = effectiveRules
 	}
 
-	ruleRepo := repositories.GetServerAlertRuleRepository()
-	ruleTypes := []string{"cpu", "memory", "disk"}
+	return ruleRecords.thresholdRules(), nil
+}
+
+// ServerRules 服务器生效的规则记录，以规则类型为键
+// 处理一次上报数据时只查询一次，再传给该数据触发的各项告警检查
+type ServerRules map[string]*models.ServerAlertRule
+
+// LoadServerRules 获取服务器生效的规则记录
+func (s *AlertService) LoadServerRules(serverID string) (ServerRules, error) {
+	return repositories.GetServerAlertRuleRepository().GetEffectiveRules(serverID)
+}
+
+// thresholdRules 解析阈值规则，未配置的规则类型使用禁用状态的默认规则
+func (r ServerRules) thresholdRules() *Rules {
+	// 默认规则（禁用状态）
+	defaultRules := &Rules{
+		CPU:     Rule{Enabled: false, Warning: 80, Critical: 90},
+		Memory:  Rule{Enabled: false, Warning: 85, Critical: 95},
+		Disk:    Rule{Enabled: false, Warning: 85, Critical: 95},
+		GPUUtil: Rule{Enabled: false, Warning: 90, Critical: 98},
+		GPUMem:  Rule{Enabled: false, Warning: 90, Critical: 98},
+		GPUTemp: Rule{Enabled: false, Warning: 80, Critical: 90},
+		// 硬件传感器温度
+		Temperature: Rule{Enabled: false, Warning: 75, Critical: 90},
+	}
+	ruleTypes := []string{"cpu", "memory", "disk", "gpu_util", "gpu_mem", "gpu_temp", "temperature"}
 
-	// 获取服务器特定规则
 	serverRules := make(map[string]*Rule)
-	serverRuleList, err := ruleRepo.GetByServerID(*serverID)
-	if err == nil {
-		for _, ruleRecord := range serverRuleList {
-			var rule Rule
-			if err := json.Unmarshal([]byte(ruleRecord.Config), &rule); err == nil {
-				serverRules[ruleRecord.RuleType] = &rule
-			}
+	for ruleType, ruleRecord := range r {
+		var rule Rule
+		if err := json.Unmarshal([]byte(ruleRecord.Config), &rule); err == nil {
+			rule.RuleID = r.ruleID(ruleType)
+			serverRules[ruleType] = &rule
 		}
 	}
 
-	// 只使用服务器特定规则，没有则使用禁用状态的默认规则
+	// 继承链上都没有配置时使用禁用状态的默认规则
 	result := &Rules{}
 	for _, ruleType := range ruleTypes {
 		var rule *Rule
-		// 只使用服务器特定规则
 		if r, ok := serverRules[ruleType]; ok {
 			rule = r
 		} else {
@@ -135,6 +295,14 @@ func (s *AlertService) GetServerRules(serverID *string) (*Rules, error) {
 				rule = &defaultRules.Memory
 			case "disk":
 				rule = &defaultRules.Disk
+			case "gpu_util":
+				rule = &defaultRules.GPUUtil
+			case "gpu_mem":
+				rule = &defaultRules.GPUMem
+			case "gpu_temp":
+				rule = &defaultRules.GPUTemp
+			case "temperature":
+				rule = &defaultRules.Temperature
 			}
 		}
 
@@ -146,10 +314,28 @@ func (s *AlertService) GetServerRules(serverID *string) (*Rules, error) {
 			result.Memory = *rule
 		case "disk":
 			result.Disk = *rule
+		case "gpu_util":
+			result.GPUUtil = *rule
+		case "gpu_mem":
+			result.GPUMem = *rule
+		case "gpu_temp":
+			result.GPUTemp = *rule
+		case "temperature":
+			result.Temperature = *rule
 		}
 	}
 
-	return result, nil
+	return result
+}
+
+// ruleID 规则类型对应的规则记录ID，没有配置时返回 nil
+func (r ServerRules) ruleID(ruleType string) *uint {
+	ruleRecord, ok := r[ruleType]
+	if !ok || ruleRecord == nil || ruleRecord.ID == 0 {
+		return nil
+	}
+	id := ruleRecord.ID
+	return &id
 }
 
 // SaveServerRules 保存服务器告警规则（serverID 不能为 nil）
@@ -185,442 +371,469 @@ func (s *AlertService) SaveServerRules(serverID *string, rules map[string]Rule)
 // evaluateRule 评估单个规则
 func (s *AlertService) evaluateRule(serverID, metricName string, value float64, rule Rule) error {
 	if !rule.Enabled {
+		// 规则停用时结束进行中的告警
+		if s.getAlertState(serverID, metricName) != AlertStateNormal {
+			s.clearAlertState(serverID, metricName, "system")
+		}
 		return nil
 	}
 
 	// 获取当前告警状态
-	cacheKey := fmt.Sprintf("alert_state:%s:%s", serverID, metricName)
-	var currentState AlertState
-	if cached := facades.Cache().Get(cacheKey); cached != nil {
-		if stateStr, ok := cached.(string); ok {
-			currentState = AlertState(stateStr)
-		}
-	}
+	currentState := s.getAlertState(serverID, metricName)
 
 	// 确定新状态
-	var newState AlertState
-	var severity string
-	if value >= rule.Critical {
-		newState = AlertStateCritical
-		severity = "严重"
-	} else if value >= rule.Warning {
-		newState = AlertStateWarning
-		severity = "警告"
-	} else {
-		newState = AlertStateNormal
+	newState, ok := rule.nextState(value, currentState)
+	if !ok {
+		return nil
 	}
 
-	// 如果状态没有变化，且不是从告警状态恢复到正常，则不发送通知
-	if newState == currentState {
-		// 如果当前是告警状态，检查是否需要重新发送（冷却期）
-		if newState != AlertStateNormal {
-			cooldownKey := fmt.Sprintf("alert_cooldown:%s:%s", serverID, metricName)
-			if cooldown := facades.Cache().Get(cooldownKey); cooldown != nil {
-				// 还在冷却期内，不发送
-				return nil
-			}
-			// 设置冷却期（2分钟）
-			err := facades.Cache().Put(cooldownKey, true, 2*time.Minute)
-			if err != nil {
-				return err
-			}
-		} else {
-			return nil
-		}
+	// 持续时间：从正常进入告警前需持续满足条件
+	forDuration, _ := expression.ParseDuration(rule.For)
+	newState = s.applyPending(serverID, metricName, currentState, newState, forDuration, time.Now())
+
+	severity := ""
+	threshold := rule.Critical
+	switch newState {
+	case AlertStateCritical:
+		severity = "严重"
+	case AlertStateWarning:
+		severity = "警告"
+		threshold = rule.Warning
 	}
 
-	// 更新状态
-	err := facades.Cache().Put(cacheKey, string(newState), 24*time.Hour)
-	if err != nil {
+	notify, err := s.transitionState(serverID, metricName, currentState, newState, rule.Options, time.Now())
+	if err != nil || !notify {
 		return err
 	}
 
-	// 如果恢复到正常状态，发送恢复通知
-	if newState == AlertStateNormal && currentState != AlertStateNormal {
-		s.sendNotification(serverID, metricName, value, newState, severity, true, rule)
-		return nil
+	metricLabel, unit := metricDisplay(metricName)
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     metricName,
+		MetricLabel: metricLabel,
+		Unit:        unit,
+		Value:       value,
+		Threshold:   threshold,
+		Severity:    severity,
+		IsRecovery:  newState == AlertStateNormal,
+		Labels:      rule.Labels,
+		PolicyID:    rule.EscalationPolicyID,
+		RuleID:      rule.RuleID,
 	}
-
-	// 如果进入告警状态，发送告警通知
-	if newState != AlertStateNormal {
-		s.sendNotification(serverID, metricName, value, newState, severity, false, rule)
+	if newState != currentState {
+		s.recordAlert(event, newState)
 	}
+	s.sendNotification(event)
 
 	return nil
 }
 
-// sendNotification 发送通知
-func (s *AlertService) sendNotification(serverID, metricName string, value float64, state AlertState, severity string, isRecovery bool, rule Rule) {
-	// 获取服务器名称
-	serverRepo := repositories.GetServerRepository()
-	server, err := serverRepo.GetByID(serverID)
-	serverName := serverID
-	serverIP := "未知"
-	if err == nil && server != nil {
-		serverName = server.Name
-		serverIP = server.IP
+// getAlertState 获取规则当前的告警状态，没有记录时视为正常，避免首次评估误发恢复通知
+func (s *AlertService) getAlertState(serverID, metricName string) AlertState {
+	cacheKey := fmt.Sprintf("alert_state:%s:%s", serverID, metricName)
+	if cached := facades.Cache().Get(cacheKey); cached != nil {
+		if stateStr, ok := cached.(string); ok && stateStr != "" {
+			return AlertState(stateStr)
+		}
 	}
+	return AlertStateNormal
+}
 
-	// 构建消息
-	metricLabel := map[string]string{
-		"cpu":    "CPU使用率",
-		"memory": "内存使用率",
-		"disk":   "磁盘使用率",
-	}[metricName]
+// transitionState 更新告警状态，返回是否需要发送通知
+// 状态变化时通知；持续告警时按规则的重复通知配置（默认每2分钟）重复通知；持续正常时不通知
+func (s *AlertService) transitionState(serverID, metricName string, currentState, newState AlertState, opts cooldown.Options, now time.Time) (bool, error) {
+	cooldownKey := fmt.Sprintf("alert_cooldown:%s:%s", serverID, metricName)
+	if newState == currentState {
+		if newState == AlertStateNormal {
+			return false, nil
+		}
+		return cooldown.Allow(cooldownKey, opts, defaultRepeatInterval, now), nil
+	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	var title, message, webhookMessage string
-	var threshold float64
-	if severity == "警告" {
-		threshold = rule.Warning
+	// 状态变化后重新计算重复通知的间隔和次数
+	if newState == AlertStateNormal {
+		cooldown.Reset(cooldownKey)
 	} else {
-		threshold = rule.Critical
+		cooldown.Start(cooldownKey, now)
 	}
 
-	if isRecovery {
-		title = fmt.Sprintf("[恢复] %s - %s", serverName, metricLabel)
-		webhookMessage = fmt.Sprintf("✅ 告警恢复\n\n服务器: %s (%s)\n指标: %s\n当前值: %.2f%%\n恢复时间: %s",
-			serverName, serverIP, metricLabel, value, timestamp)
-	} else {
-		title = fmt.Sprintf("[%s] %s - %s", severity, serverName, metricLabel)
-		webhookMessage = fmt.Sprintf("🚨 发生告警 (%s)\n\n服务器: %s (%s)\n指标: %s\n当前值: %.2f%%\n阈值: %.2f%%\n触发时间: %s",
-			severity, serverName, serverIP, metricLabel, value, threshold, timestamp)
+	// 告警状态与 alerts 表中的告警实例保持一致，不设置过期时间
+	cacheKey := fmt.Sprintf("alert_state:%s:%s", serverID, metricName)
+	if !facades.Cache().Forever(cacheKey, string(newState)) {
+		return false, fmt.Errorf("保存告警状态失败: %s", cacheKey)
 	}
+	return true, nil
+}
 
-	color := "#ff4d4f" // 红色
-	if severity == "警告" {
-		color = "#faad14" // 橙色
+// recordStateChange 记录带宽、到期等只有告警和正常两种状态的规则的状态变化，状态不变时不更新告警实例
+// 这类规则不按 transitionState 计算重复通知，由调用方按各自的默认冷却期判断
+func (s *AlertService) recordStateChange(event *alertEvent, newState AlertState) {
+	if s.getAlertState(event.ServerID, event.RuleKey) == newState {
+		return
 	}
-	if isRecovery {
-		color = "#52c41a" // 绿色
+	cacheKey := fmt.Sprintf("alert_state:%s:%s", event.ServerID, event.RuleKey)
+	if !facades.Cache().Forever(cacheKey, string(newState)) {
+		facades.Log().Warningf("保存告警状态失败: %s", cacheKey)
+		return
 	}
+	s.recordAlert(event, newState)
+}
 
-	statusText := severity
-	if isRecovery {
-		statusText = "恢复正常"
+// nextState 根据指标值确定规则的新状态
+// 滞回：告警中的指标需低于恢复阈值才恢复，避免在阈值附近反复告警；
+// 保持告警期间指标已低于阈值时返回 false，表示状态不变且不再重复通知
+func (r Rule) nextState(value float64, currentState AlertState) (AlertState, bool) {
+	newState := AlertStateNormal
+	if value >= r.Critical {
+		newState = AlertStateCritical
+	} else if value >= r.Warning {
+		newState = AlertStateWarning
 	}
 
-	templateData := map[string]interface{}{
-		"Title":        title,
-		"Timestamp":    timestamp,
-		"ServerName":   serverName,
-		"ServerIP":     serverIP,
-		"MetricLabel":  metricLabel,
-		"StatusText":   statusText,
-		"Color":        color,
-		"CurrentValue": value,
-		"Threshold":    threshold,
+	if newState == AlertStateNormal && currentState != AlertStateNormal && r.Recovery != nil && value > *r.Recovery {
+		return currentState, false
 	}
+	return newState, true
+}
 
-	var tmpl *template.Template
-	var templateErr error
-
-	templateContent, err := ResourceFiles.ReadFile("resources/views/emails/alert.tmpl")
-	if err == nil {
-		tmpl, templateErr = template.New("emails/alert.tmpl").Parse(string(templateContent))
-	} else {
-		templateErr = err
+// pendingState 处理规则的持续时间（for），since 为条件开始满足的时间（Unix 秒，0 表示尚未开始）
+// 返回生效的状态和新的开始时间：从正常进入告警时，条件需持续满足 forDuration 后才生效；告警升级、降级和恢复立即生效
+func pendingState(currentState, newState AlertState, since int64, forDuration time.Duration, now time.Time) (AlertState, int64) {
+	if currentState != AlertStateNormal || newState == AlertStateNormal || forDuration <= 0 {
+		return newState, 0
 	}
-	if templateErr != nil {
-		facades.Log().Warningf("解析邮件模板失败: %v", templateErr)
-		if isRecovery {
-			message = fmt.Sprintf("告警恢复通知\n\n服务器: %s (%s)\n指标: %s\n当前值: %.2f%%\n恢复时间: %s\n\n此邮件由云哨监控系统自动发送，请勿回复。",
-				serverName, serverIP, metricLabel, value, timestamp)
-		} else {
-			message = fmt.Sprintf("告警通知 (%s)\n\n服务器: %s (%s)\n指标: %s\n当前状态: %s\n当前值: %.2f%%\n触发阈值: %.2f%%\n触发时间: %s\n\n此邮件由云哨监控系统自动发送，请勿回复。",
-				severity, serverName, serverIP, metricLabel, statusText, value, threshold, timestamp)
-		}
-	} else {
-		var buf bytes.Buffer
-		templateName := "emails/alert.tmpl"
-		if execErr := tmpl.ExecuteTemplate(&buf, templateName, templateData); execErr != nil {
-			facades.Log().Errorf("渲染邮件模板失败: %v", execErr)
-			if isRecovery {
-				message = fmt.Sprintf("告警恢复通知\n\n服务器: %s (%s)\n指标: %s\n当前值: %.2f%%\n恢复时间: %s\n\n此邮件由云哨监控系统自动发送，请勿回复。",
-					serverName, serverIP, metricLabel, value, timestamp)
-			} else {
-				message = fmt.Sprintf("告警通知 (%s)\n\n服务器: %s (%s)\n指标: %s\n当前状态: %s\n当前值: %.2f%%\n触发阈值: %.2f%%\n触发时间: %s\n\n此邮件由云哨监控系统自动发送，请勿回复。",
-					severity, serverName, serverIP, metricLabel, statusText, value, threshold, timestamp)
-			}
-		} else {
-			message = buf.String()
-		}
+	if since == 0 {
+		since = now.Unix()
 	}
-
-	// 获取通知配置并发送（优先使用服务器特定配置）
-	emailConfig, webhookConfig, err := s.getNotificationConfigs(serverID)
-	if err != nil {
-		facades.Log().Warningf("获取通知配置失败: %v", err)
-		return
+	if now.Sub(time.Unix(since, 0)) < forDuration {
+		return AlertStateNormal, since
 	}
+	return newState, 0
+}
 
-	// 发送邮件
-	if emailConfig.Enabled {
-		configJson, _ := json.Marshal(emailConfig)
-		if err := facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "email",
-			Config:  string(configJson),
-			Subject: title,
-			Content: message,
-		}).Dispatch(); err != nil {
-			facades.Log().Errorf("分发邮件发送任务失败: %v", err)
-		}
-	}
+// applyPending 处理规则的持续时间（for），条件开始满足的时间保存在缓存中
+func (s *AlertService) applyPending(serverID, metricName string, currentState, newState AlertState, forDuration time.Duration, now time.Time) AlertState {
+	pendingKey := fmt.Sprintf("alert_pending:%s:%s", serverID, metricName)
 
-	// 发送Webhook
-	if webhookConfig.Enabled {
-		configJson, _ := json.Marshal(webhookConfig)
-		if err := facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "webhook",
-			Config:  string(configJson),
-			Subject: title,
-			Content: webhookMessage,
-		}).Dispatch(); err != nil {
-			facades.Log().Errorf("分发Webhook发送任务失败: %v", err)
-		}
+	var since int64
+	if cached, ok := facades.Cache().Get(pendingKey).(int64); ok {
+		since = cached
 	}
+	state, pendingSince := pendingState(currentState, newState, since, forDuration, now)
+	switch {
+	case pendingSince == 0:
+		_ = facades.Cache().Forget(pendingKey)
+	case pendingSince != since:
+		_ = facades.Cache().Put(pendingKey, pendingSince, forDuration+time.Hour)
+	}
+	return state
 }
 
-// getNotificationConfigs 获取通知配置
-func (s *AlertService) getNotificationConfigs(serverID string) (*notification.EmailConfig, *notification.WebhookConfig, error) {
-	emailConfig := &notification.EmailConfig{Enabled: false}
-	webhookConfig := &notification.WebhookConfig{Enabled: false}
+// alertEvent 一次告警或恢复通知的内容
+type alertEvent struct {
+	ServerID    string
+	RuleKey     string // 告警状态标识，如 cpu、gpu_util:0、expression:xxx
+	MetricLabel string // 阈值规则为指标名称，表达式规则为规则名称
+	Unit        string
+	Value       float64
+	Threshold   float64
+	Severity    string // 警告、严重，恢复时为空
+	IsRecovery  bool
+	Expression  string             // 表达式规则的触发条件，阈值规则为空
+	Values      map[string]float64 // 表达式中各指标项的取值
+	Labels      map[string]string
+	Message     string        // 自定义详情，设置后替代按指标生成的详情
+	PolicyID    *uint         // 规则配置的升级策略
+	RuleID      *uint         // 触发告警的规则记录，使用默认规则时为空
+	Alert       *models.Alert // 状态变化时对应的告警实例，持续告警的重复通知为空
+}
 
-	// 获取全局通知配置
-	notificationRepo := repositories.GetAlertNotificationRepository()
-	notifications, err := notificationRepo.GetAll()
-	if err != nil {
-		return emailConfig, webhookConfig, err
+// formatValues 格式化表达式中各指标项的取值，按名称排序
+func (e *alertEvent) formatValues() string {
+	names := make([]string, 0, len(e.Values))
+	for name := range e.Values {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// 解析全局配置
-	for _, notif := range notifications {
-		if !notif.Enabled || notif.ConfigJson == "" {
-			continue
-		}
-
-		switch notif.NotificationType {
-		case "email":
-			if err := json.Unmarshal([]byte(notif.ConfigJson), &emailConfig); err == nil {
-			}
-		case "webhook":
-			if err := json.Unmarshal([]byte(notif.ConfigJson), &webhookConfig); err == nil {
-			}
-		}
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%.2f", name, e.Values[name]))
 	}
+	return strings.Join(parts, ", ")
+}
 
-	// 获取服务器特定的通知渠道配置
-	channelRepo := repositories.GetServerNotificationChannelRepository()
-	serverChannels, err := channelRepo.GetByServerID(serverID)
-	if err == nil && len(serverChannels) > 0 {
-		// 只使用服务器配置的启用状态
-		for _, channel := range serverChannels {
-			if channel.NotificationType == "email" {
-				emailConfig.Enabled = channel.Enabled
-			} else if channel.NotificationType == "webhook" {
-				webhookConfig.Enabled = channel.Enabled
-			}
-		}
+// formatLabels 格式化规则标签，按名称排序
+func (e *alertEvent) formatLabels() string {
+	keys := make([]string, 0, len(e.Labels))
+	for key := range e.Labels {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return emailConfig, webhookConfig, nil
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+e.Labels[key])
+	}
+	return strings.Join(parts, ", ")
 }
 
-// GetServerNotificationChannels 获取服务器启用的通知渠道
-func (s *AlertService) GetServerNotificationChannels(serverID string) (map[string]bool, error) {
-	channelRepo := repositories.GetServerNotificationChannelRepository()
-	channels, err := channelRepo.GetByServerID(serverID)
-	if err != nil {
-		return nil, err
+// detail 告警详情文本，表达式规则展示触发条件和各指标项取值，阈值规则展示当前值和阈值
+func (e *alertEvent) detail() string {
+	if e.Message != "" {
+		return e.Message
 	}
 
-	result := make(map[string]bool)
-	for _, channel := range channels {
-		result[channel.NotificationType] = channel.Enabled
+	var detail string
+	if e.Expression != "" {
+		detail = fmt.Sprintf("规则: %s\n触发条件: %s\n指标取值: %s", e.MetricLabel, e.Expression, e.formatValues())
+	} else if e.IsRecovery {
+		detail = fmt.Sprintf("指标: %s\n当前值: %.2f%s", e.MetricLabel, e.Value, e.Unit)
+	} else {
+		detail = fmt.Sprintf("指标: %s\n当前值: %.2f%s\n阈值: %.2f%s", e.MetricLabel, e.Value, e.Unit, e.Threshold, e.Unit)
 	}
-
-	return result, nil
+	if labels := e.formatLabels(); labels != "" {
+		detail += "\n标签: " + labels
+	}
+	return detail
 }
 
-// SaveServerNotificationChannels 保存服务器通知渠道配置
-func (s *AlertService) SaveServerNotificationChannels(serverID string, channels map[string]bool) error {
-	channelRepo := repositories.GetServerNotificationChannelRepository()
+// sendNotification 发送通知
+func (s *AlertService) sendNotification(event *alertEvent) {
+	// 静默期内告警照常记录，但不发送通知
+	if s.isSilenced(event.ServerID, event.RuleKey, event.Labels) {
+		return
+	}
 
-	for notificationType, enabled := range channels {
-		// 验证 notificationType 不为空
-		if notificationType == "" {
-			continue
-		}
+	statusText := event.Severity
+	if event.IsRecovery {
+		statusText = "恢复正常"
+	}
 
-		channel := &models.ServerNotificationChannel{
-			ServerID:         serverID,
-			NotificationType: notificationType,
-			Enabled:          enabled,
-		}
+	data := s.notificationData(event.ServerID)
+	data.MetricLabel = event.MetricLabel
+	data.Severity = event.Severity
+	data.StatusText = statusText
+	data.IsRecovery = event.IsRecovery
+	data.Color = severityColor(event.Severity, event.IsRecovery)
+	data.CurrentValue = event.Value
+	data.Threshold = event.Threshold
+	data.Unit = event.Unit
+	data.Expression = event.Expression
+	data.Values = event.formatValues()
+	data.Labels = event.formatLabels()
+	data.Detail = event.detail()
+
+	templateEvent := notifytemplate.EventAlert
+	if event.IsRecovery {
+		templateEvent = notifytemplate.EventRecovery
+	}
+	msg := s.renderNotification(templateEvent, data)
+
+	// 按升级策略或服务器通知配置发送
+	s.deliver(event, msg)
+}
 
-		if err := channelRepo.CreateOrUpdate(channel); err != nil {
-			facades.Log().Warningf("保存服务器通知渠道配置失败 %s: %v", notificationType, err)
-			return err
-		}
+// notificationData 构建通知模板数据中服务器和时间相关的变量
+func (s *AlertService) notificationData(serverID string) notifytemplate.Data {
+	data := notifytemplate.Data{
+		Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
+		ServerID:   serverID,
+		ServerName: serverID,
+		ServerIP:   "未知",
+		Extra:      map[string]string{},
 	}
+	server, err := repositories.GetServerRepository().GetByID(serverID)
+	if err == nil && server != nil {
+		data.ServerName = server.Name
+		data.ServerIP = server.IP
+	}
+	return data
+}
 
-	return nil
+// metricDisplay 获取指标的显示名称和单位，支持 gpu_util:0 这类带设备序号的指标名
+func metricDisplay(metricName string) (string, string) {
+	baseName, device, _ := strings.Cut(metricName, ":")
+	label := map[string]string{
+		"cpu":           "CPU使用率",
+		"memory":        "内存使用率",
+		"disk":          "磁盘使用率",
+		"gpu_util":      "GPU使用率",
+		"gpu_mem":       "GPU显存使用率",
+		"gpu_temp":      "GPU温度",
+		"temperature":   "温度",
+		"disk_forecast": "磁盘预计写满",
+	}[baseName]
+	if label == "" {
+		label = baseName
+	}
+
+	unit := "%"
+	if baseName == "gpu_temp" || baseName == "temperature" {
+		unit = "°C"
+	} else if baseName == diskForecastRuleKey {
+		unit = "小时"
+	}
+
+	if device != "" {
+		if strings.HasPrefix(baseName, "gpu_") {
+			label = fmt.Sprintf("%s (GPU %s)", label, device)
+		} else if baseName == "temperature" {
+			sensorLabel := map[string]string{
+				"cpu_package": "CPU封装",
+				"cpu_core":    "CPU核心",
+				"nvme":        "NVMe",
+				"chassis":     "机箱",
+			}[device]
+			if sensorLabel == "" {
+				sensorLabel = device
+			}
+			label = fmt.Sprintf("%s (%s)", label, sensorLabel)
+		}
+	}
+	return label, unit
 }
 
-// CheckBandwidth 检查带宽峰值告警
-func (s *AlertService) CheckBandwidth(serverID string, currentMbps float64) error {
-	serverIDPtr := &serverID
-	ruleRepo := repositories.GetServerAlertRuleRepository()
+// getNotificationChannels 获取服务器接收通知的渠道
+func (s *AlertService) getNotificationChannels(serverID string) ([]*models.AlertNotification, error) {
+	return repositories.GetAlertNotificationRepository().GetEffectiveByServerID(serverID)
+}
 
-	// 获取服务器特定规则
-	rule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "bandwidth")
+// dispatchNotifications 按服务器订阅的通知渠道分发发送任务
+func (s *AlertService) dispatchNotifications(serverID string, msg notificationMessage) {
+	channels, err := s.getNotificationChannels(serverID)
 	if err != nil {
-		// 没有配置规则，不检查
-		return nil
+		facades.Log().Warningf("获取通知渠道失败: %v", err)
+		return
 	}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(rule.Config), &config); err != nil {
-		return nil
+	for _, channel := range channels {
+		if err := facades.Queue().Job(notificationJob(channel, msg)).Dispatch(); err != nil {
+			facades.Log().Errorf("分发通知渠道 %s 发送任务失败: %v", channel.Name, err)
+		}
 	}
+}
 
-	enabled, _ := config["enabled"].(bool)
-	if !enabled {
-		return nil
+// GetServerNotificationChannels 获取服务器按类型是否接收通知，兼容按类型开关的旧接口
+func (s *AlertService) GetServerNotificationChannels(serverID string) (map[string]bool, error) {
+	subscriptions, err := s.GetNotificationSubscriptions(RuleScope{ServerID: &serverID})
+	if err != nil {
+		return nil, err
 	}
 
-	threshold, ok := config["threshold"].(float64)
-	if !ok {
-		return nil
+	result := make(map[string]bool)
+	for _, subscription := range subscriptions {
+		result[subscription.Type] = result[subscription.Type] || subscription.Enabled
 	}
 
-	if currentMbps >= threshold {
-		// 触发告警
-		serverRepo := repositories.GetServerRepository()
-		server, err := serverRepo.GetByID(serverID)
-		serverName := serverID
-		serverIP := "未知"
-		if err == nil && server != nil {
-			serverName = server.Name
-			serverIP = server.IP
-		}
+	return result, nil
+}
 
-		title := fmt.Sprintf("[告警] %s - 带宽峰值", serverName)
-		webhookMessage := fmt.Sprintf("🚨 带宽峰值告警\n\n服务器: %s (%s)\n当前带宽: %.2f Mbps\n阈值: %.2f Mbps\n触发时间: %s",
-			serverName, serverIP, currentMbps, threshold, time.Now().Format("2006-01-02 15:04:05"))
+// SaveServerNotificationChannels 按类型订阅或退订该类型的所有通知渠道，兼容按类型开关的旧接口
+func (s *AlertService) SaveServerNotificationChannels(serverID string, channels map[string]bool) error {
+	notifications, err := repositories.GetAlertNotificationRepository().GetAll()
+	if err != nil {
+		return err
+	}
+	existing, err := repositories.GetNotificationSubscriptionRepository().GetByServerID(serverID)
+	if err != nil {
+		return err
+	}
 
-		// 检查冷却期
-		cacheKey := fmt.Sprintf("alert_cooldown:%s:bandwidth", serverID)
-		if cooldown := facades.Cache().Get(cacheKey); cooldown != nil {
-			return nil
-		}
-		facades.Cache().Put(cacheKey, true, 2*time.Minute)
-
-		// 发送通知
-		emailConfig, webhookConfig, _ := s.getNotificationConfigs(serverID)
-		if emailConfig.Enabled {
-			configJson, _ := json.Marshal(emailConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "email",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
-		}
-		if webhookConfig.Enabled {
-			configJson, _ := json.Marshal(webhookConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "webhook",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
+	subscriptions := make(map[uint]bool)
+	for _, subscription := range existing {
+		subscriptions[subscription.ChannelID] = subscription.Enabled
+	}
+	for _, notification := range notifications {
+		if enabled, ok := channels[notification.NotificationType]; ok {
+			subscriptions[notification.ID] = enabled
 		}
 	}
 
-	return nil
+	return repositories.GetNotificationSubscriptionRepository().Replace(&serverID, nil, subscriptions)
 }
 
-// CheckTraffic 检查流量耗尽告警
-func (s *AlertService) CheckTraffic(serverID string, usedBytes int64, limitBytes int64) error {
-	if limitBytes <= 0 {
-		// 无限制，不检查
-		return nil
+// checkBandwidth 检查带宽峰值告警，rule 为服务器生效的 bandwidth 规则
+func (s *AlertService) checkBandwidth(serverID string, rule *models.ServerAlertRule, currentMbps float64) error {
+	var config map[string]interface{}
+	if rule != nil {
+		_ = json.Unmarshal([]byte(rule.Config), &config)
 	}
-
-	serverIDPtr := &serverID
-	ruleRepo := repositories.GetServerAlertRuleRepository()
-
-	// 获取服务器特定规则
-	rule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "traffic")
-	if err != nil {
-		// 没有配置规则，不检查
+	enabled, _ := config["enabled"].(bool)
+	threshold, ok := config["threshold"].(float64)
+	if !enabled || !ok {
+		// 没有配置或停用规则时结束进行中的告警
+		if s.getAlertState(serverID, "bandwidth") != AlertStateNormal {
+			s.clearAlertState(serverID, "bandwidth", "system")
+		}
 		return nil
 	}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(rule.Config), &config); err != nil {
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     "bandwidth",
+		MetricLabel: "带宽峰值",
+		Unit:        "Mbps",
+		Value:       currentMbps,
+		Threshold:   threshold,
+		Severity:    "警告",
+		RuleID:      &rule.ID,
+	}
+	cacheKey := fmt.Sprintf("alert_cooldown:%s:bandwidth", serverID)
+	if currentMbps < threshold {
+		// 带宽回落到阈值以下时结束告警并清除冷却记录，再次触发时立即通知
+		event.IsRecovery = true
+		s.recordStateChange(event, AlertStateNormal)
+		cooldown.Reset(cacheKey)
 		return nil
 	}
 
-	enabled, _ := config["enabled"].(bool)
-	if !enabled {
+	// 静默期内告警照常记录，但不发送通知
+	s.recordStateChange(event, AlertStateWarning)
+	if s.isSilenced(serverID, "bandwidth", nil) {
 		return nil
 	}
 
-	thresholdPercent, ok := config["threshold_percent"].(float64)
-	if !ok {
+	// 检查冷却期
+	if !cooldown.Allow(cacheKey, cooldown.FromConfig(config), defaultRepeatInterval, time.Now()) {
 		return nil
 	}
 
-	usedPercent := float64(usedBytes) / float64(limitBytes) * 100
-	if usedPercent >= thresholdPercent {
-		// 触发告警
-		serverRepo := repositories.GetServerRepository()
-		server, err := serverRepo.GetByID(serverID)
-		serverName := serverID
-		serverIP := "未知"
-		if err == nil && server != nil {
-			serverName = server.Name
-			serverIP = server.IP
-		}
+	data := s.notificationData(serverID)
+	data.MetricLabel = event.MetricLabel
+	data.Severity = event.Severity
+	data.StatusText = event.Severity
+	data.CurrentValue = currentMbps
+	data.Threshold = threshold
+	data.Unit = event.Unit
+	msg := s.renderNotification(notifytemplate.EventBandwidth, data)
 
-		usedGB := float64(usedBytes) / (1024 * 1024 * 1024)
-		limitGB := float64(limitBytes) / (1024 * 1024 * 1024)
+	// 按升级策略或服务器通知配置发送
+	s.deliver(event, msg)
 
-		title := fmt.Sprintf("[告警] %s - 流量耗尽", serverName)
-		webhookMessage := fmt.Sprintf("🚨 流量耗尽告警\n\n服务器: %s (%s)\n已用流量: %.2f GB / %.2f GB (%.2f%%)\n阈值: %.2f%%\n触发时间: %s",
-			serverName, serverIP, usedGB, limitGB, usedPercent, thresholdPercent, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
 
-		// 检查冷却期
-		cacheKey := fmt.Sprintf("alert_cooldown:%s:traffic", serverID)
-		if cooldown := facades.Cache().Get(cacheKey); cooldown != nil {
-			return nil
-		}
-		facades.Cache().Put(cacheKey, true, 2*time.Minute)
-
-		// 发送通知
-		emailConfig, webhookConfig, _ := s.getNotificationConfigs(serverID)
-		if emailConfig.Enabled {
-			configJson, _ := json.Marshal(emailConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "email",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
+// CheckExpirations 检查所有服务器的到期告警，由定时任务每天执行
+func (s *AlertService) CheckExpirations() error {
+	facades.Log().Info("开始检查服务器到期告警")
+
+	servers, err := repositories.GetServerRepository().GetAll()
+	if err != nil {
+		facades.Log().Errorf("获取服务器列表失败: %v", err)
+		return err
+	}
+	for _, server := range servers {
+		// 清除到期时间的服务器仍需检查，以结束进行中的告警
+		if server.ExpireTime == nil && s.getAlertState(server.ID, "expiration") == AlertStateNormal {
+			continue
 		}
-		if webhookConfig.Enabled {
-			configJson, _ := json.Marshal(webhookConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "webhook",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
+		if err := s.CheckExpiration(server.ID); err != nil {
+			facades.Log().Warningf("检查服务器 %s 到期告警失败: %v", server.ID, err)
 		}
 	}
 
+	facades.Log().Info("服务器到期告警检查完成")
 	return nil
 }
 
@@ -632,33 +845,23 @@ func (s *AlertService) CheckExpiration(serverID string) error {
 		return nil
 	}
 
-	if server.ExpireTime == nil {
-		// 无到期时间，不检查
-		return nil
-	}
-
-	serverIDPtr := &serverID
-	ruleRepo := repositories.GetServerAlertRuleRepository()
-
-	// 获取服务器特定规则
-	rule, err := ruleRepo.GetByServerIDAndType(serverIDPtr, "expiration")
+	// 获取生效的规则（服务器 -> 分组 -> 全局）
+	rule, err := repositories.GetServerAlertRuleRepository().GetEffectiveByType(serverID, "expiration")
 	if err != nil {
-		// 没有配置规则，不检查
 		return nil
 	}
 
 	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(rule.Config), &config); err != nil {
-		return nil
+	if rule != nil {
+		_ = json.Unmarshal([]byte(rule.Config), &config)
 	}
-
 	enabled, _ := config["enabled"].(bool)
-	if !enabled {
-		return nil
-	}
-
 	alertDays, ok := config["alert_days"].(float64)
-	if !ok {
+	if !enabled || !ok || server.ExpireTime == nil {
+		// 没有配置或停用规则、清除到期时间时结束进行中的告警
+		if s.getAlertState(serverID, "expiration") != AlertStateNormal {
+			s.clearAlertState(serverID, "expiration", "system")
+		}
 		return nil
 	}
 
@@ -666,94 +869,118 @@ func (s *AlertService) CheckExpiration(serverID string) error {
 	expireTime := *server.ExpireTime
 	daysUntilExpire := expireTime.Sub(now).Hours() / 24
 
-	if daysUntilExpire <= alertDays && daysUntilExpire >= 0 {
-		// 触发告警
-		title := fmt.Sprintf("[告警] %s - 即将到期", server.Name)
-		webhookMessage := fmt.Sprintf("🚨 服务器到期提醒\n\n服务器: %s (%s)\n到期时间: %s\n剩余天数: %.0f 天\n触发时间: %s",
-			server.Name, server.IP, expireTime.Format("2006-01-02 15:04:05"), daysUntilExpire, now.Format("2006-01-02 15:04:05"))
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     "expiration",
+		MetricLabel: "即将到期",
+		Unit:        "天",
+		Value:       daysUntilExpire,
+		Threshold:   alertDays,
+		Severity:    "警告",
+		RuleID:      &rule.ID,
+	}
+	cacheKey := fmt.Sprintf("alert_cooldown:%s:expiration", serverID)
+	if daysUntilExpire > alertDays || daysUntilExpire < 0 {
+		// 不在提醒范围内时结束告警并清除冷却记录，再次进入时立即通知
+		event.IsRecovery = true
+		s.recordStateChange(event, AlertStateNormal)
+		cooldown.Reset(cacheKey)
+		return nil
+	}
 
-		// 检查冷却期（每天只发送一次）
-		cacheKey := fmt.Sprintf("alert_cooldown:%s:expiration", serverID)
-		if cooldown := facades.Cache().Get(cacheKey); cooldown != nil {
-			return nil
-		}
-		facades.Cache().Put(cacheKey, true, 24*time.Hour)
-
-		// 发送通知
-		emailConfig, webhookConfig, _ := s.getNotificationConfigs(serverID)
-		if emailConfig.Enabled {
-			configJson, _ := json.Marshal(emailConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "email",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
-		}
-		if webhookConfig.Enabled {
-			configJson, _ := json.Marshal(webhookConfig)
-			_ = facades.Queue().Job(&jobs.SendAlertJob{
-				Channel: "webhook",
-				Config:  string(configJson),
-				Subject: title,
-				Content: webhookMessage,
-			}).Dispatch()
-		}
+	// 静默期内告警照常记录，但不发送通知
+	s.recordStateChange(event, AlertStateWarning)
+	if s.isSilenced(serverID, "expiration", nil) {
+		return nil
+	}
+
+	// 检查冷却期（默认每天只发送一次）
+	if !cooldown.Allow(cacheKey, cooldown.FromConfig(config), 24*time.Hour, now) {
+		return nil
 	}
 
+	data := s.notificationData(serverID)
+	data.MetricLabel = event.MetricLabel
+	data.Severity = event.Severity
+	data.StatusText = event.Severity
+	data.CurrentValue = daysUntilExpire
+	data.Threshold = alertDays
+	data.Unit = event.Unit
+	data.Extra["ExpireTime"] = expireTime.Format("2006-01-02 15:04:05")
+	msg := s.renderNotification(notifytemplate.EventExpiration, data)
+
+	// 按升级策略或服务器通知配置发送
+	s.deliver(event, msg)
+
 	return nil
 }
 
 // NotifyServerOffline 发送服务器离线告警
 func (s *AlertService) NotifyServerOffline(serverID string) {
-	if !utils.GetSettingBool("alert_server_offline_enabled", false) {
-		return
+	// 无论是否开启通知都记录离线告警
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     serverOfflineRuleKey,
+		MetricLabel: "服务器离线",
+		Severity:    "严重",
+		Message:     "服务器与面板断开连接，请检查该服务器或 Agent 状态",
 	}
-	cacheKey := fmt.Sprintf("alert_cooldown:%s:server_offline", serverID)
-	if facades.Cache().Get(cacheKey) != nil {
+	s.recordAlert(event, AlertStateCritical)
+
+	if !utils.GetSettingBool("alert_server_offline_enabled", false) || s.isSilenced(serverID, serverOfflineRuleKey, nil) {
 		return
 	}
-	_ = facades.Cache().Put(cacheKey, true, 5*time.Minute)
-
-	emailConfig, webhookConfig, err := s.getNotificationConfigs(serverID)
-	if err != nil || (!emailConfig.Enabled && !webhookConfig.Enabled) {
+	// 频繁断线重连时，之后的离线通知视为同一告警的重复通知，按规则的重复通知配置发送
+	// 服务器保持在线超过重复通知间隔后再次离线，视为新的告警
+	now := time.Now()
+	opts := s.offlineCooldownOptions(serverID)
+	cooldownKey := fmt.Sprintf("alert_cooldown:%s:server_offline", serverID)
+	onlineAt := facades.Cache().GetInt64(fmt.Sprintf("server_online_at:%s", serverID))
+	if now.Sub(time.Unix(onlineAt, 0)) >= opts.Interval(defaultOfflineRepeatInterval) {
+		cooldown.Reset(cooldownKey)
+	}
+	if !cooldown.Allow(cooldownKey, opts, defaultOfflineRepeatInterval, now) {
 		return
 	}
 
-	serverRepo := repositories.GetServerRepository()
-	server, _ := serverRepo.GetByID(serverID)
-	serverName, serverIP := serverID, "未知"
-	if server != nil {
-		serverName, serverIP = server.Name, server.IP
-	}
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	title := fmt.Sprintf("[告警] %s - 服务器离线", serverName)
-	content := fmt.Sprintf("🚨 服务器离线告警\n\n服务器: %s (%s)\n离线时间: %s\n\n请检查该服务器或 Agent 状态。",
-		serverName, serverIP, timestamp)
-
-	if emailConfig.Enabled {
-		configJson, _ := json.Marshal(emailConfig)
-		_ = facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "email",
-			Config:  string(configJson),
-			Subject: title,
-			Content: content,
-		}).Dispatch()
-	}
-	if webhookConfig.Enabled {
-		configJson, _ := json.Marshal(webhookConfig)
-		_ = facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "webhook",
-			Config:  string(configJson),
-			Subject: title,
-			Content: content,
-		}).Dispatch()
+	data := s.notificationData(serverID)
+	data.MetricLabel = event.MetricLabel
+	data.Severity = event.Severity
+	data.StatusText = event.Severity
+	data.Color = "#ff4d4f"
+	data.Detail = event.Message
+	msg := s.renderNotification(notifytemplate.EventServerOffline, data)
+
+	s.deliver(event, msg)
+}
+
+// offlineCooldownOptions 获取服务器生效的离线告警重复通知配置
+func (s *AlertService) offlineCooldownOptions(serverID string) cooldown.Options {
+	rule, err := repositories.GetServerAlertRuleRepository().GetEffectiveByType(serverID, "server_offline")
+	if err != nil || rule == nil {
+		return cooldown.Options{}
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(rule.Config), &config); err != nil {
+		return cooldown.Options{}
 	}
+	return cooldown.FromConfig(config)
 }
 
 // NotifyServerOnline 发送服务器上线告警（由连接管理器在服务器从离线恢复上线时调用）
 func (s *AlertService) NotifyServerOnline(serverID string) {
-	if !utils.GetSettingBool("alert_server_online_enabled", false) {
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     serverOfflineRuleKey,
+		MetricLabel: "服务器离线",
+		IsRecovery:  true,
+	}
+	event.Alert = s.resolveAlert(serverID, serverOfflineRuleKey, "auto")
+	// 记录上线时间，再次离线时用于判断是否为频繁断线重连
+	_ = facades.Cache().Forever(fmt.Sprintf("server_online_at:%s", serverID), time.Now().Unix())
+
+	// 上线通知与离线告警共用静默规则
+	if !utils.GetSettingBool("alert_server_online_enabled", false) || s.isSilenced(serverID, serverOfflineRuleKey, nil) {
 		return
 	}
 	cacheKey := fmt.Sprintf("alert_cooldown:%s:server_online", serverID)
@@ -762,38 +989,12 @@ func (s *AlertService) NotifyServerOnline(serverID string) {
 	}
 	_ = facades.Cache().Put(cacheKey, true, 2*time.Minute)
 
-	emailConfig, webhookConfig, err := s.getNotificationConfigs(serverID)
-	if err != nil || (!emailConfig.Enabled && !webhookConfig.Enabled) {
-		return
-	}
+	data := s.notificationData(serverID)
+	data.MetricLabel = event.MetricLabel
+	data.StatusText = "恢复正常"
+	data.IsRecovery = true
+	data.Color = "#52c41a"
+	msg := s.renderNotification(notifytemplate.EventServerOnline, data)
 
-	serverRepo := repositories.GetServerRepository()
-	server, _ := serverRepo.GetByID(serverID)
-	serverName, serverIP := serverID, "未知"
-	if server != nil {
-		serverName, serverIP = server.Name, server.IP
-	}
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	title := fmt.Sprintf("[恢复] %s - 服务器已上线", serverName)
-	content := fmt.Sprintf("✅ 服务器已上线\n\n服务器: %s (%s)\n上线时间: %s",
-		serverName, serverIP, timestamp)
-
-	if emailConfig.Enabled {
-		configJson, _ := json.Marshal(emailConfig)
-		_ = facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "email",
-			Config:  string(configJson),
-			Subject: title,
-			Content: content,
-		}).Dispatch()
-	}
-	if webhookConfig.Enabled {
-		configJson, _ := json.Marshal(webhookConfig)
-		_ = facades.Queue().Job(&jobs.SendAlertJob{
-			Channel: "webhook",
-			Config:  string(configJson),
-			Subject: title,
-			Content: content,
-		}).Dispatch()
-	}
+	s.deliver(event, msg)
 }
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+// newWebhookChannel 创建指向本地 Webhook 的默认通知渠道，返回收到的请求数
+func newWebhookChannel(t *testing.T, alertService *services.AlertService) *atomic.Int32 {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	if _, err := alertService.CreateNotificationChannel(services.NotificationChannelOptions{
+		Name:      "webhook",
+		Type:      "webhook",
+		Enabled:   true,
+		IsDefault: true,
+		Config:    map[string]interface{}{"enabled": true, "webhook": server.URL, "platform": "generic"},
+	}); err != nil {
+		t.Fatalf("创建通知渠道失败: %v", err)
+	}
+	// 不合并告警，通知立即发送
+	if err := repositories.GetSystemSettingRepository().SetValue("alert_group_window", "0"); err != nil {
+		t.Fatal(err)
+	}
+	return &requests
+}
+
+func TestCheckExpirationsSilenced(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	requests := newWebhookChannel(t, alertService)
+
+	serverID := "expiring-server"
+	expireTime := time.Now().Add(72 * time.Hour)
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "node", IP: "10.0.0.9", AgentKey: "key", Status: "online", ExpireTime: &expireTime}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{}, map[string]interface{}{
+		"expiration": map[string]interface{}{"enabled": true, "alert_days": 7.0},
+	}); err != nil {
+		t.Fatalf("保存到期规则失败: %v", err)
+	}
+
+	startsAt, endsAt := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	silence, err := alertService.CreateSilence(services.SilenceOptions{Name: "维护", ServerID: &serverID, StartsAt: &startsAt, EndsAt: &endsAt}, "admin")
+	if err != nil {
+		t.Fatalf("创建静默规则失败: %v", err)
+	}
+
+	if err := alertService.CheckExpirations(); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Fatalf("静默期内发送了 %d 条到期通知", got)
+	}
+	// 静默期内告警照常记录
+	if alert, err := repositories.GetAlertRepository().GetFiring(serverID, "expiration"); err != nil || alert == nil || alert.Type != "threshold" {
+		t.Fatalf("静默期内应记录到期告警，得到 %+v (%v)", alert, err)
+	}
+
+	// 静默结束后立即通知，静默期间不消耗冷却期
+	if err := repositories.GetAlertSilenceRepository().Delete(silence.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := alertService.CheckExpirations(); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("静默结束后发送了 %d 条到期通知，期望 1", got)
+	}
+}
+
+func TestCheckAndAlertBandwidthSilenced(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	requests := newWebhookChannel(t, alertService)
+
+	serverID := "bandwidth-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "edge-1", IP: "10.0.0.12", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{ServerID: &serverID}, map[string]interface{}{
+		"bandwidth": map[string]interface{}{"enabled": true, "threshold": 100.0},
+	}); err != nil {
+		t.Fatalf("保存带宽规则失败: %v", err)
+	}
+	// 下载速率 20MB/s，即 160Mbps，超过 100Mbps 的阈值
+	metrics := map[string]interface{}{"cpu_usage": 10.0, "net_bytes_sent_rate": 1e6, "net_bytes_recv_rate": 20e6}
+
+	startsAt, endsAt := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	silence, err := alertService.CreateSilence(services.SilenceOptions{Name: "压测", ServerID: &serverID, RuleType: "bandwidth", StartsAt: &startsAt, EndsAt: &endsAt}, "admin")
+	if err != nil {
+		t.Fatalf("创建静默规则失败: %v", err)
+	}
+
+	if err := alertService.CheckAndAlert(serverID, metrics); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Fatalf("静默期内发送了 %d 条带宽告警", got)
+	}
+
+	// 静默结束后，上报的指标超过阈值时发送通知
+	if err := repositories.GetAlertSilenceRepository().Delete(silence.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := alertService.CheckAndAlert(serverID, metrics); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("静默结束后发送了 %d 条带宽告警，期望 1", got)
+	}
+}
+
+func TestCheckAndAlertBandwidthRecordsAlert(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	requests := newWebhookChannel(t, alertService)
+
+	serverID := "bandwidth-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "edge-1", IP: "10.0.0.12", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{ServerID: &serverID}, map[string]interface{}{
+		"bandwidth": map[string]interface{}{"enabled": true, "threshold": 100.0},
+	}); err != nil {
+		t.Fatalf("保存带宽规则失败: %v", err)
+	}
+	alertRepo := repositories.GetAlertRepository()
+
+	// 160Mbps 超过阈值，持续超过阈值时不重复创建告警
+	for i := 0; i < 2; i++ {
+		if err := alertService.CheckAndAlert(serverID, map[string]interface{}{"net_bytes_sent_rate": 1e6, "net_bytes_recv_rate": 20e6}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	firing, err := alertRepo.GetFiring(serverID, "bandwidth")
+	if err != nil || firing == nil {
+		t.Fatalf("带宽超过阈值时应记录告警: %v", err)
+	}
+	if firing.MetricValue == nil || *firing.MetricValue != 160 || firing.Threshold == nil || *firing.Threshold != 100 || firing.RuleID == nil {
+		t.Errorf("告警记录为 %+v", firing)
+	}
+	if _, total, err := alertRepo.List(repositories.AlertFilter{ServerID: serverID}); err != nil || total != 1 {
+		t.Errorf("告警记录数为 %d (%v)，期望 1", total, err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("发送了 %d 条带宽告警，期望 1", got)
+	}
+
+	// 回落到阈值以下时结束告警
+	if err := alertService.CheckAndAlert(serverID, map[string]interface{}{"net_bytes_sent_rate": 1e6, "net_bytes_recv_rate": 2e6}); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := alertRepo.GetByID(firing.ID)
+	if err != nil || resolved == nil || resolved.Status != models.AlertStatusResolved || resolved.ResolvedBy != "auto" {
+		t.Fatalf("带宽回落后告警应自动恢复，得到 %+v (%v)", resolved, err)
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+
+	"github.com/goravel/framework/facades"
+	"github.com/robfig/cron/v3"
+)
+
+// SilenceOptions 创建或更新静默规则的参数
+type SilenceOptions struct {
+	Name     string
+	Comment  string
+	ServerID *string
+	GroupID  *uint
+	RuleType string
+	Labels   map[string]string
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Cron     string
+	Duration int // 周期窗口时长(分钟)
+}
+
+// validate 校验静默规则参数：一次性静默需指定开始和结束时间，周期静默需指定 cron 表达式和时长
+func (o *SilenceOptions) validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("静默名称不能为空")
+	}
+	if o.StartsAt != nil && o.EndsAt != nil && !o.EndsAt.After(*o.StartsAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	if o.Cron != "" {
+		if _, err := cron.ParseStandard(o.Cron); err != nil {
+			return errors.New("cron 表达式无效: " + err.Error())
+		}
+		if o.Duration <= 0 {
+			return errors.New("周期维护窗口时长必须大于0")
+		}
+		return nil
+	}
+	if o.StartsAt == nil || o.EndsAt == nil {
+		return errors.New("请指定开始和结束时间，或设置周期维护窗口")
+	}
+	return nil
+}
+
+// apply 将参数写入静默规则
+func (o *SilenceOptions) apply(silence *models.AlertSilence) {
+	silence.Name = o.Name
+	silence.Comment = o.Comment
+	silence.ServerID = o.ServerID
+	silence.GroupID = o.GroupID
+	silence.RuleType = o.RuleType
+	silence.Labels = ""
+	if len(o.Labels) > 0 {
+		labelsJson, _ := json.Marshal(o.Labels)
+		silence.Labels = string(labelsJson)
+	}
+	silence.StartsAt = o.StartsAt
+	silence.EndsAt = o.EndsAt
+	silence.Cron = o.Cron
+	silence.Duration = o.Duration
+	if silence.Cron == "" {
+		silence.Duration = 0
+	}
+}
+
+// CreateSilence 创建静默规则
+func (s *AlertService) CreateSilence(options SilenceOptions, createdBy string) (*models.AlertSilence, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	silence := &models.AlertSilence{CreatedBy: createdBy}
+	options.apply(silence)
+	if err := repositories.GetAlertSilenceRepository().Create(silence); err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// UpdateSilence 更新静默规则
+func (s *AlertService) UpdateSilence(id uint, options SilenceOptions) (*models.AlertSilence, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	silenceRepo := repositories.GetAlertSilenceRepository()
+	silence, err := silenceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if silence == nil {
+		return nil, errors.New("静默规则不存在")
+	}
+
+	options.apply(silence)
+	if err := silenceRepo.Update(silence); err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// IsSilenceActive 判断静默规则在指定时间是否生效
+func IsSilenceActive(silence *models.AlertSilence, now time.Time) bool {
+	return silenceWindowEnd(silence, now) != nil
+}
+
+// silenceWindowEnd 返回当前所处静默窗口的结束时间，未生效时返回 nil
+func silenceWindowEnd(silence *models.AlertSilence, now time.Time) *time.Time {
+	if silence.StartsAt != nil && now.Before(*silence.StartsAt) {
+		return nil
+	}
+	if silence.EndsAt != nil && !now.Before(*silence.EndsAt) {
+		return nil
+	}
+	if silence.Cron == "" {
+		return silence.EndsAt
+	}
+
+	schedule, err := cron.ParseStandard(silence.Cron)
+	if err != nil || silence.Duration <= 0 {
+		return nil
+	}
+	// 窗口开始时间落在 (now-时长, now] 内即处于维护窗口
+	window := time.Duration(silence.Duration) * time.Minute
+	start := schedule.Next(now.Add(-window))
+	if start.After(now) {
+		return nil
+	}
+	end := start.Add(window)
+	if silence.EndsAt != nil && silence.EndsAt.Before(end) {
+		end = *silence.EndsAt
+	}
+	return &end
+}
+
+// silenceMatches 判断静默规则是否匹配告警
+// ruleKey 为告警状态标识，rule_type 既可匹配完整标识（如 gpu_util:0），也可匹配基础类型（如 gpu_util、expression）
+func silenceMatches(silence *models.AlertSilence, serverID string, groupID *uint, ruleKey string, labels map[string]string) bool {
+	if silence.ServerID != nil && *silence.ServerID != serverID {
+		return false
+	}
+	if silence.GroupID != nil && (groupID == nil || *groupID != *silence.GroupID) {
+		return false
+	}
+	if silence.RuleType != "" {
+		baseType, _, _ := strings.Cut(ruleKey, ":")
+		if silence.RuleType != ruleKey && silence.RuleType != baseType {
+			return false
+		}
+	}
+	if silence.Labels != "" {
+		var matchers map[string]string
+		if err := json.Unmarshal([]byte(silence.Labels), &matchers); err != nil {
+			return false
+		}
+		for key, value := range matchers {
+			if labels[key] != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GetActiveSilences 获取当前生效的静默规则
+func (s *AlertService) GetActiveSilences(now time.Time) []*models.AlertSilence {
+	silences, err := repositories.GetAlertSilenceRepository().GetAll()
+	if err != nil {
+		facades.Log().Warningf("获取静默规则失败: %v", err)
+		return nil
+	}
+
+	active := make([]*models.AlertSilence, 0)
+	for _, silence := range silences {
+		if IsSilenceActive(silence, now) {
+			active = append(active, silence)
+		}
+	}
+	return active
+}
+
+// GetActiveSilence 获取与告警匹配的生效中静默规则，没有时返回 nil
+func (s *AlertService) GetActiveSilence(serverID, ruleKey string, labels map[string]string) *models.AlertSilence {
+	silences := s.GetActiveSilences(time.Now())
+	if len(silences) == 0 {
+		return nil
+	}
+
+	var groupID *uint
+	if server, err := repositories.GetServerRepository().GetByID(serverID); err == nil && server != nil {
+		groupID = server.GroupID
+	}
+	for _, silence := range silences {
+		if silenceMatches(silence, serverID, groupID, ruleKey, labels) {
+			return silence
+		}
+	}
+	return nil
+}
+
+// isSilenced 判断告警是否处于静默期，静默时记录日志
+func (s *AlertService) isSilenced(serverID, ruleKey string, labels map[string]string) bool {
+	silence := s.GetActiveSilence(serverID, ruleKey, labels)
+	if silence == nil {
+		return false
+	}
+	facades.Log().Infof("告警处于静默期，跳过通知: server_id=%s, rule=%s, silence=%s", serverID, ruleKey, silence.Name)
+	return true
+}
+
+// FindMaintenanceSilence 从生效的静默规则中查找覆盖整台服务器的规则（不限规则类型和标签），即服务器处于维护中
+func FindMaintenanceSilence(silences []*models.AlertSilence, serverID string, groupID *uint) *models.AlertSilence {
+	for _, silence := range silences {
+		if silence.RuleType != "" || silence.Labels != "" {
+			continue
+		}
+		if silenceMatches(silence, serverID, groupID, "", nil) {
+			return silence
+		}
+	}
+	return nil
+}
+
+// MaintenanceInfo 服务器维护信息，用于在服务器列表和详情中展示
+func MaintenanceInfo(silence *models.AlertSilence, now time.Time) map[string]interface{} {
+	info := map[string]interface{}{
+		"id":      silence.ID,
+		"name":    silence.Name,
+		"comment": silence.Comment,
+	}
+	if end := silenceWindowEnd(silence, now); end != nil {
+		info["ends_at"] = end.Format("2006-01-02 15:04:05")
+	}
+	return info
+}
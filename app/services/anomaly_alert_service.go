@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"goravel/app/jobs"
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/cooldown"
+	"goravel/app/utils/expression"
+
+	"github.com/goravel/framework/facades"
+)
+
+// AnomalyRuleKeyPrefix 异常检测告警的状态标识前缀，如 anomaly:cpu
+const AnomalyRuleKeyPrefix = "anomaly:"
+
+// anomalyMetricFields 异常检测指标对应的上报字段
+var anomalyMetricFields = map[string]string{
+	"cpu":    "cpu_usage",
+	"memory": "memory_usage",
+	"disk":   "disk_usage",
+}
+
+// AnomalyRule 异常检测规则：指标持续偏离该服务器同一时段（一周中的同一小时）的历史基线时告警
+type AnomalyRule struct {
+	Enabled      bool     `json:"enabled"`
+	Metrics      []string `json:"metrics"`       // 检测的指标：cpu、memory、disk
+	Sensitivity  float64  `json:"sensitivity"`   // 正常范围为均值上下若干倍标准差
+	MinDeviation float64  `json:"min_deviation"` // 正常范围的最小半宽（百分点），避免波动很小的指标误报
+	MinSamples   int      `json:"min_samples"`   // 基线至少需要的样本数，不足时不检测
+	For          string   `json:"for"`           // 偏离需持续的时间
+	Severity     string   `json:"severity"`      // warning 或 critical
+	cooldown.Options
+	// 规则所在的规则记录，使用默认规则时为空
+	RuleID *uint `json:"-"`
+}
+
+// defaultAnomalyRule 异常检测规则的默认配置
+func defaultAnomalyRule() AnomalyRule {
+	return AnomalyRule{
+		Metrics:      []string{"cpu", "memory"},
+		Sensitivity:  3,
+		MinDeviation: 10,
+		MinSamples:   30,
+		For:          "10m",
+		Severity:     string(AlertStateWarning),
+	}
+}
+
+// parseAnomalyRule 解析异常检测规则，未配置的字段使用默认值
+func parseAnomalyRule(config string) (AnomalyRule, error) {
+	rule := defaultAnomalyRule()
+	if err := json.Unmarshal([]byte(config), &rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// Validate 校验异常检测规则
+func (r AnomalyRule) Validate() error {
+	if len(r.Metrics) == 0 {
+		return errors.New("至少需要检测一个指标")
+	}
+	for _, metric := range r.Metrics {
+		if _, ok := anomalyMetricFields[metric]; !ok {
+			return fmt.Errorf("不支持检测指标 %s", metric)
+		}
+	}
+	if r.Sensitivity <= 0 {
+		return errors.New("灵敏度必须大于0")
+	}
+	if r.MinDeviation < 0 {
+		return errors.New("最小偏离幅度不能为负数")
+	}
+	if r.MinSamples < 0 {
+		return errors.New("最少样本数不能为负数")
+	}
+	if _, err := expression.ParseDuration(r.For); err != nil {
+		return err
+	}
+	if r.Severity != string(AlertStateWarning) && r.Severity != string(AlertStateCritical) {
+		return errors.New("告警级别只能为 warning 或 critical")
+	}
+	return r.Options.Validate()
+}
+
+// Band 根据基线计算指标的正常范围，基线样本不足时返回 false
+func (r AnomalyRule) Band(baseline *models.ServerMetricBaseline) (float64, float64, bool) {
+	if baseline == nil || baseline.Samples == 0 || baseline.Samples < r.MinSamples {
+		return 0, 0, false
+	}
+	deviation := math.Max(baseline.Stddev*r.Sensitivity, r.MinDeviation)
+	return baseline.Mean - deviation, baseline.Mean + deviation, true
+}
+
+// GetAnomalyRule 获取服务器生效的异常检测规则，未配置时返回默认（未启用）规则
+func (s *AlertService) GetAnomalyRule(serverID string) AnomalyRule {
+	ruleRecords, err := s.LoadServerRules(serverID)
+	if err != nil {
+		return defaultAnomalyRule()
+	}
+	return ruleRecords.anomalyRule()
+}
+
+// anomalyRule 解析异常检测规则，未配置时返回默认（未启用）规则
+func (r ServerRules) anomalyRule() AnomalyRule {
+	ruleRecord, ok := r["anomaly"]
+	if !ok || ruleRecord == nil {
+		return defaultAnomalyRule()
+	}
+	rule, err := parseAnomalyRule(ruleRecord.Config)
+	if err != nil {
+		facades.Log().Warningf("解析异常检测规则失败: %v", err)
+		return defaultAnomalyRule()
+	}
+	rule.RuleID = r.ruleID("anomaly")
+	return rule
+}
+
+// CheckAnomalies 按服务器生效的异常检测规则，将最新指标与当前时段的基线比较，持续偏离正常范围时告警
+func (s *AlertService) CheckAnomalies(serverID string, rule AnomalyRule, metrics map[string]interface{}, now time.Time) error {
+	baselineRepo := repositories.GetServerMetricBaselineRepository()
+	hourOfWeek := jobs.HourOfWeek(now)
+	forDuration, _ := expression.ParseDuration(rule.For)
+
+	for metric, field := range anomalyMetricFields {
+		ruleKey := AnomalyRuleKeyPrefix + metric
+		if !rule.Enabled || !slices.Contains(rule.Metrics, metric) {
+			// 规则停用或不再检测该指标时结束进行中的告警
+			if s.getAlertState(serverID, ruleKey) != AlertStateNormal {
+				s.clearAlertState(serverID, ruleKey, "system")
+			}
+			continue
+		}
+		value, ok := metrics[field].(float64)
+		if !ok {
+			continue
+		}
+		baseline, err := baselineRepo.Get(serverID, metric, hourOfWeek)
+		if err != nil {
+			return err
+		}
+		low, high, ok := rule.Band(baseline)
+		if !ok {
+			// 基线不足时不判断，保持原有状态
+			continue
+		}
+
+		currentState := s.getAlertState(serverID, ruleKey)
+		newState := AlertStateNormal
+		if value < low || value > high {
+			newState = AlertState(rule.Severity)
+		}
+		newState = s.applyPending(serverID, ruleKey, currentState, newState, forDuration, now)
+
+		notify, err := s.transitionState(serverID, ruleKey, currentState, newState, rule.Options, now)
+		if err != nil {
+			return err
+		}
+		if !notify {
+			continue
+		}
+
+		metricLabel, unit := metricDisplay(metric)
+		severity := ""
+		switch newState {
+		case AlertStateCritical:
+			severity = "严重"
+		case AlertStateWarning:
+			severity = "警告"
+		}
+		threshold := high
+		if value < low {
+			threshold = low
+		}
+		event := &alertEvent{
+			ServerID:    serverID,
+			RuleKey:     ruleKey,
+			MetricLabel: metricLabel + "异常",
+			Unit:        unit,
+			Value:       value,
+			Threshold:   threshold,
+			Severity:    severity,
+			IsRecovery:  newState == AlertStateNormal,
+			Message: fmt.Sprintf("指标: %s\n当前值: %.2f%s\n该时段正常范围: %.2f%s ~ %.2f%s (历史均值 %.2f%s)",
+				metricLabel, value, unit, math.Max(low, 0), unit, high, unit, baseline.Mean, unit),
+			RuleID: rule.RuleID,
+		}
+		if newState != currentState {
+			s.recordAlert(event, newState)
+		}
+		s.sendNotification(event)
+	}
+
+	return nil
+}
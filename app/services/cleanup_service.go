@@ -75,6 +75,8 @@ func (s *CleanupService) CleanupOldData() {
 		"server_network_connections": "server_network_connections",
 		"server_network_speed":       "server_network_speed",
 		"server_cpus":                "server_cpus",
+		"server_gpu_metrics":         "server_gpu_metrics",
+		"server_sensor_readings":     "server_sensor_readings",
 		"alerts":                     "alerts",
 		"service_monitor_alerts":     "service_monitor_alerts",
 		"audit_logs":                 "audit_logs",
@@ -119,6 +121,14 @@ func (s *CleanupService) CleanupOldData() {
 		cutoffTime := time.Now().AddDate(0, 0, -keepDays)
 		cutoffTimestamp := cutoffTime.Unix()
 
+		// 告警中的记录无论开始多久都保留，只清理已恢复的告警
+		if tableName == "alerts" {
+			if err := s.cleanupResolvedAlerts(cutoffTime, keepDays); err != nil {
+				facades.Log().Errorf("清理表 %s 失败: %v", tableName, err)
+			}
+			continue
+		}
+
 		// 删除旧数据
 		result, err := facades.Orm().Query().Table(tableName).
 			Where("timestamp", "<", cutoffTimestamp).
@@ -197,9 +207,21 @@ func (s *CleanupService) CleanupServiceMonitorAlerts(retentionDays int) error {
 	return s.CleanupTableData("service_monitor_alerts", retentionDays)
 }
 
-// CleanupAlerts 清理告警记录
+// CleanupAlerts 清理已恢复的告警记录，告警中的记录不清理
 func (s *CleanupService) CleanupAlerts(retentionDays int) error {
-	return s.CleanupTableData("alerts", retentionDays)
+	return s.cleanupResolvedAlerts(time.Now().AddDate(0, 0, -retentionDays), retentionDays)
+}
+
+// cleanupResolvedAlerts 删除开始时间早于截止时间的已恢复告警
+func (s *CleanupService) cleanupResolvedAlerts(cutoffTime time.Time, keepDays int) error {
+	rowsAffected, err := repositories.GetAlertRepository().DeleteResolvedBefore(cutoffTime)
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		facades.Log().Infof("已清理表 alerts 中 %d 条超过 %d 天的已恢复告警", rowsAffected, keepDays)
+	}
+	return nil
 }
 
 // OptimizeDatabase 优化数据库
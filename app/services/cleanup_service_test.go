@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/services"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestCleanupAlertsKeepsFiring(t *testing.T) {
+	tests.NewDatabase(t)
+	if err := facades.Orm().Query().Create(&models.Server{ID: "s1", Name: "node", IP: "10.0.0.1", AgentKey: "key", Status: "online"}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	alertRepo := repositories.GetAlertRepository()
+
+	old := time.Now().AddDate(0, 0, -40)
+	alerts := []*models.Alert{
+		{ID: "old-firing", ServerID: "s1", RuleKey: "cpu", Status: models.AlertStatusFiring, Timestamp: old},
+		{ID: "old-resolved", ServerID: "s1", RuleKey: "memory", Status: models.AlertStatusResolved, Timestamp: old},
+		{ID: "new-resolved", ServerID: "s1", RuleKey: "disk", Status: models.AlertStatusResolved, Timestamp: time.Now()},
+	}
+	for _, alert := range alerts {
+		if err := alertRepo.Create(alert); err != nil {
+			t.Fatalf("创建告警失败: %v", err)
+		}
+	}
+
+	if err := services.NewCleanupService().CleanupAlerts(30); err != nil {
+		t.Fatalf("清理告警失败: %v", err)
+	}
+
+	// 告警中的记录无论开始多久都保留
+	want := map[string]bool{"old-firing": true, "old-resolved": false, "new-resolved": true}
+	for id, kept := range want {
+		alert, err := alertRepo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (alert != nil) != kept {
+			t.Errorf("告警 %s 保留为 %v，期望 %v", id, alert != nil, kept)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"goravel/app/repositories"
+	"goravel/app/utils/cooldown"
+	"goravel/app/utils/expression"
+
+	"github.com/goravel/framework/facades"
+)
+
+const (
+	// diskForecastRuleKey 磁盘写满预测告警的状态标识
+	diskForecastRuleKey = "disk_forecast"
+	// diskForecastBucket 拟合趋势时对历史数据取平均的时间段长度
+	diskForecastBucket = 10 * time.Minute
+	// diskForecastMinPoints 拟合趋势至少需要的数据点数
+	diskForecastMinPoints = 6
+	// diskForecastCheckInterval 磁盘写满预测告警的检查间隔，趋势变化缓慢，无需每次上报都检查
+	diskForecastCheckInterval = 5 * time.Minute
+)
+
+// DiskForecast 磁盘使用率的趋势预测
+type DiskForecast struct {
+	CurrentUsage float64    `json:"current_usage"`          // 按趋势拟合的当前使用率(%)
+	GrowthPerDay float64    `json:"growth_per_day"`         // 每天增长的使用率(百分点)
+	HoursToFull  *float64   `json:"hours_to_full"`          // 预计写满的剩余小时数，使用率不增长时为空
+	FullAt       *time.Time `json:"full_at"`                // 预计写满的时间
+	TimeToFull   string     `json:"time_to_full,omitempty"` // 便于展示的剩余时间，如 约3天
+	Window       string     `json:"window"`                 // 拟合使用的历史数据时长
+	Samples      int        `json:"samples"`
+}
+
+// DiskForecastRule 磁盘写满预测规则：按近期磁盘使用率的趋势预测写满时间，剩余时间低于阈值时告警
+type DiskForecastRule struct {
+	Enabled       bool    `json:"enabled"`
+	WarningHours  float64 `json:"warning_hours"`  // 预计写满时间少于该小时数时警告
+	CriticalHours float64 `json:"critical_hours"` // 预计写满时间少于该小时数时严重告警
+	Window        string  `json:"window"`         // 拟合趋势使用的历史数据时长，如 24h、7d
+	cooldown.Options
+	// 规则所在的规则记录，使用默认规则时为空
+	RuleID *uint `json:"-"`
+}
+
+// defaultDiskForecastRule 磁盘写满预测规则的默认配置
+func defaultDiskForecastRule() DiskForecastRule {
+	return DiskForecastRule{
+		WarningHours:  72,
+		CriticalHours: 24,
+		Window:        "24h",
+	}
+}
+
+// parseDiskForecastRule 解析磁盘写满预测规则，未配置的字段使用默认值
+func parseDiskForecastRule(config string) (DiskForecastRule, error) {
+	rule := defaultDiskForecastRule()
+	if err := json.Unmarshal([]byte(config), &rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// Validate 校验磁盘写满预测规则
+func (r DiskForecastRule) Validate() error {
+	if r.WarningHours <= 0 || r.CriticalHours < 0 {
+		return errors.New("预计写满时间阈值必须大于0")
+	}
+	if r.CriticalHours > r.WarningHours {
+		return errors.New("严重阈值不能大于警告阈值")
+	}
+	window, err := expression.ParseDuration(r.Window)
+	if err != nil {
+		return err
+	}
+	if window < time.Hour || window > 30*24*time.Hour {
+		return errors.New("历史数据时长需在 1h 到 30d 之间")
+	}
+	return r.Options.Validate()
+}
+
+// windowDuration 拟合趋势使用的历史数据时长
+func (r DiskForecastRule) windowDuration() time.Duration {
+	window, err := expression.ParseDuration(r.Window)
+	if err != nil || window <= 0 {
+		return 24 * time.Hour
+	}
+	return window
+}
+
+// ForecastDiskFull 对磁盘使用率序列做线性拟合，预测写满的时间，数据不足时返回 nil
+func ForecastDiskFull(points []repositories.MetricPoint, now time.Time) *DiskForecast {
+	if len(points) < diskForecastMinPoints {
+		return nil
+	}
+
+	// 最小二乘法拟合 usage = intercept + slope * hours，以第一个点为时间原点
+	origin := points[0].Timestamp
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := float64(point.Timestamp-origin) / 3600
+		n++
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	nowHours := float64(now.Unix()-origin) / 3600
+	current := math.Min(math.Max(intercept+slope*nowHours, 0), 100)
+	forecast := &DiskForecast{
+		CurrentUsage: current,
+		GrowthPerDay: slope * 24,
+		Samples:      len(points),
+	}
+	if slope <= 0 {
+		return forecast
+	}
+
+	hours := (100 - current) / slope
+	fullAt := now.Add(time.Duration(hours * float64(time.Hour)))
+	forecast.HoursToFull = &hours
+	forecast.FullAt = &fullAt
+	forecast.TimeToFull = formatApproxHours(hours)
+	return forecast
+}
+
+// formatApproxHours 将剩余小时数格式化为便于阅读的文本
+func formatApproxHours(hours float64) string {
+	switch {
+	case hours < 1:
+		return "不足1小时"
+	case hours < 48:
+		return fmt.Sprintf("约%d小时", int(math.Round(hours)))
+	default:
+		return fmt.Sprintf("约%d天", int(math.Round(hours/24)))
+	}
+}
+
+// GetDiskForecastRule 获取服务器生效的磁盘写满预测规则，未配置时返回默认（未启用）规则
+func (s *AlertService) GetDiskForecastRule(serverID string) DiskForecastRule {
+	ruleRecords, err := s.LoadServerRules(serverID)
+	if err != nil {
+		return defaultDiskForecastRule()
+	}
+	return ruleRecords.diskForecastRule()
+}
+
+// diskForecastRule 解析磁盘写满预测规则，未配置时返回默认（未启用）规则
+func (r ServerRules) diskForecastRule() DiskForecastRule {
+	ruleRecord, ok := r[diskForecastRuleKey]
+	if !ok || ruleRecord == nil {
+		return defaultDiskForecastRule()
+	}
+	rule, err := parseDiskForecastRule(ruleRecord.Config)
+	if err != nil {
+		facades.Log().Warningf("解析磁盘写满预测规则失败: %v", err)
+		return defaultDiskForecastRule()
+	}
+	rule.RuleID = r.ruleID(diskForecastRuleKey)
+	return rule
+}
+
+// GetDiskForecast 按服务器生效规则的历史数据时长预测磁盘写满时间，数据不足时返回 nil
+func (s *AlertService) GetDiskForecast(serverID string, now time.Time) (*DiskForecast, error) {
+	return s.forecastDisk(serverID, s.GetDiskForecastRule(serverID), now)
+}
+
+// forecastDisk 按规则的历史数据时长预测磁盘写满时间，数据不足时返回 nil
+func (s *AlertService) forecastDisk(serverID string, rule DiskForecastRule, now time.Time) (*DiskForecast, error) {
+	window := rule.windowDuration()
+	points, err := repositories.GetServerMetricRepository().GetDiskUsageSeries(serverID, now.Add(-window), diskForecastBucket)
+	if err != nil {
+		return nil, err
+	}
+	forecast := ForecastDiskFull(points, now)
+	if forecast != nil {
+		forecast.Window = rule.Window
+	}
+	return forecast, nil
+}
+
+// CheckDiskForecast 按服务器生效的磁盘写满预测规则检查告警，预计写满时间低于阈值时告警
+func (s *AlertService) CheckDiskForecast(serverID string, rule DiskForecastRule, now time.Time) error {
+	if !rule.Enabled {
+		// 规则停用时结束进行中的告警
+		if s.getAlertState(serverID, diskForecastRuleKey) != AlertStateNormal {
+			s.clearAlertState(serverID, diskForecastRuleKey, "system")
+		}
+		return nil
+	}
+
+	checkedKey := fmt.Sprintf("disk_forecast_checked:%s", serverID)
+	if facades.Cache().Get(checkedKey) != nil {
+		return nil
+	}
+	_ = facades.Cache().Put(checkedKey, true, diskForecastCheckInterval)
+
+	forecast, err := s.forecastDisk(serverID, rule, now)
+	if err != nil || forecast == nil {
+		// 数据不足时不判断，保持原有状态
+		return err
+	}
+
+	currentState := s.getAlertState(serverID, diskForecastRuleKey)
+	newState := AlertStateNormal
+	threshold := rule.WarningHours
+	if forecast.HoursToFull != nil {
+		switch {
+		case *forecast.HoursToFull < rule.CriticalHours:
+			newState = AlertStateCritical
+			threshold = rule.CriticalHours
+		case *forecast.HoursToFull < rule.WarningHours:
+			newState = AlertStateWarning
+		}
+	}
+
+	notify, err := s.transitionState(serverID, diskForecastRuleKey, currentState, newState, rule.Options, now)
+	if err != nil || !notify {
+		return err
+	}
+
+	severity := ""
+	switch newState {
+	case AlertStateCritical:
+		severity = "严重"
+	case AlertStateWarning:
+		severity = "警告"
+	}
+	message := fmt.Sprintf("磁盘使用率: %.2f%%\n增长速度: %.2f%%/天\n预计写满: 不会写满", forecast.CurrentUsage, forecast.GrowthPerDay)
+	hoursToFull := 0.0
+	if forecast.HoursToFull != nil {
+		hoursToFull = *forecast.HoursToFull
+		message = fmt.Sprintf("磁盘使用率: %.2f%%\n增长速度: %.2f%%/天\n预计写满: %s后 (%s)\n告警阈值: %.0f小时",
+			forecast.CurrentUsage, forecast.GrowthPerDay, forecast.TimeToFull,
+			forecast.FullAt.Format("2006-01-02 15:04"), threshold)
+	}
+
+	metricLabel, unit := metricDisplay(diskForecastRuleKey)
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     diskForecastRuleKey,
+		MetricLabel: metricLabel,
+		Unit:        unit,
+		Value:       hoursToFull,
+		Threshold:   threshold,
+		Severity:    severity,
+		IsRecovery:  newState == AlertStateNormal,
+		Message:     message,
+		RuleID:      rule.RuleID,
+	}
+	if newState != currentState {
+		s.recordAlert(event, newState)
+	}
+	s.sendNotification(event)
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package services
+
+// ExecuteSaveGPUInfo 直接执行GPU信息保存任务，不经过后台写入队列
+func ExecuteSaveGPUInfo(serverID string, data map[string]interface{}) error {
+	return (&saveGPUInfoJob{serverID: serverID, data: data}).Execute()
+}
+
+// ExecuteSaveSystemInfo 直接执行系统信息保存任务，不经过后台写入队列
+func ExecuteSaveSystemInfo(serverID string, data map[string]interface{}) error {
+	return (&saveSystemInfoJob{serverID: serverID, data: data}).Execute()
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/cooldown"
+	"goravel/app/utils/expression"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ExpressionRuleTypePrefix 表达式规则在 server_alert_rules.rule_type 中的前缀，完整类型为 expression:<key>
+const ExpressionRuleTypePrefix = "expression:"
+
+// expressionRuleKeyPattern 规则标识只允许小写字母、数字、下划线和中划线（rule_type 最长50字符）
+var expressionRuleKeyPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// ExpressionRule 表达式告警规则
+type ExpressionRule struct {
+	Key        string            `json:"key"`
+	Name       string            `json:"name"`
+	Enabled    bool              `json:"enabled"`
+	Expression string            `json:"expression"`         // 触发条件，例如 avg(cpu_usage, 5m) > 90 and memory_usage > 80
+	Recovery   string            `json:"recovery,omitempty"` // 恢复条件，为空时触发条件不成立即恢复
+	For        string            `json:"for,omitempty"`      // 触发前需持续的时间，如 5m
+	Severity   string            `json:"severity"`           // warning 或 critical
+	Labels     map[string]string `json:"labels,omitempty"`
+	// 告警升级策略，为空时使用服务器所在分组的策略
+	EscalationPolicyID *uint `json:"escalation_policy_id,omitempty"`
+	// 重复通知配置，为空时持续告警每2分钟重复通知一次
+	cooldown.Options
+	// 规则所在的规则记录
+	RuleID *uint `json:"-"`
+}
+
+// Validate 校验表达式规则
+func (r *ExpressionRule) Validate() error {
+	if !expressionRuleKeyPattern.MatchString(r.Key) {
+		return errors.New("规则标识只能包含小写字母、数字、下划线和中划线，长度不超过32")
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("规则名称不能为空")
+	}
+	if _, err := expression.Parse(r.Expression); err != nil {
+		return fmt.Errorf("触发条件无效: %v", err)
+	}
+	if r.Recovery != "" {
+		if _, err := expression.Parse(r.Recovery); err != nil {
+			return fmt.Errorf("恢复条件无效: %v", err)
+		}
+	}
+	if _, err := expression.ParseDuration(r.For); err != nil {
+		return err
+	}
+	if r.Severity != string(AlertStateWarning) && r.Severity != string(AlertStateCritical) {
+		return errors.New("告警级别只能为 warning 或 critical")
+	}
+	return r.Options.Validate()
+}
+
+// ruleType 返回规则在 server_alert_rules 中的类型
+func (r *ExpressionRule) ruleType() string {
+	return ExpressionRuleTypePrefix + r.Key
+}
+
+// GetExpressionRules 获取服务器的所有表达式规则
+// 表达式规则只能配置在服务器上，不从分组、模板和全局继承，也不受服务器 inherit 模式影响
+func (s *AlertService) GetExpressionRules(serverID string) ([]ExpressionRule, error) {
+	ruleRecords, err := repositories.GetServerAlertRuleRepository().GetByServerID(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]ExpressionRule, 0)
+	for _, ruleRecord := range ruleRecords {
+		if !strings.HasPrefix(ruleRecord.RuleType, ExpressionRuleTypePrefix) {
+			continue
+		}
+		var rule ExpressionRule
+		if err := json.Unmarshal([]byte(ruleRecord.Config), &rule); err != nil {
+			facades.Log().Warningf("解析表达式规则失败 %s: %v", ruleRecord.RuleType, err)
+			continue
+		}
+		rule.Key = strings.TrimPrefix(ruleRecord.RuleType, ExpressionRuleTypePrefix)
+		ruleID := ruleRecord.ID
+		rule.RuleID = &ruleID
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SaveExpressionRule 创建或更新服务器的表达式规则
+func (s *AlertService) SaveExpressionRule(serverID string, rule ExpressionRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	ruleJson, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	return repositories.GetServerAlertRuleRepository().CreateOrUpdate(&models.ServerAlertRule{
+		ServerID: &serverID,
+		RuleType: rule.ruleType(),
+		Config:   string(ruleJson),
+	})
+}
+
+// DeleteExpressionRule 删除服务器的表达式规则，并清除其告警状态
+func (s *AlertService) DeleteExpressionRule(serverID, key string) error {
+	ruleType := ExpressionRuleTypePrefix + key
+	if err := repositories.GetServerAlertRuleRepository().DeleteByServerIDAndType(&serverID, ruleType); err != nil {
+		return err
+	}
+
+	s.clearAlertState(serverID, ruleType, "system")
+	return nil
+}
+
+// PreviewExpression 使用服务器当前的指标数据对表达式求值，用于保存规则前预览
+func (s *AlertService) PreviewExpression(serverID, src string) (map[string]interface{}, error) {
+	expr, err := expression.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"metrics": expr.Metrics(),
+	}
+	matched, values, err := expr.Eval(GetMetricWindow().Env(serverID, time.Now()))
+	if err != nil {
+		result["error"] = err.Error()
+	} else {
+		result["matched"] = matched
+	}
+	result["values"] = values
+	return result, nil
+}
+
+// CheckExpressionRules 对服务器所有启用的表达式规则求值并触发告警
+func (s *AlertService) CheckExpressionRules(serverID string) error {
+	rules, err := s.GetExpressionRules(serverID)
+	if err != nil {
+		return err
+	}
+
+	env := GetMetricWindow().Env(serverID, time.Now())
+	for _, rule := range rules {
+		if !rule.Enabled {
+			// 规则停用时结束进行中的告警
+			if s.getAlertState(serverID, rule.ruleType()) != AlertStateNormal {
+				s.clearAlertState(serverID, rule.ruleType(), "system")
+			}
+			continue
+		}
+		if err := s.evaluateExpressionRule(serverID, rule, env); err != nil {
+			facades.Log().Warningf("表达式规则 %s 求值失败: %v", rule.Key, err)
+		}
+	}
+	return nil
+}
+
+// evaluateExpressionRule 评估单个表达式规则，状态、持续时间和冷却期与阈值规则共用
+func (s *AlertService) evaluateExpressionRule(serverID string, rule ExpressionRule, env expression.Env) error {
+	expr, err := expression.Parse(rule.Expression)
+	if err != nil {
+		return err
+	}
+
+	matched, values, err := expr.Eval(env)
+	if err != nil {
+		// 指标暂无数据时保持当前状态，等待数据上报
+		if errors.Is(err, expression.ErrNoData) {
+			return nil
+		}
+		return err
+	}
+
+	ruleType := rule.ruleType()
+	currentState := s.getAlertState(serverID, ruleType)
+
+	newState := AlertStateNormal
+	if matched {
+		newState = AlertState(rule.Severity)
+	}
+
+	// 滞回：配置了恢复条件时，告警中的规则需满足恢复条件才恢复，保持告警期间不再重复通知
+	if newState == AlertStateNormal && currentState != AlertStateNormal && rule.Recovery != "" {
+		recoveryExpr, err := expression.Parse(rule.Recovery)
+		if err != nil {
+			return err
+		}
+		recovered, recoveryValues, _ := recoveryExpr.Eval(env)
+		if !recovered {
+			return nil
+		}
+		for name, value := range recoveryValues {
+			values[name] = value
+		}
+	}
+
+	// 持续时间：从正常进入告警前需持续满足条件
+	forDuration, _ := expression.ParseDuration(rule.For)
+	newState = s.applyPending(serverID, ruleType, currentState, newState, forDuration, time.Now())
+
+	notify, err := s.transitionState(serverID, ruleType, currentState, newState, rule.Options, time.Now())
+	if err != nil || !notify {
+		return err
+	}
+
+	severity := ""
+	switch newState {
+	case AlertStateCritical:
+		severity = "严重"
+	case AlertStateWarning:
+		severity = "警告"
+	}
+	event := &alertEvent{
+		ServerID:    serverID,
+		RuleKey:     ruleType,
+		MetricLabel: rule.Name,
+		Severity:    severity,
+		IsRecovery:  newState == AlertStateNormal,
+		Expression:  rule.Expression,
+		Values:      values,
+		Labels:      rule.Labels,
+		PolicyID:    rule.EscalationPolicyID,
+		RuleID:      rule.RuleID,
+	}
+	if newState != currentState {
+		s.recordAlert(event, newState)
+	}
+	s.sendNotification(event)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"goravel/app/utils/expression"
+)
+
+// metricStaleAfter 指标最新值超过该时长未更新时视为无数据
+const metricStaleAfter = 10 * time.Minute
+
+// metricSample 单次指标采样
+type metricSample struct {
+	value float64
+	at    time.Time
+}
+
+// MetricWindow 保存各服务器最近一段时间的指标采样，供表达式告警规则求值
+type MetricWindow struct {
+	mu        sync.RWMutex
+	retention time.Duration
+	series    map[string]map[string][]metricSample // server_id -> 指标名 -> 采样
+}
+
+var (
+	globalMetricWindow *MetricWindow
+	metricWindowOnce   sync.Once
+)
+
+// GetMetricWindow 获取全局指标窗口（单例）
+func GetMetricWindow() *MetricWindow {
+	metricWindowOnce.Do(func() {
+		globalMetricWindow = &MetricWindow{
+			retention: expression.MaxWindow,
+			series:    make(map[string]map[string][]metricSample),
+		}
+	})
+	return globalMetricWindow
+}
+
+// Record 记录一组指标采样
+func (w *MetricWindow) Record(serverID string, values map[string]float64, at time.Time) {
+	if len(values) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	serverSeries, ok := w.series[serverID]
+	if !ok {
+		serverSeries = make(map[string][]metricSample)
+		w.series[serverID] = serverSeries
+	}
+
+	cutoff := at.Add(-w.retention)
+	for name, value := range values {
+		samples := append(serverSeries[name], metricSample{value: value, at: at})
+		// 丢弃超出保留时长的采样
+		start := 0
+		for start < len(samples) && samples[start].at.Before(cutoff) {
+			start++
+		}
+		serverSeries[name] = samples[start:]
+	}
+}
+
+// RecordFields 记录上报数据中的所有数值字段，指标名为 prefix.字段名
+func (w *MetricWindow) RecordFields(serverID, prefix string, data map[string]interface{}, at time.Time) {
+	values := make(map[string]float64)
+	for key, raw := range data {
+		if value, ok := raw.(float64); ok {
+			values[prefix+"."+key] = value
+		}
+	}
+	w.Record(serverID, values, at)
+}
+
+// Remove 删除服务器的所有采样
+func (w *MetricWindow) Remove(serverID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.series, serverID)
+}
+
+// Env 返回以 now 为当前时间的表达式求值环境
+func (w *MetricWindow) Env(serverID string, now time.Time) expression.Env {
+	return &metricWindowEnv{window: w, serverID: serverID, now: now}
+}
+
+// samples 获取指标在时间范围内的采样副本
+func (w *MetricWindow) samples(serverID, metric string, since, until time.Time) []metricSample {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var result []metricSample
+	for _, sample := range w.series[serverID][metric] {
+		if sample.at.Before(since) || sample.at.After(until) {
+			continue
+		}
+		result = append(result, sample)
+	}
+	return result
+}
+
+// metricWindowEnv 基于指标窗口的表达式求值环境
+type metricWindowEnv struct {
+	window   *MetricWindow
+	serverID string
+	now      time.Time
+}
+
+func (e *metricWindowEnv) Value(metric string) (float64, bool) {
+	samples := e.window.samples(e.serverID, metric, e.now.Add(-metricStaleAfter), e.now)
+	if len(samples) == 0 {
+		return 0, false
+	}
+	return samples[len(samples)-1].value, true
+}
+
+func (e *metricWindowEnv) Aggregate(fn, metric string, window time.Duration) (float64, bool) {
+	samples := e.window.samples(e.serverID, metric, e.now.Add(-window), e.now)
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	switch fn {
+	case "count":
+		return float64(len(samples)), true
+	case "sum", "avg":
+		var sum float64
+		for _, sample := range samples {
+			sum += sample.value
+		}
+		if fn == "avg" {
+			return sum / float64(len(samples)), true
+		}
+		return sum, true
+	case "min", "max":
+		result := samples[0].value
+		for _, sample := range samples[1:] {
+			if (fn == "min" && sample.value < result) || (fn == "max" && sample.value > result) {
+				result = sample.value
+			}
+		}
+		return result, true
+	}
+	return 0, false
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"goravel/app/jobs"
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/notification"
+	"goravel/app/utils/notifytemplate"
+	"goravel/app/utils/secret"
+
+	"github.com/goravel/framework/facades"
+)
+
+// notificationChannelTypes 支持的通知渠道类型
+var notificationChannelTypes = []string{"email", "webhook", "telegram", "ntfy", "gotify", "bark"}
+
+// NotificationChannelOptions 创建或更新通知渠道的参数，更新时敏感字段留空表示保持不变
+type NotificationChannelOptions struct {
+	Name      string                 `json:"name" form:"name"`
+	Type      string                 `json:"type" form:"type"`
+	Enabled   bool                   `json:"enabled" form:"enabled"`
+	IsDefault bool                   `json:"is_default" form:"is_default"`
+	Config    map[string]interface{} `json:"config" form:"config"`
+}
+
+// NotificationChannelItem 通知渠道，配置中的敏感字段以 has_<字段> 表示是否已设置
+type NotificationChannelItem struct {
+	ID        uint                   `json:"id"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Enabled   bool                   `json:"enabled"`
+	IsDefault bool                   `json:"is_default"`
+	Config    map[string]interface{} `json:"config"`
+}
+
+// NotificationSubscriptionItem 服务器或分组对通知渠道的订阅情况
+type NotificationSubscriptionItem struct {
+	ChannelID uint   `json:"channel_id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Enabled   bool   `json:"enabled"` // 是否接收该渠道的通知
+	Source    string `json:"source"`  // server、group、default
+}
+
+// validate 校验通知渠道参数
+func (o *NotificationChannelOptions) validate() error {
+	o.Name = strings.TrimSpace(o.Name)
+	if o.Name == "" {
+		return errors.New("渠道名称不能为空")
+	}
+	if len([]rune(o.Name)) > 100 {
+		return errors.New("渠道名称不能超过100个字符")
+	}
+	if !slices.Contains(notificationChannelTypes, o.Type) {
+		return errors.New("不支持的通知渠道类型")
+	}
+	if o.Config == nil {
+		return errors.New("渠道配置不能为空")
+	}
+	return nil
+}
+
+// normalizeNotificationConfig 按渠道类型校验配置并去掉无关字段
+func normalizeNotificationConfig(notificationType string, config map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	switch notificationType {
+	case "email":
+		var cfg notification.EmailConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的邮件配置")
+		}
+		if strings.TrimSpace(cfg.SMTP) == "" || strings.TrimSpace(cfg.From) == "" || strings.TrimSpace(cfg.To) == "" {
+			return nil, errors.New("SMTP 服务器、发件人和收件人不能为空")
+		}
+		if cfg.Port <= 0 || cfg.Port > 65535 {
+			return nil, errors.New("SMTP 端口无效")
+		}
+		if err := validateEmailConfig(&cfg); err != nil {
+			return nil, err
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	case "webhook":
+		var cfg notification.WebhookConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的 Webhook 配置")
+		}
+		if !strings.HasPrefix(cfg.Webhook, "http://") && !strings.HasPrefix(cfg.Webhook, "https://") {
+			return nil, errors.New("Webhook 地址需以 http:// 或 https:// 开头")
+		}
+		if cfg.Platform == "dingtalk" && !slices.Contains(notification.DingTalkMessageTypes, cfg.MessageType) {
+			return nil, errors.New("不支持的钉钉消息类型")
+		}
+		if err := validateGenericWebhook(&cfg); err != nil {
+			return nil, err
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	case "telegram":
+		var cfg notification.TelegramConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的 Telegram 配置")
+		}
+		if strings.TrimSpace(cfg.BotToken) == "" || strings.TrimSpace(cfg.ChatID) == "" {
+			return nil, errors.New("Bot Token 和 Chat ID 不能为空")
+		}
+		if cfg.ThreadID < 0 {
+			return nil, errors.New("话题ID无效")
+		}
+		if !slices.Contains(notification.TelegramParseModes, cfg.ParseMode) {
+			return nil, errors.New("不支持的 Telegram 解析模式")
+		}
+		if cfg.APIURL != "" && !strings.HasPrefix(cfg.APIURL, "http://") && !strings.HasPrefix(cfg.APIURL, "https://") {
+			return nil, errors.New("Bot API 地址需以 http:// 或 https:// 开头")
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	case "ntfy":
+		var cfg notification.NtfyConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的 ntfy 配置")
+		}
+		if strings.TrimSpace(cfg.Topic) == "" {
+			return nil, errors.New("主题不能为空")
+		}
+		if cfg.Priority < 0 || cfg.Priority > 5 {
+			return nil, errors.New("ntfy 优先级需在 1-5 之间")
+		}
+		if cfg.ServerURL != "" && !strings.HasPrefix(cfg.ServerURL, "http://") && !strings.HasPrefix(cfg.ServerURL, "https://") {
+			return nil, errors.New("服务地址需以 http:// 或 https:// 开头")
+		}
+		if cfg.Token != "" {
+			// 使用访问令牌时不保存用户名密码
+			cfg.Username, cfg.Password = "", ""
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	case "gotify":
+		var cfg notification.GotifyConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的 Gotify 配置")
+		}
+		if !strings.HasPrefix(cfg.ServerURL, "http://") && !strings.HasPrefix(cfg.ServerURL, "https://") {
+			return nil, errors.New("服务地址需以 http:// 或 https:// 开头")
+		}
+		if strings.TrimSpace(cfg.AppToken) == "" {
+			return nil, errors.New("应用令牌不能为空")
+		}
+		if cfg.Priority < 0 || cfg.Priority > 10 {
+			return nil, errors.New("Gotify 优先级需在 1-10 之间")
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	case "bark":
+		var cfg notification.BarkConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, errors.New("无效的 Bark 配置")
+		}
+		if strings.TrimSpace(cfg.DeviceKey) == "" {
+			return nil, errors.New("设备 Key 不能为空")
+		}
+		if cfg.ServerURL != "" && !strings.HasPrefix(cfg.ServerURL, "http://") && !strings.HasPrefix(cfg.ServerURL, "https://") {
+			return nil, errors.New("服务地址需以 http:// 或 https:// 开头")
+		}
+		cfg.Enabled = true
+		normalized = cfg
+	default:
+		return nil, errors.New("不支持的通知渠道类型")
+	}
+
+	raw, err = json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateEmailConfig 校验发件人、收件人地址列表、认证方式和 CA 证书
+func validateEmailConfig(cfg *notification.EmailConfig) error {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return errors.New("发件人地址无效")
+	}
+	for _, list := range []string{cfg.To, cfg.Cc, cfg.Bcc} {
+		if _, err := notification.ParseEmailAddresses(list); err != nil {
+			return err
+		}
+	}
+	cfg.AuthMethod = strings.ToUpper(strings.TrimSpace(cfg.AuthMethod))
+	if !slices.Contains(notification.EmailAuthMethods, cfg.AuthMethod) {
+		return errors.New("不支持的认证方式")
+	}
+	if _, err := notification.EmailTLSConfig(*cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateGenericWebhook 校验通用 Webhook 的请求方法、请求头、成功状态码，并使用示例数据试渲染请求体模板
+func validateGenericWebhook(cfg *notification.WebhookConfig) error {
+	cfg.Method = strings.ToUpper(strings.TrimSpace(cfg.Method))
+	if !slices.Contains(notification.WebhookMethods, cfg.Method) {
+		return errors.New("不支持的请求方法")
+	}
+	if err := notification.ValidateWebhookHeaders(cfg.Headers); err != nil {
+		return err
+	}
+	if err := notification.ValidateWebhookSuccessCodes(cfg.SuccessCodes); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cfg.BodyTemplate) != "" {
+		data := notifytemplate.SampleData(notifytemplate.EventAlert)
+		if _, err := notification.RenderWebhookBody(cfg.BodyTemplate, notification.Message{
+			Title:   data.Title,
+			Content: data.Detail,
+			Event:   notifytemplate.EventAlert,
+			Data:    &data,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notificationChannelItem 转换为接口返回的通知渠道，敏感字段不返回
+func notificationChannelItem(channel *models.AlertNotification) NotificationChannelItem {
+	config := make(map[string]interface{})
+	if channel.ConfigJson != "" {
+		_ = json.Unmarshal([]byte(channel.ConfigJson), &config)
+	}
+	for _, field := range repositories.NotificationSecretFields(channel.NotificationType) {
+		value, _ := config[field].(string)
+		config["has_"+field] = value != ""
+		delete(config, field)
+	}
+	delete(config, "enabled")
+
+	return NotificationChannelItem{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Type:      channel.NotificationType,
+		Enabled:   channel.Enabled,
+		IsDefault: channel.IsDefault,
+		Config:    config,
+	}
+}
+
+// GetNotificationChannels 获取所有通知渠道
+func (s *AlertService) GetNotificationChannels() ([]NotificationChannelItem, error) {
+	channels, err := repositories.GetAlertNotificationRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NotificationChannelItem, 0, len(channels))
+	for _, channel := range channels {
+		items = append(items, notificationChannelItem(channel))
+	}
+	return items, nil
+}
+
+// CreateNotificationChannel 创建通知渠道
+func (s *AlertService) CreateNotificationChannel(opts NotificationChannelOptions) (*NotificationChannelItem, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	config, err := normalizeNotificationConfig(opts.Type, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := &models.AlertNotification{
+		Name:             opts.Name,
+		NotificationType: opts.Type,
+		Enabled:          opts.Enabled,
+		IsDefault:        opts.IsDefault,
+	}
+	if err := repositories.GetAlertNotificationRepository().Create(channel, config); err != nil {
+		return nil, err
+	}
+	item := notificationChannelItem(channel)
+	return &item, nil
+}
+
+// UpdateNotificationChannel 更新通知渠道，渠道类型不能修改，敏感字段留空时保留原值
+func (s *AlertService) UpdateNotificationChannel(channel *models.AlertNotification, opts NotificationChannelOptions) (*NotificationChannelItem, error) {
+	if opts.Type == "" {
+		opts.Type = channel.NotificationType
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Type != channel.NotificationType {
+		return nil, errors.New("不能修改通知渠道类型")
+	}
+
+	var oldConfig map[string]interface{}
+	_ = json.Unmarshal([]byte(channel.ConfigJson), &oldConfig)
+	for _, field := range repositories.NotificationSecretFields(channel.NotificationType) {
+		if value, _ := opts.Config[field].(string); value != "" {
+			continue
+		}
+		if old, _ := oldConfig[field].(string); old != "" {
+			if dec, err := secret.DecryptStringWithAppKey(old); err == nil {
+				old = dec
+			}
+			opts.Config[field] = old
+		}
+	}
+
+	config, err := normalizeNotificationConfig(opts.Type, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	channel.Name = opts.Name
+	channel.Enabled = opts.Enabled
+	channel.IsDefault = opts.IsDefault
+	if err := repositories.GetAlertNotificationRepository().Update(channel, config); err != nil {
+		return nil, err
+	}
+	item := notificationChannelItem(channel)
+	return &item, nil
+}
+
+// TestNotificationChannel 使用已保存的配置向通知渠道发送测试消息
+func (s *AlertService) TestNotificationChannel(channel *models.AlertNotification) error {
+	subject := "CloudSentinel 告警通知测试"
+	content := fmt.Sprintf("CloudSentinel 告警通知测试\n这是一条测试消息，用于验证通知渠道「%s」的配置是否正确。\n发送时间：%s",
+		channel.Name, time.Now().Format("2006-01-02 15:04:05"))
+	// 测试消息失败时直接返回错误，不进入重试
+	job := notificationJob(channel, notificationMessage{Title: subject, EmailContent: content, WebhookContent: content})
+	job.Test = true
+	return job.Handle()
+}
+
+// notificationMessage 渲染后的通知内容
+type notificationMessage struct {
+	Title          string
+	EmailContent   string
+	WebhookContent string
+	Color          string               // 状态颜色，Slack、Discord 等支持颜色的平台使用
+	ServerID       string               // 相关的服务器，用于生成服务器详情页链接，汇总通知为空
+	Event          string               // 事件类型，通用 Webhook 请求体模板使用
+	Data           *notifytemplate.Data // 事件数据，通用 Webhook 请求体模板使用，汇总和测试通知为空
+}
+
+// severityColor 告警级别对应的颜色，与邮件模板一致：严重为红色，警告为橙色，恢复为绿色
+func severityColor(severity string, isRecovery bool) string {
+	switch {
+	case isRecovery:
+		return "#52c41a"
+	case severity == "警告":
+		return "#faad14"
+	default:
+		return "#ff4d4f"
+	}
+}
+
+// serverDetailURL 面板中服务器详情页的地址，未配置 http.url 时为空
+func serverDetailURL(serverID string) string {
+	baseURL := strings.TrimRight(facades.Config().GetString("http.url"), "/")
+	if serverID == "" || baseURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/servers/%s", baseURL, url.PathEscape(serverID))
+}
+
+// notificationJob 构建通知渠道的发送任务，邮件使用邮件正文，其余渠道使用 Webhook 正文
+// 邮件正文为 HTML 时以 Webhook 正文作为纯文本部分
+func notificationJob(channel *models.AlertNotification, msg notificationMessage) *jobs.SendAlertJob {
+	content, htmlContent := msg.WebhookContent, ""
+	if channel.NotificationType == "email" {
+		if notifytemplate.IsHTML(msg.EmailContent) {
+			htmlContent = msg.EmailContent
+		} else {
+			content = msg.EmailContent
+		}
+	}
+	return &jobs.SendAlertJob{
+		ChannelID: channel.ID,
+		Channel:   channel.NotificationType,
+		Config:    channel.ConfigJson,
+		Subject:   msg.Title,
+		Content:   content,
+		HTML:      htmlContent,
+		Color:     msg.Color,
+		Link:      serverDetailURL(msg.ServerID),
+		Event:     msg.Event,
+		Data:      msg.Data,
+	}
+}
+
+// ResendNotificationDelivery 手动重发投递记录，生成一条新的投递记录并立即发送
+// 渠道仍存在时使用渠道当前的配置，以便修正配置后重发失败的通知，升级通知保留步骤中的收件人和地址；原记录仍在重试时不再继续重试
+func (s *AlertService) ResendNotificationDelivery(delivery *models.NotificationDelivery) (*models.NotificationDelivery, error) {
+	job, err := jobs.NewSendAlertJobFromDelivery(delivery)
+	if err != nil {
+		return nil, fmt.Errorf("无法恢复发送任务: %v", err)
+	}
+	if delivery.ChannelID != nil {
+		channel, err := repositories.GetAlertNotificationRepository().GetByID(*delivery.ChannelID)
+		if err == nil && channel != nil {
+			job.ApplyChannelConfig(channel)
+		}
+	}
+
+	deliveryRepo := repositories.GetNotificationDeliveryRepository()
+	if delivery.Status == models.DeliveryStatusRetrying {
+		delivery.Status = models.DeliveryStatusDead
+		delivery.NextRetryAt = nil
+		if err := deliveryRepo.Save(delivery); err != nil {
+			return nil, err
+		}
+	}
+
+	resendOf := delivery.ID
+	job.DeliveryID = 0
+	job.ResendOf = &resendOf
+	_ = job.Handle()
+
+	if job.DeliveryID == 0 {
+		return nil, errors.New("创建投递记录失败")
+	}
+	return deliveryRepo.GetByID(job.DeliveryID)
+}
+
+// validateSubscriptionScope 校验订阅的作用范围，只支持服务器和分组
+func validateSubscriptionScope(scope RuleScope) error {
+	if (scope.ServerID == nil) == (scope.GroupID == nil) || scope.ProfileID != nil {
+		return errors.New("订阅需要指定服务器或分组")
+	}
+	return nil
+}
+
+// GetNotificationSubscriptions 获取服务器或分组对各通知渠道的订阅情况，未订阅的渠道显示继承的结果
+func (s *AlertService) GetNotificationSubscriptions(scope RuleScope) ([]NotificationSubscriptionItem, error) {
+	if err := validateSubscriptionScope(scope); err != nil {
+		return nil, err
+	}
+	channels, err := repositories.GetAlertNotificationRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	type subscriptionSource struct {
+		enabled bool
+		source  string
+	}
+	subscribed := make(map[uint]subscriptionSource)
+	subscriptionRepo := repositories.GetNotificationSubscriptionRepository()
+
+	groupID := scope.GroupID
+	if scope.ServerID != nil {
+		server, err := repositories.GetServerRepository().GetByID(*scope.ServerID)
+		if err != nil {
+			return nil, err
+		}
+		if server != nil {
+			groupID = server.GroupID
+		}
+	}
+	if groupID != nil {
+		groupSubscriptions, err := subscriptionRepo.GetByGroupID(*groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, subscription := range groupSubscriptions {
+			subscribed[subscription.ChannelID] = subscriptionSource{subscription.Enabled, "group"}
+		}
+	}
+	if scope.ServerID != nil {
+		serverSubscriptions, err := subscriptionRepo.GetByServerID(*scope.ServerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, subscription := range serverSubscriptions {
+			subscribed[subscription.ChannelID] = subscriptionSource{subscription.Enabled, "server"}
+		}
+	}
+
+	items := make([]NotificationSubscriptionItem, 0, len(channels))
+	for _, channel := range channels {
+		item := NotificationSubscriptionItem{
+			ChannelID: channel.ID,
+			Name:      channel.Name,
+			Type:      channel.NotificationType,
+			Enabled:   channel.IsDefault,
+			Source:    "default",
+		}
+		if sub, ok := subscribed[channel.ID]; ok {
+			item.Enabled, item.Source = sub.enabled, sub.source
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SaveNotificationSubscriptions 替换服务器或分组的通知渠道订阅，subscriptions 的键为渠道ID，值为订阅或退订，未列出的渠道继承上级设置
+func (s *AlertService) SaveNotificationSubscriptions(scope RuleScope, subscriptions map[uint]bool) error {
+	if err := validateSubscriptionScope(scope); err != nil {
+		return err
+	}
+	notificationRepo := repositories.GetAlertNotificationRepository()
+	for channelID := range subscriptions {
+		channel, err := notificationRepo.GetByID(channelID)
+		if err != nil {
+			return err
+		}
+		if channel == nil {
+			return fmt.Errorf("通知渠道 %d 不存在", channelID)
+		}
+	}
+	return repositories.GetNotificationSubscriptionRepository().Replace(scope.ServerID, scope.GroupID, subscriptions)
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"goravel/app/repositories"
+	"goravel/app/utils/notifytemplate"
+
+	"github.com/goravel/framework/facades"
+)
+
+// alertEmailTemplateFile 告警和恢复邮件的默认 HTML 模板
+const alertEmailTemplateFile = "resources/views/emails/alert.tmpl"
+
+// defaultNotificationSubjects 各事件类型的默认邮件标题
+var defaultNotificationSubjects = map[string]string{
+	notifytemplate.EventAlert:         "[{{ .Severity }}] {{ .ServerName }} - {{ .MetricLabel }}",
+	notifytemplate.EventRecovery:      "[恢复] {{ .ServerName }} - {{ .MetricLabel }}",
+	notifytemplate.EventBandwidth:     "[告警] {{ .ServerName }} - 带宽峰值",
+	notifytemplate.EventTraffic:       "[告警] {{ .ServerName }} - 流量耗尽",
+	notifytemplate.EventExpiration:    "[告警] {{ .ServerName }} - 即将到期",
+	notifytemplate.EventServerOffline: "[告警] {{ .ServerName }} - 服务器离线",
+	notifytemplate.EventServerOnline:  "[恢复] {{ .ServerName }} - 服务器已上线",
+	notifytemplate.EventServiceDown:   "[严重] {{ .ServerName }} - 服务 {{ .Extra.ServiceName }} 已停止",
+	notifytemplate.EventServiceUp:     "[恢复] {{ .ServerName }} - 服务 {{ .Extra.ServiceName }} 已恢复",
+	notifytemplate.EventEscalation:    "[升级] {{ .ServerName }} - {{ .MetricLabel }}",
+}
+
+// defaultNotificationBodies 各事件类型的默认文本正文，Webhook 和没有 HTML 模板的邮件使用
+var defaultNotificationBodies = map[string]string{
+	notifytemplate.EventAlert:         "🚨 发生告警 ({{ .Severity }})\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n{{ .Detail }}\n触发时间: {{ .Timestamp }}",
+	notifytemplate.EventRecovery:      "✅ 告警恢复\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n{{ .Detail }}\n恢复时间: {{ .Timestamp }}",
+	notifytemplate.EventBandwidth:     "🚨 带宽峰值告警\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n当前带宽: {{ printf \"%.2f\" .CurrentValue }} Mbps\n阈值: {{ printf \"%.2f\" .Threshold }} Mbps\n触发时间: {{ .Timestamp }}",
+	notifytemplate.EventTraffic:       "🚨 流量耗尽告警\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n已用流量: {{ .Extra.UsedGB }} GB / {{ .Extra.LimitGB }} GB ({{ printf \"%.2f\" .CurrentValue }}%)\n阈值: {{ printf \"%.2f\" .Threshold }}%\n触发时间: {{ .Timestamp }}",
+	notifytemplate.EventExpiration:    "🚨 服务器到期提醒\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n到期时间: {{ .Extra.ExpireTime }}\n剩余天数: {{ printf \"%.0f\" .CurrentValue }} 天\n触发时间: {{ .Timestamp }}",
+	notifytemplate.EventServerOffline: "🚨 服务器离线告警\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n离线时间: {{ .Timestamp }}\n\n请检查该服务器或 Agent 状态。",
+	notifytemplate.EventServerOnline:  "✅ 服务器已上线\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n上线时间: {{ .Timestamp }}",
+	notifytemplate.EventServiceDown:   "🚨 服务停止告警\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n服务: {{ .Extra.ServiceName }}\n触发时间: {{ .Timestamp }}",
+	notifytemplate.EventServiceUp:     "✅ 服务已恢复\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n服务: {{ .Extra.ServiceName }}\n故障时长: {{ .Extra.Duration }}\n恢复时间: {{ .Timestamp }}",
+	notifytemplate.EventEscalation:    "⏫ 告警未确认 ({{ .Severity }})\n\n服务器: {{ .ServerName }} ({{ .ServerIP }})\n{{ .Detail }}\n触发时间: {{ .Timestamp }}\n已持续: {{ .Extra.Elapsed }} 分钟，请尽快处理并确认告警",
+}
+
+// NotificationTemplateItem 通知模板及是否为自定义模板
+type NotificationTemplateItem struct {
+	Channel string `json:"channel"`
+	Event   string `json:"event"`
+	notifytemplate.Template
+	Custom bool `json:"custom"`
+}
+
+// notificationTemplateKey 通知模板在系统设置中的键
+func notificationTemplateKey(channel, event string) string {
+	return "notification_template_" + channel + "_" + event
+}
+
+// alertEmailTemplateBody 读取告警邮件的默认 HTML 模板，去掉供视图加载使用的 define 包裹
+func alertEmailTemplateBody() (string, error) {
+	content, err := ResourceFiles.ReadFile(alertEmailTemplateFile)
+	if err != nil {
+		return "", err
+	}
+	body := strings.TrimSpace(string(content))
+	body = strings.TrimPrefix(body, `{{ define "emails/alert.tmpl" }}`)
+	body = strings.TrimSuffix(body, "{{ end }}")
+	return strings.TrimSpace(body), nil
+}
+
+// defaultNotificationTemplate 获取渠道和事件类型的默认模板，告警和恢复邮件使用 HTML 模板
+func defaultNotificationTemplate(channel, event string) notifytemplate.Template {
+	tmpl := notifytemplate.Template{
+		Subject: defaultNotificationSubjects[event],
+		Body:    defaultNotificationBodies[event],
+	}
+	if channel == notifytemplate.ChannelEmail && (event == notifytemplate.EventAlert || event == notifytemplate.EventRecovery) {
+		if body, err := alertEmailTemplateBody(); err == nil {
+			tmpl.Body = body
+		} else {
+			facades.Log().Warningf("读取邮件模板失败: %v", err)
+		}
+	}
+	if channel != notifytemplate.ChannelEmail {
+		tmpl.Subject = ""
+	}
+	return tmpl
+}
+
+// validateTemplateTarget 校验渠道和事件类型
+func validateTemplateTarget(channel, event string) error {
+	if !notifytemplate.ValidChannel(channel) {
+		return errors.New("不支持的通知渠道")
+	}
+	if !notifytemplate.ValidEvent(event) {
+		return errors.New("不支持的事件类型")
+	}
+	return nil
+}
+
+// GetNotificationTemplate 获取渠道和事件类型生效的模板，未自定义时返回默认模板
+func (s *AlertService) GetNotificationTemplate(channel, event string) (notifytemplate.Template, bool) {
+	value := repositories.GetSystemSettingRepository().GetValue(notificationTemplateKey(channel, event), "")
+	if value != "" {
+		var tmpl notifytemplate.Template
+		if err := json.Unmarshal([]byte(value), &tmpl); err == nil {
+			return tmpl, true
+		}
+		facades.Log().Warningf("解析通知模板 %s/%s 失败，使用默认模板", channel, event)
+	}
+	return defaultNotificationTemplate(channel, event), false
+}
+
+// GetNotificationTemplates 获取所有渠道和事件类型生效的模板
+func (s *AlertService) GetNotificationTemplates() []NotificationTemplateItem {
+	items := make([]NotificationTemplateItem, 0, len(notifytemplate.Channels)*len(notifytemplate.Events))
+	for _, channel := range notifytemplate.Channels {
+		for _, event := range notifytemplate.Events {
+			tmpl, custom := s.GetNotificationTemplate(channel, event)
+			items = append(items, NotificationTemplateItem{Channel: channel, Event: event, Template: tmpl, Custom: custom})
+		}
+	}
+	return items
+}
+
+// PreviewNotificationTemplate 使用示例数据渲染模板，返回渲染结果或模板错误
+func (s *AlertService) PreviewNotificationTemplate(channel, event string, tmpl notifytemplate.Template) (string, string, error) {
+	if err := validateTemplateTarget(channel, event); err != nil {
+		return "", "", err
+	}
+	return notifytemplate.Render(channel, tmpl, notifytemplate.SampleData(event))
+}
+
+// SaveNotificationTemplate 校验并保存自定义模板，模板无法使用示例数据渲染时不保存
+func (s *AlertService) SaveNotificationTemplate(channel, event string, tmpl notifytemplate.Template) error {
+	if _, _, err := s.PreviewNotificationTemplate(channel, event, tmpl); err != nil {
+		return err
+	}
+	return repositories.GetSystemSettingRepository().SetJSON(notificationTemplateKey(channel, event), tmpl)
+}
+
+// ResetNotificationTemplate 删除自定义模板，恢复使用默认模板
+func (s *AlertService) ResetNotificationTemplate(channel, event string) error {
+	if err := validateTemplateTarget(channel, event); err != nil {
+		return err
+	}
+	return repositories.GetSystemSettingRepository().SetValue(notificationTemplateKey(channel, event), "")
+}
+
+// renderNotificationChannel 按渠道生效的模板渲染通知，自定义模板渲染失败时退回默认模板
+func (s *AlertService) renderNotificationChannel(channel, event string, data notifytemplate.Data) (string, string, error) {
+	tmpl, custom := s.GetNotificationTemplate(channel, event)
+	subject, body, err := notifytemplate.Render(channel, tmpl, data)
+	if err == nil || !custom {
+		return subject, body, err
+	}
+	facades.Log().Warningf("渲染自定义通知模板 %s/%s 失败，使用默认模板: %v", channel, event, err)
+	return notifytemplate.Render(channel, defaultNotificationTemplate(channel, event), data)
+}
+
+// renderNotification 渲染事件的通知标题、邮件正文和 Webhook 正文
+func (s *AlertService) renderNotification(event string, data notifytemplate.Data) notificationMessage {
+	title, emailContent, emailErr := s.renderNotificationChannel(notifytemplate.ChannelEmail, event, data)
+	if emailErr != nil {
+		facades.Log().Errorf("渲染邮件通知失败: %v", emailErr)
+		title, _, _ = notifytemplate.Render(notifytemplate.ChannelEmail, notifytemplate.Template{
+			Subject: defaultNotificationSubjects[event],
+			Body:    defaultNotificationBodies[event],
+		}, data)
+	}
+
+	data.Title = title
+	_, webhookContent, err := s.renderNotificationChannel(notifytemplate.ChannelWebhook, event, data)
+	if err != nil {
+		facades.Log().Errorf("渲染 Webhook 通知失败: %v", err)
+	}
+	if emailErr != nil {
+		// 邮件模板不可用时使用 Webhook 正文
+		emailContent = webhookContent
+	}
+
+	color := data.Color
+	if color == "" {
+		color = severityColor(data.Severity, data.IsRecovery)
+	}
+	return notificationMessage{
+		Title:          title,
+		EmailContent:   emailContent,
+		WebhookContent: webhookContent,
+		Color:          color,
+		ServerID:       data.ServerID,
+		Event:          event,
+		Data:           &data,
+	}
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"goravel/app/models"
+	"goravel/app/repositories"
+	"goravel/app/utils/notifytemplate"
+
+	"github.com/google/uuid"
+	"github.com/goravel/framework/facades"
+)
+
+// 服务监控事件类型
+const (
+	ServiceEventDown = "down"
+	ServiceEventUp   = "up"
+)
+
+// ServiceUptime 单个服务在时间范围内的可用性统计
+type ServiceUptime struct {
+	Name            string                        `json:"name"`
+	Status          string                        `json:"status"` // up, down, unknown
+	UptimePercent   float64                       `json:"uptime_percent"`
+	DowntimeSeconds int64                         `json:"downtime_seconds"`
+	DownCount       int                           `json:"down_count"`
+	Events          []*models.ServiceMonitorAlert `json:"events"`
+}
+
+// CheckServiceStatus 根据 process_info 上报的服务状态评估服务监控告警
+func (s *AlertService) CheckServiceStatus(serverID string, data map[string]interface{}) error {
+	serverRepo := repositories.GetServerRepository()
+	server, err := serverRepo.GetByID(serverID)
+	if err != nil || server == nil {
+		return err
+	}
+	if len(server.MonitoredServices) == 0 {
+		return nil
+	}
+
+	statuses := ParseServiceStatuses(data)
+	// 服务监控规则只查询一次，各服务共用
+	rule, err := repositories.GetServerAlertRuleRepository().GetEffectiveByType(serverID, "service")
+	if err != nil {
+		return err
+	}
+	notify := isServiceRuleEnabled(rule)
+	var ruleID *uint
+	if rule != nil && rule.ID > 0 {
+		id := rule.ID
+		ruleID = &id
+	}
+
+	for _, name := range server.MonitoredServices {
+		running, ok := statuses[name]
+		if !ok {
+			// 未上报的服务状态未知，不做判断
+			continue
+		}
+		if err := s.evaluateServiceState(server, name, running, notify, ruleID); err != nil {
+			facades.Log().Warningf("服务监控告警检查失败: server_id=%s, service=%s, error=%v", serverID, name, err)
+		}
+	}
+
+	return nil
+}
+
+// isServiceRuleEnabled 服务监控告警规则是否启用
+func isServiceRuleEnabled(rule *models.ServerAlertRule) bool {
+	if rule == nil {
+		return false
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(rule.Config), &config); err != nil {
+		return false
+	}
+	enabled, _ := config["enabled"].(bool)
+	return enabled
+}
+
+// evaluateServiceState 评估单个服务的状态变化，状态切换时记录事件并发送通知，ruleID 为事件关联的服务监控规则
+func (s *AlertService) evaluateServiceState(server *models.Server, serviceName string, running bool, notify bool, ruleID *uint) error {
+	eventRepo := repositories.GetServiceMonitorAlertRepository()
+	now := time.Now()
+
+	newState := ServiceEventUp
+	if !running {
+		newState = ServiceEventDown
+	}
+
+	// 获取上一次的状态，缓存失效时从事件表恢复
+	cacheKey := fmt.Sprintf("service_state:%s:%s", server.ID, serviceName)
+	currentState := ""
+	if cached := facades.Cache().Get(cacheKey); cached != nil {
+		currentState, _ = cached.(string)
+	}
+	var lastEvent *models.ServiceMonitorAlert
+	if currentState == "" || newState == ServiceEventUp {
+		lastEvent, _ = eventRepo.GetLatestByService(server.ID, serviceName, now)
+		if currentState == "" {
+			currentState = ServiceEventUp
+			if lastEvent != nil {
+				currentState = lastEvent.Type
+			}
+		}
+	}
+
+	if newState == currentState {
+		return facades.Cache().Put(cacheKey, newState, 24*time.Hour)
+	}
+
+	event := &models.ServiceMonitorAlert{
+		ID:          uuid.New().String(),
+		ServerID:    server.ID,
+		ServiceName: serviceName,
+		Type:        newState,
+		Timestamp:   now,
+		RuleID:      ruleID,
+	}
+
+	// 事件的标题和内容使用通知模板渲染，与发送的通知一致
+	data := s.notificationData(server.ID)
+	data.Timestamp = now.Format("2006-01-02 15:04:05")
+	data.MetricLabel = "服务 " + serviceName
+	data.Extra["ServiceName"] = serviceName
+	data.Detail = "服务: " + serviceName
+	templateEvent := notifytemplate.EventServiceDown
+	if newState == ServiceEventDown {
+		data.Severity = "严重"
+		data.StatusText = "严重"
+	} else {
+		if lastEvent != nil && lastEvent.Type == ServiceEventDown {
+			event.Duration = int64(now.Sub(lastEvent.Timestamp).Seconds())
+		}
+		duration := CalculateUptime(event.Duration)
+		data.StatusText = "恢复正常"
+		data.IsRecovery = true
+		data.Extra["Duration"] = duration
+		data.Detail += "\n故障时长: " + duration
+		templateEvent = notifytemplate.EventServiceUp
+	}
+	data.Color = severityColor(data.Severity, data.IsRecovery)
+	msg := s.renderNotification(templateEvent, data)
+	event.Title = msg.Title
+	event.Message = msg.WebhookContent
+
+	// 事件保存成功后再更新缓存的状态，保存失败时下次上报仍会识别为状态变化
+	if err := eventRepo.Create(event); err != nil {
+		return err
+	}
+	if err := facades.Cache().Put(cacheKey, newState, 24*time.Hour); err != nil {
+		facades.Log().Warningf("缓存服务状态失败: %v", err)
+	}
+
+	if notify && !s.isSilenced(server.ID, "service:"+serviceName, nil) {
+		s.dispatchNotifications(server.ID, msg)
+	}
+
+	return nil
+}
+
+// GetServiceUptimeHistory 统计服务器各被监控服务在时间范围内的可用性
+func (s *AlertService) GetServiceUptimeHistory(serverID string, startTime, endTime time.Time) ([]*ServiceUptime, error) {
+	serverRepo := repositories.GetServerRepository()
+	server, err := serverRepo.GetByID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if server == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+
+	eventRepo := repositories.GetServiceMonitorAlertRepository()
+	events, err := eventRepo.GetByServerID(serverID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// 被监控的服务，以及时间范围内出现过事件的服务
+	names := make([]string, 0, len(server.MonitoredServices))
+	eventsByService := make(map[string][]*models.ServiceMonitorAlert)
+	for _, name := range server.MonitoredServices {
+		if _, ok := eventsByService[name]; !ok {
+			eventsByService[name] = []*models.ServiceMonitorAlert{}
+			names = append(names, name)
+		}
+	}
+	for _, event := range events {
+		if _, ok := eventsByService[event.ServiceName]; !ok {
+			names = append(names, event.ServiceName)
+		}
+		eventsByService[event.ServiceName] = append(eventsByService[event.ServiceName], event)
+	}
+
+	currentStatuses := ParseServiceStatuses(server.ServiceStatus)
+	totalSeconds := endTime.Sub(startTime).Seconds()
+	result := make([]*ServiceUptime, 0, len(names))
+
+	for _, name := range names {
+		serviceEvents := eventsByService[name]
+		uptime := &ServiceUptime{
+			Name:          name,
+			Status:        "unknown",
+			UptimePercent: 100,
+			Events:        serviceEvents,
+		}
+		if running, ok := currentStatuses[name]; ok {
+			uptime.Status = ServiceEventUp
+			if !running {
+				uptime.Status = ServiceEventDown
+			}
+		}
+
+		// 以时间范围开始前的最后一个事件作为初始状态
+		state := ServiceEventUp
+		if previous, err := eventRepo.GetLatestByService(serverID, name, startTime); err == nil && previous != nil {
+			state = previous.Type
+		}
+
+		var downtime float64
+		cursor := startTime
+		for _, event := range serviceEvents {
+			if state == ServiceEventDown {
+				downtime += event.Timestamp.Sub(cursor).Seconds()
+			}
+			if event.Type == ServiceEventDown {
+				uptime.DownCount++
+			}
+			state = event.Type
+			cursor = event.Timestamp
+		}
+		if state == ServiceEventDown {
+			downtime += endTime.Sub(cursor).Seconds()
+		}
+
+		uptime.DowntimeSeconds = int64(downtime)
+		if totalSeconds > 0 {
+			uptime.UptimePercent = FormatMetricValue((totalSeconds - downtime) / totalSeconds * 100)
+		}
+		result = append(result, uptime)
+	}
+
+	return result, nil
+}
+
+// ParseServiceStatuses 解析 process_info 中的服务运行状态，返回 服务名 -> 是否运行
+// 兼容 {"services": {"nginx": "running"}}、{"services": [{"name": "nginx", "status": "running"}]} 以及直接以服务名为键的格式
+func ParseServiceStatuses(data map[string]interface{}) map[string]bool {
+	result := make(map[string]bool)
+	if data == nil {
+		return result
+	}
+
+	var source interface{} = data
+	if services, ok := data["services"]; ok {
+		source = services
+	}
+
+	switch v := source.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if running, ok := parseServiceRunning(value); ok {
+				result[name] = running
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := itemMap["name"].(string)
+			if name == "" {
+				continue
+			}
+			if running, ok := parseServiceRunning(itemMap); ok {
+				result[name] = running
+			}
+		}
+	}
+
+	return result
+}
+
+// parseServiceRunning 解析单个服务的状态值，第二个返回值表示状态是否可识别
+func parseServiceRunning(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v > 0, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "running", "active", "up", "ok", "online", "started", "healthy":
+			return true, true
+		case "stopped", "inactive", "dead", "failed", "down", "exited", "offline", "unhealthy", "not_found", "not-found":
+			return false, true
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"running", "active", "status", "state"} {
+			if field, ok := v[key]; ok {
+				if running, ok := parseServiceRunning(field); ok {
+					return running, true
+				}
+			}
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/app/utils/notifytemplate"
+	"goravel/tests"
+
+	"github.com/goravel/framework/facades"
+)
+
+func TestCheckServiceStatusUsesTemplates(t *testing.T) {
+	tests.NewDatabase(t)
+	alertService := services.NewAlertService()
+	requests := newWebhookChannel(t, alertService)
+
+	serverID := "service-server"
+	if err := facades.Orm().Query().Create(&models.Server{ID: serverID, Name: "web-2", IP: "10.0.0.10", AgentKey: "key", Status: "online", MonitoredServices: []string{"nginx"}}); err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	if err := alertService.SaveAlertRules(services.RuleScope{ServerID: &serverID}, map[string]interface{}{
+		"service": map[string]interface{}{"enabled": true},
+	}); err != nil {
+		t.Fatalf("保存服务监控规则失败: %v", err)
+	}
+	if err := alertService.SaveNotificationTemplate(notifytemplate.ChannelWebhook, notifytemplate.EventServiceDown, notifytemplate.Template{
+		Body: "{{ .Extra.ServiceName }} 在 {{ .ServerName }} 上停止",
+	}); err != nil {
+		t.Fatalf("保存通知模板失败: %v", err)
+	}
+
+	check := func(status string) *models.ServiceMonitorAlert {
+		t.Helper()
+		if err := alertService.CheckServiceStatus(serverID, map[string]interface{}{
+			"services": map[string]interface{}{"nginx": status},
+		}); err != nil {
+			t.Fatalf("服务监控告警检查失败: %v", err)
+		}
+		var event models.ServiceMonitorAlert
+		if err := facades.Orm().Query().Where("server_id", serverID).Order("timestamp desc").First(&event); err != nil {
+			t.Fatal(err)
+		}
+		return &event
+	}
+
+	// 服务停止时使用自定义的 Webhook 模板，标题使用默认的邮件标题模板
+	down := check("stopped")
+	if down.Type != services.ServiceEventDown || down.Message != "nginx 在 web-2 上停止" {
+		t.Errorf("停止事件为 %s: %q，期望使用自定义模板", down.Type, down.Message)
+	}
+	if down.Title != "[严重] web-2 - 服务 nginx 已停止" {
+		t.Errorf("停止事件标题为 %q", down.Title)
+	}
+
+	// 服务恢复时使用默认模板
+	up := check("running")
+	if up.Type != services.ServiceEventUp || up.Title != "[恢复] web-2 - 服务 nginx 已恢复" {
+		t.Errorf("恢复事件为 %s: %q", up.Type, up.Title)
+	}
+	if !strings.Contains(up.Message, "服务: nginx") || !strings.Contains(up.Message, "故障时长") {
+		t.Errorf("恢复事件内容为 %q", up.Message)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("发送了 %d 次通知，期望 2 次", got)
+	}
+}
@@ -38,6 +38,8 @@ type AgentMessageHandler interface {
 	HandleProcessInfo(data map[string]interface{}, conn *AgentConnection) error
 	// HandleGPUInfo 处理GPU信息消息
 	HandleGPUInfo(data map[string]interface{}, conn *AgentConnection) error
+	// HandleSensors 处理硬件传感器消息
+	HandleSensors(data map[string]interface{}, conn *AgentConnection) error
 	// HandleAgentLogs 处理Agent日志消息
 	HandleAgentLogs(data map[string]interface{}, conn *AgentConnection) error
 }
@@ -149,6 +151,17 @@ func (h *agentMessageHandler) HandleAuth(data map[string]interface{}, conn *Agen
 
 	facades.Log().Channel("websocket").Infof("Agent认证成功: server_id=%s, remote=%s", serverID, conn.GetRemoteAddr())
 
+	// 记录 Agent 版本（可选）
+	agentVersion, _ := authData["agent_version"].(string)
+	if agentVersion == "" {
+		agentVersion, _ = authData["version"].(string)
+	}
+	if agentVersion != "" {
+		if err := h.saver.SaveAgentVersion(serverID, agentVersion); err != nil {
+			facades.Log().Channel("websocket").Warningf("更新Agent版本失败: server_id=%s, error=%v", serverID, err)
+		}
+	}
+
 	// 注册连接（这会关闭旧连接）
 	// 注意：必须在发送响应后注册，否则旧连接可能在响应发送前被关闭
 	if err := h.manager.RegisterAgent(serverID, conn); err != nil {
@@ -312,6 +325,27 @@ func (h *agentMessageHandler) HandleGPUInfo(data map[string]interface{}, conn *A
 	return nil
 }
 
+// HandleSensors 处理硬件传感器消息
+func (h *agentMessageHandler) HandleSensors(data map[string]interface{}, conn *AgentConnection) error {
+	sensorData, ok := data["data"].(map[string]interface{})
+	if !ok {
+		return errors.New("传感器数据格式错误")
+	}
+
+	serverID := conn.GetServerID()
+	if serverID == "" {
+		return errors.New("未认证的连接")
+	}
+
+	// 异步保存数据
+	if err := h.saver.SaveSensors(serverID, sensorData); err != nil {
+		facades.Log().Channel("websocket").Errorf("保存传感器数据失败: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 // HandleAgentLogs 处理Agent日志消息
 func (h *agentMessageHandler) HandleAgentLogs(data map[string]interface{}, conn *AgentConnection) error {
 	logsData, ok := data["data"].([]interface{})
@@ -16,5 +16,7 @@ type AgentDataSaver interface {
 	SaveSwapInfo(serverID string, data map[string]interface{}) error
 	SaveProcessInfo(serverID string, data map[string]interface{}) error
 	SaveGPUInfo(serverID string, data map[string]interface{}) error
+	SaveSensors(serverID string, data map[string]interface{}) error
+	SaveAgentVersion(serverID string, version string) error
 	SaveAgentLogs(serverID string, logs []interface{}) error
 }
@@ -47,6 +47,7 @@ const (
 	MessageTypeAgentConfig = "agent_config"
 	MessageTypeProcessInfo = "process_info"
 	MessageTypeGPUInfo     = "gpu_info"
+	MessageTypeSensors     = "sensors"
 	MessageTypeAgentLog    = "agent_log"
 	MessageTypePing        = "ping"
 	MessageTypePong        = "pong"
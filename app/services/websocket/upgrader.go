@@ -117,4 +117,3 @@ func (u *Upgrader) GetClientIPFromConn(conn *websocket.Conn, ctx http.Context) s
 
 	return ctx.Request().Ip()
 }
-
@@ -82,6 +82,14 @@ func (s *agentDataSaver) SaveGPUInfo(serverID string, data map[string]interface{
 	return SaveGPUInfo(serverID, data)
 }
 
+func (s *agentDataSaver) SaveAgentVersion(serverID string, version string) error {
+	return UpdateAgentVersion(serverID, version)
+}
+
+func (s *agentDataSaver) SaveSensors(serverID string, data map[string]interface{}) error {
+	return SaveSensors(serverID, data)
+}
+
 func (s *agentDataSaver) SaveAgentLogs(serverID string, logs []interface{}) error {
 	return SaveAgentLogs(serverID, logs)
 }
@@ -0,0 +1,127 @@
+package cooldown
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"goravel/app/utils/expression"
+
+	"github.com/goravel/framework/facades"
+)
+
+const (
+	// MinInterval 重复通知间隔的下限，避免持续告警时频繁通知
+	MinInterval = 30 * time.Second
+	// MaxInterval 重复通知间隔的上限
+	MaxInterval = 7 * 24 * time.Hour
+)
+
+// Options 规则的重复通知配置，字段均为空时使用告警类型的默认冷却期
+type Options struct {
+	RepeatInterval     string `json:"repeat_interval,omitempty"`       // 持续告警时的重复通知间隔，如 10m、1h
+	NotifyOnChangeOnly bool   `json:"notify_on_change_only,omitempty"` // 只在状态变化时通知，不重复通知
+	MaxRepeats         int    `json:"max_repeats,omitempty"`           // 每次告警最多重复通知的次数，0 表示不限制
+}
+
+// FromConfig 从规则配置或请求数据中读取重复通知配置
+func FromConfig(data map[string]interface{}) Options {
+	var opts Options
+	if interval, ok := data["repeat_interval"].(string); ok {
+		opts.RepeatInterval = interval
+	}
+	if changeOnly, ok := data["notify_on_change_only"].(bool); ok {
+		opts.NotifyOnChangeOnly = changeOnly
+	}
+	if maxRepeats, ok := data["max_repeats"].(float64); ok {
+		opts.MaxRepeats = int(maxRepeats)
+	}
+	return opts
+}
+
+// Validate 校验重复通知配置
+func (o Options) Validate() error {
+	interval, err := expression.ParseDuration(o.RepeatInterval)
+	if err != nil {
+		return fmt.Errorf("重复通知间隔无效: %v", err)
+	}
+	if o.RepeatInterval != "" && (interval < MinInterval || interval > MaxInterval) {
+		return fmt.Errorf("重复通知间隔需在 %s 到 %s 之间", MinInterval, MaxInterval)
+	}
+	if o.MaxRepeats < 0 {
+		return fmt.Errorf("最大重复通知次数不能为负数")
+	}
+	return nil
+}
+
+// Interval 获取重复通知间隔，未配置时使用 defaultInterval
+func (o Options) Interval(defaultInterval time.Duration) time.Duration {
+	interval, err := expression.ParseDuration(o.RepeatInterval)
+	if err != nil || interval <= 0 {
+		return defaultInterval
+	}
+	return interval
+}
+
+// AllowRepeat 判断持续告警时是否需要再次通知
+// last 为上次通知的时间，repeats 为本次告警已重复通知的次数
+func (o Options) AllowRepeat(last time.Time, repeats int, defaultInterval time.Duration, now time.Time) bool {
+	if o.NotifyOnChangeOnly {
+		return false
+	}
+	if o.MaxRepeats > 0 && repeats >= o.MaxRepeats {
+		return false
+	}
+	return now.Sub(last) >= o.Interval(defaultInterval)
+}
+
+// Allow 判断告警是否需要通知，需要时记录本次通知
+// 没有通知记录时视为首次告警，直接通知；否则按规则的重复通知配置判断
+func Allow(key string, opts Options, defaultInterval time.Duration, now time.Time) bool {
+	last, repeats, ok := load(key)
+	if !ok {
+		Start(key, now)
+		return true
+	}
+	if !opts.AllowRepeat(last, repeats, defaultInterval, now) {
+		return false
+	}
+	save(key, now, repeats+1)
+	return true
+}
+
+// Start 记录告警的首次通知，之后的重复通知从该时间开始计算间隔
+func Start(key string, now time.Time) {
+	save(key, now, 0)
+}
+
+// Reset 清除通知记录，告警恢复或状态变化后重新计算
+func Reset(key string) {
+	_ = facades.Cache().Forget(key)
+}
+
+// load 读取上次通知的时间和已重复通知的次数
+func load(key string) (time.Time, int, bool) {
+	cached, ok := facades.Cache().Get(key).(string)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	unixText, repeatsText, found := strings.Cut(cached, ":")
+	if !found {
+		return time.Time{}, 0, false
+	}
+	unix, err := strconv.ParseInt(unixText, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	repeats, _ := strconv.Atoi(repeatsText)
+	return time.Unix(unix, 0), repeats, true
+}
+
+// save 保存通知记录，格式为 "上次通知时间:重复次数"
+func save(key string, at time.Time, repeats int) {
+	if !facades.Cache().Forever(key, fmt.Sprintf("%d:%d", at.Unix(), repeats)) {
+		facades.Log().Warningf("保存告警冷却记录失败: %s", key)
+	}
+}
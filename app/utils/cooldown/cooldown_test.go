@@ -0,0 +1,118 @@
+package cooldown_test
+
+import (
+	"testing"
+	"time"
+
+	"goravel/app/utils/cooldown"
+	"goravel/tests"
+)
+
+const defaultInterval = 2 * time.Minute
+
+// step 在告警开始后 after 时检查一次是否通知
+type step struct {
+	after time.Duration
+	want  bool
+}
+
+func TestAllow(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  cooldown.Options
+		steps []step
+	}{
+		{
+			name: "未配置时使用默认间隔",
+			steps: []step{
+				{0, true},
+				{time.Minute, false},
+				{2 * time.Minute, true},
+				{3 * time.Minute, false},
+				{4 * time.Minute, true},
+			},
+		},
+		{
+			name: "按配置的间隔重复通知",
+			opts: cooldown.Options{RepeatInterval: "10m"},
+			steps: []step{
+				{0, true},
+				{2 * time.Minute, false},
+				{9 * time.Minute, false},
+				{10 * time.Minute, true},
+				{15 * time.Minute, false},
+				{20 * time.Minute, true},
+			},
+		},
+		{
+			name: "达到最大重复次数后不再通知",
+			opts: cooldown.Options{MaxRepeats: 2},
+			steps: []step{
+				{0, true},
+				{2 * time.Minute, true},
+				{4 * time.Minute, true},
+				{6 * time.Minute, false},
+				{time.Hour, false},
+			},
+		},
+		{
+			name: "只在状态变化时通知",
+			opts: cooldown.Options{NotifyOnChangeOnly: true, RepeatInterval: "1m"},
+			steps: []step{
+				{0, true},
+				{time.Minute, false},
+				{24 * time.Hour, false},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tests.NewApp(t)
+			start := time.Unix(1_700_000_000, 0)
+			for _, s := range c.steps {
+				if got := cooldown.Allow("alert_cooldown:test", c.opts, defaultInterval, start.Add(s.after)); got != s.want {
+					t.Errorf("告警开始 %s 后是否通知为 %v，期望 %v", s.after, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStartAndReset(t *testing.T) {
+	tests.NewApp(t)
+	key := "alert_cooldown:test"
+	opts := cooldown.Options{RepeatInterval: "5m", MaxRepeats: 1}
+	now := time.Unix(1_700_000_000, 0)
+
+	// Start 记录首次通知，之后的重复通知从该时间开始计算
+	cooldown.Start(key, now)
+	if cooldown.Allow(key, opts, defaultInterval, now.Add(4*time.Minute)) {
+		t.Error("未到重复通知间隔时不应通知")
+	}
+	if !cooldown.Allow(key, opts, defaultInterval, now.Add(5*time.Minute)) {
+		t.Error("到达重复通知间隔时应通知")
+	}
+	if cooldown.Allow(key, opts, defaultInterval, now.Add(10*time.Minute)) {
+		t.Error("达到最大重复次数后不应通知")
+	}
+
+	// 状态变化后 Start 重新计算间隔和重复次数
+	now = now.Add(time.Hour)
+	cooldown.Start(key, now)
+	if cooldown.Allow(key, opts, defaultInterval, now.Add(time.Minute)) {
+		t.Error("重新开始后未到重复通知间隔时不应通知")
+	}
+	if !cooldown.Allow(key, opts, defaultInterval, now.Add(5*time.Minute)) {
+		t.Error("重新开始后应重新计算重复次数")
+	}
+
+	// Reset 清除记录后视为首次告警，立即通知
+	cooldown.Reset(key)
+	if !cooldown.Allow(key, opts, defaultInterval, now.Add(6*time.Minute)) {
+		t.Error("清除记录后应立即通知")
+	}
+	if cooldown.Allow(key, opts, defaultInterval, now.Add(7*time.Minute)) {
+		t.Error("清除记录后应从本次通知重新计算间隔")
+	}
+}
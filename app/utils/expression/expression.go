@@ -0,0 +1,279 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxWindow 聚合函数允许的最大时间窗口
+const MaxWindow = time.Hour
+
+// ErrNoData 表达式引用的指标暂无数据
+var ErrNoData = errors.New("指标暂无数据")
+
+// Env 表达式求值时的指标数据来源
+type Env interface {
+	// Value 获取指标的最新值
+	Value(metric string) (float64, bool)
+	// Aggregate 计算指标在时间窗口内的聚合值，fn 为 avg、min、max、sum、count
+	Aggregate(fn, metric string, window time.Duration) (float64, bool)
+}
+
+// windowFuncs 时间窗口聚合函数
+var windowFuncs = map[string]bool{
+	"avg":   true,
+	"min":   true,
+	"max":   true,
+	"sum":   true,
+	"count": true,
+}
+
+// Expr 已解析的告警表达式
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse 解析告警表达式，例如 avg(cpu_usage, 5m) > 90 and memory_usage > 80
+func Parse(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errors.New("表达式不能为空")
+	}
+
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("表达式在位置 %d 处有多余内容 %q", p.peek().pos, p.peek().text)
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval 对表达式求值，返回结果是否成立以及求值过程中各指标项的取值
+func (e *Expr) Eval(env Env) (bool, map[string]float64, error) {
+	values := make(map[string]float64)
+	result, err := e.root.eval(env, values)
+	if err != nil {
+		return false, values, err
+	}
+	return result != 0, values, nil
+}
+
+// Metrics 返回表达式引用的所有指标名称
+func (e *Expr) Metrics() []string {
+	seen := make(map[string]bool)
+	e.root.collect(seen)
+
+	metrics := make([]string, 0, len(seen))
+	for name := range seen {
+		metrics = append(metrics, name)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// node 表达式语法树节点
+type node interface {
+	eval(env Env, values map[string]float64) (float64, error)
+	collect(metrics map[string]bool)
+	String() string
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(Env, map[string]float64) (float64, error) {
+	return n.value, nil
+}
+
+func (n *numberNode) collect(map[string]bool) {}
+
+func (n *numberNode) String() string {
+	return strconv.FormatFloat(n.value, 'f', -1, 64)
+}
+
+type metricNode struct {
+	name string
+}
+
+func (n *metricNode) eval(env Env, values map[string]float64) (float64, error) {
+	value, ok := env.Value(n.name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNoData, n.name)
+	}
+	values[n.name] = value
+	return value, nil
+}
+
+func (n *metricNode) collect(metrics map[string]bool) {
+	metrics[n.name] = true
+}
+
+func (n *metricNode) String() string {
+	return n.name
+}
+
+type windowNode struct {
+	fn     string
+	metric string
+	window time.Duration
+	text   string
+}
+
+func (n *windowNode) eval(env Env, values map[string]float64) (float64, error) {
+	value, ok := env.Aggregate(n.fn, n.metric, n.window)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNoData, n.text)
+	}
+	values[n.text] = value
+	return value, nil
+}
+
+func (n *windowNode) collect(metrics map[string]bool) {
+	metrics[n.metric] = true
+}
+
+func (n *windowNode) String() string {
+	return n.text
+}
+
+type absNode struct {
+	arg node
+}
+
+func (n *absNode) eval(env Env, values map[string]float64) (float64, error) {
+	value, err := n.arg.eval(env, values)
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(value), nil
+}
+
+func (n *absNode) collect(metrics map[string]bool) {
+	n.arg.collect(metrics)
+}
+
+func (n *absNode) String() string {
+	return "abs(" + n.arg.String() + ")"
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env Env, values map[string]float64) (float64, error) {
+	value, err := n.operand.eval(env, values)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "not" {
+		return boolValue(value == 0), nil
+	}
+	return -value, nil
+}
+
+func (n *unaryNode) collect(metrics map[string]bool) {
+	n.operand.collect(metrics)
+}
+
+func (n *unaryNode) String() string {
+	if n.op == "not" {
+		return "not " + n.operand.String()
+	}
+	return n.op + n.operand.String()
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env Env, values map[string]float64) (float64, error) {
+	left, err := n.left.eval(env, values)
+	if err != nil {
+		return 0, err
+	}
+
+	// 逻辑运算短路求值
+	switch n.op {
+	case "and":
+		if left == 0 {
+			return 0, nil
+		}
+	case "or":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := n.right.eval(env, values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "and", "or":
+		return boolValue(right != 0), nil
+	case ">":
+		return boolValue(left > right), nil
+	case ">=":
+		return boolValue(left >= right), nil
+	case "<":
+		return boolValue(left < right), nil
+	case "<=":
+		return boolValue(left <= right), nil
+	case "==":
+		return boolValue(left == right), nil
+	case "!=":
+		return boolValue(left != right), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, errors.New("除数为零")
+		}
+		return left / right, nil
+	}
+	return 0, fmt.Errorf("不支持的运算符 %s", n.op)
+}
+
+func (n *binaryNode) collect(metrics map[string]bool) {
+	n.left.collect(metrics)
+	n.right.collect(metrics)
+}
+
+func (n *binaryNode) String() string {
+	return n.left.String() + " " + n.op + " " + n.right.String()
+}
+
+// boolValue 将布尔值转换为数值
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenDuration
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token 词法单元
+type token struct {
+	kind     tokenKind
+	text     string
+	number   float64
+	duration time.Duration
+	pos      int
+}
+
+// tokenize 将表达式拆分为词法单元
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			numText := string(runes[start:i])
+			number, err := strconv.ParseFloat(numText, 64)
+			if err != nil {
+				return nil, fmt.Errorf("无效的数字 %q (位置 %d)", numText, start)
+			}
+
+			// 数字后紧跟时间单位视为时长，例如 5m、30s、1h
+			unitStart := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			if unitStart == i {
+				tokens = append(tokens, token{kind: tokenNumber, text: numText, number: number, pos: start})
+				continue
+			}
+			unit := string(runes[unitStart:i])
+			duration, err := parseDurationUnit(number, unit)
+			if err != nil {
+				return nil, fmt.Errorf("%v (位置 %d)", err, start)
+			}
+			tokens = append(tokens, token{kind: tokenDuration, text: string(runes[start:i]), duration: duration, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == ':') {
+				i++
+			}
+			text := string(runes[start:i])
+			switch strings.ToLower(text) {
+			case "and", "or", "not":
+				tokens = append(tokens, token{kind: tokenOperator, text: strings.ToLower(text), pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: start})
+			}
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		default:
+			// 运算符，优先匹配两个字符的形式
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case ">=", "<=", "==", "!=", "&&", "||":
+					op := two
+					if op == "&&" {
+						op = "and"
+					} else if op == "||" {
+						op = "or"
+					}
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			switch r {
+			case '>', '<', '+', '-', '*', '/':
+				tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: i})
+			case '!':
+				tokens = append(tokens, token{kind: tokenOperator, text: "not", pos: i})
+			default:
+				return nil, fmt.Errorf("无法识别的字符 %q (位置 %d)", r, i)
+			}
+			i++
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// parseDurationUnit 解析带单位的时长
+func parseDurationUnit(value float64, unit string) (time.Duration, error) {
+	var base time.Duration
+	switch unit {
+	case "s":
+		base = time.Second
+	case "m":
+		base = time.Minute
+	case "h":
+		base = time.Hour
+	case "d":
+		base = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("不支持的时间单位 %q", unit)
+	}
+	return time.Duration(value * float64(base)), nil
+}
+
+// ParseDuration 解析规则中的持续时间，支持 30s、5m、1h、1d 以及纯数字（秒）
+func ParseDuration(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseFloat(text, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	i := 0
+	for i < len(text) && (text[i] == '.' || (text[i] >= '0' && text[i] <= '9')) {
+		i++
+	}
+	value, err := strconv.ParseFloat(text[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的持续时间 %q", text)
+	}
+	return parseDurationUnit(value, text[i:])
+}
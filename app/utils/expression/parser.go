@@ -0,0 +1,226 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parser 递归下降解析器
+// 优先级从低到高：or、and、not、比较、加减、乘除、一元负号
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isOperator 判断当前词法单元是否为指定运算符之一
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOperator("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "not", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator(">", ">=", "<", "<=", "==", "!=") {
+		op := p.next().text
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &numberNode{value: tok.number}, nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return &metricNode{name: tok.text}, nil
+
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, fmt.Errorf("位置 %d 处缺少右括号", p.peek().pos)
+		}
+		p.next()
+		return inner, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("表达式不完整")
+
+	case tokenDuration:
+		return nil, fmt.Errorf("时长 %s 只能作为聚合函数的参数 (位置 %d)", tok.text, tok.pos)
+	}
+
+	return nil, fmt.Errorf("位置 %d 处出现意外的 %q", tok.pos, tok.text)
+}
+
+// parseCall 解析函数调用，窗口函数形如 avg(cpu_usage, 5m)
+func (p *parser) parseCall(name token) (node, error) {
+	fn := strings.ToLower(name.text)
+	p.next() // (
+
+	if fn == "abs" {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, fmt.Errorf("abs 只接受一个参数 (位置 %d)", p.peek().pos)
+		}
+		p.next()
+		return &absNode{arg: arg}, nil
+	}
+
+	if !windowFuncs[fn] {
+		return nil, fmt.Errorf("不支持的函数 %s (位置 %d)", name.text, name.pos)
+	}
+
+	metric := p.next()
+	if metric.kind != tokenIdent {
+		return nil, fmt.Errorf("%s 的第一个参数必须是指标名称 (位置 %d)", fn, metric.pos)
+	}
+	if p.peek().kind != tokenComma {
+		return nil, fmt.Errorf("%s 缺少时间窗口参数，例如 %s(%s, 5m) (位置 %d)", fn, fn, metric.text, p.peek().pos)
+	}
+	p.next()
+
+	window := p.next()
+	if window.kind != tokenDuration {
+		return nil, fmt.Errorf("%s 的第二个参数必须是时长，例如 5m (位置 %d)", fn, window.pos)
+	}
+	if window.duration <= 0 || window.duration > MaxWindow {
+		return nil, fmt.Errorf("时间窗口 %s 超出范围，最大为 %.0fm", window.text, MaxWindow.Minutes())
+	}
+	if p.peek().kind != tokenRParen {
+		return nil, fmt.Errorf("位置 %d 处缺少右括号", p.peek().pos)
+	}
+	p.next()
+
+	return &windowNode{
+		fn:     fn,
+		metric: metric.text,
+		window: window.duration,
+		text:   fmt.Sprintf("%s(%s, %s)", fn, metric.text, window.text),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+)
+
+// barkServerURL Bark 官方服务地址
+const barkServerURL = "https://api.day.app"
+
+// BarkConfig Bark 推送配置
+type BarkConfig struct {
+	Enabled   bool   `json:"enabled"`
+	ServerURL string `json:"server_url"` // 自建服务地址，为空时使用官方地址
+	DeviceKey string `json:"device_key"`
+	Sound     string `json:"sound"` // 推送铃声，如 alarm、minuet，为空时使用默认铃声
+	Group     string `json:"group"` // 通知分组，为空时不分组
+}
+
+// barkResponse Bark 接口的响应
+type barkResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SendBark 通过 Bark 推送到 iOS 设备，点击通知时打开服务器详情页
+func SendBark(config BarkConfig, msg Message) error {
+	if config.DeviceKey == "" {
+		return fmt.Errorf("bark配置不完整")
+	}
+
+	message := map[string]interface{}{
+		"device_key": config.DeviceKey,
+		"title":      messageTitle(msg),
+		"body":       msg.Content,
+	}
+	if config.Sound != "" {
+		message["sound"] = config.Sound
+	}
+	if config.Group != "" {
+		message["group"] = config.Group
+	}
+	if msg.Link != "" {
+		message["url"] = msg.Link
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	serverURL := strings.TrimRight(config.ServerURL, "/")
+	if serverURL == "" {
+		serverURL = barkServerURL
+	}
+	resp, err := facades.Http().
+		WithHeaders(map[string]string{"Content-Type": "application/json"}).
+		Post(serverURL+"/push", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("bark请求失败: %v", err)
+	}
+
+	body, _ := resp.Body()
+	var result barkResponse
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return fmt.Errorf("bark接口返回错误状态码: %d, Body: %s", resp.Status(), body)
+	}
+	if resp.Failed() || result.Code != 200 {
+		return fmt.Errorf("bark接口返回错误: %d %s", result.Code, result.Message)
+	}
+	return nil
+}
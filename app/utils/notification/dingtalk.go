@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DingTalkMessageTypes 钉钉机器人支持的消息类型，为空时使用 markdown
+var DingTalkMessageTypes = []string{"", "markdown", "text"}
+
+// signDingTalkURL 为开启加签的钉钉机器人地址追加 timestamp 和 sign 参数
+// sign 为使用密钥对 "timestamp\nsecret" 做 HMAC-SHA256 后的 Base64 编码
+func signDingTalkURL(webhook, secret string, now time.Time) (string, error) {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return "", fmt.Errorf("webhook地址无效: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// dingTalkMessage 构建钉钉机器人消息：内容不含关键词时在开头补充关键词，@ 的手机号需要同时出现在内容中
+func dingTalkMessage(config WebhookConfig, content string) map[string]interface{} {
+	if config.Keyword != "" && !strings.Contains(content, config.Keyword) {
+		content = fmt.Sprintf("[%s] %s", config.Keyword, content)
+	}
+
+	at := map[string]interface{}{}
+	var mentions []string
+	if config.Mentioned == "@all" {
+		at["isAtAll"] = true
+	} else if config.Mentioned != "" {
+		mobiles := make([]string, 0)
+		for _, mobile := range strings.Split(config.Mentioned, ",") {
+			mobile = strings.TrimSpace(mobile)
+			if mobile != "" {
+				mobiles = append(mobiles, mobile)
+				mentions = append(mentions, "@"+mobile)
+			}
+		}
+		if len(mobiles) > 0 {
+			at["atMobiles"] = mobiles
+		}
+	}
+
+	if config.MessageType == "text" {
+		if len(mentions) > 0 {
+			content += "\n" + strings.Join(mentions, " ")
+		}
+		return map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]interface{}{"content": content},
+			"at":      at,
+		}
+	}
+
+	// markdown 消息以第一行作为会话列表中显示的标题，单个换行需要转换为 Markdown 的强制换行
+	title, _, _ := strings.Cut(content, "\n")
+	text := strings.ReplaceAll(content, "\n", "  \n")
+	if len(mentions) > 0 {
+		text += "  \n" + strings.Join(mentions, " ")
+	}
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": title,
+			"text":  text,
+		},
+		"at": at,
+	}
+}
+
+// checkDingTalkResponse 钉钉接口在 HTTP 200 中以 errcode 返回错误，如签名不匹配、缺少关键词或发送频率超限
+func checkDingTalkResponse(body string) error {
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return fmt.Errorf("钉钉接口返回无法解析的响应: %s", body)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("钉钉接口返回错误: %d %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Discord embed 的长度限制
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+)
+
+// discordColor 将 #rrggbb 格式的颜色转换为 Discord 使用的整数
+func discordColor(color string) (int64, bool) {
+	value, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// discordMessage 构建 Discord Webhook 消息：内容放在 embed 中，embed 颜色表示告警状态，标题链接到服务器详情页
+func discordMessage(config WebhookConfig, msg Message) map[string]interface{} {
+	embed := map[string]interface{}{
+		"title":       truncateRunes(messageTitle(msg), discordTitleLimit),
+		"description": truncateRunes(msg.Content, discordDescriptionLimit),
+	}
+	if color, ok := discordColor(msg.Color); ok {
+		embed["color"] = color
+	}
+	if msg.Link != "" {
+		embed["url"] = msg.Link
+	}
+
+	message := map[string]interface{}{
+		"embeds": []map[string]interface{}{embed},
+	}
+
+	// @all 提及所有人，其余按用户ID提及；只允许解析配置中的提及，避免内容中的 @ 误触发
+	allowed := map[string]interface{}{"parse": []string{}}
+	if config.Mentioned == "@all" {
+		message["content"] = "@everyone"
+		allowed["parse"] = []string{"everyone"}
+	} else if config.Mentioned != "" {
+		var mentions, users []string
+		for _, id := range strings.Split(config.Mentioned, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" {
+				mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+				users = append(users, id)
+			}
+		}
+		if len(mentions) > 0 {
+			message["content"] = strings.Join(mentions, " ")
+			allowed["users"] = users
+		}
+	}
+	message["allowed_mentions"] = allowed
+	return message
+}
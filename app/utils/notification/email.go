@@ -0,0 +1,397 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EmailAuthMethods 支持的 SMTP 认证方式，为空时按服务器支持的方式依次选择 PLAIN、LOGIN、CRAM-MD5
+var EmailAuthMethods = []string{"", "PLAIN", "LOGIN", "CRAM-MD5"}
+
+// emailDialTimeout 连接 SMTP 服务器的超时时间
+const emailDialTimeout = 10 * time.Second
+
+// EmailConfig 邮件配置
+type EmailConfig struct {
+	Enabled    bool   `json:"enabled"`
+	SMTP       string `json:"smtp"`
+	Port       int    `json:"port"`
+	Security   string `json:"security"` // NONE, STARTTLS, SSL
+	From       string `json:"from"`     // 发件人，可带显示名称，如 CloudSentinel <alert@example.com>
+	To         string `json:"to"`       // 收件人，多个地址以逗号或分号分隔，下同
+	Cc         string `json:"cc"`
+	Bcc        string `json:"bcc"`
+	Username   string `json:"username"` // 登录用户名，为空时使用发件人地址
+	Password   string `json:"password"`
+	AuthMethod string `json:"auth_method"` // PLAIN、LOGIN、CRAM-MD5，为空时自动选择
+	SkipVerify bool   `json:"skip_verify"` // 不校验服务器证书，仅用于自签名证书且无法提供 CA 证书的情况
+	CACert     string `json:"ca_cert"`     // 自定义 CA 证书（PEM），用于校验自签名或内网 CA 签发的服务器证书
+}
+
+// EmailMessage 邮件内容，同时提供 HTML 和纯文本正文时以 multipart/alternative 发送
+type EmailMessage struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// ParseEmailAddresses 解析以逗号或分号分隔的邮件地址列表
+func ParseEmailAddresses(list string) ([]*mail.Address, error) {
+	list = strings.TrimSpace(strings.ReplaceAll(list, ";", ","))
+	if list == "" {
+		return nil, nil
+	}
+	addresses, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("邮件地址 %s 无效: %v", list, err)
+	}
+	return addresses, nil
+}
+
+// EmailTLSConfig 构建连接 SMTP 服务器的 TLS 配置，默认校验服务器证书
+func EmailTLSConfig(config EmailConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.SMTP,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.SkipVerify,
+	}
+	if strings.TrimSpace(config.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACert)) {
+			return nil, errors.New("CA 证书无效，需为 PEM 格式")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// SendEmail 发送纯文本邮件
+func SendEmail(config EmailConfig, subject, content string) error {
+	return SendEmailMessage(config, EmailMessage{Subject: subject, Text: content})
+}
+
+// SendEmailMessage 发送邮件，收件人包括 To、Cc、Bcc 中的所有地址，Bcc 不写入邮件头
+func SendEmailMessage(config EmailConfig, msg EmailMessage) error {
+	if config.SMTP == "" || config.From == "" || config.To == "" {
+		return fmt.Errorf("邮件配置不完整")
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return fmt.Errorf("发件人地址无效: %v", err)
+	}
+	to, err := ParseEmailAddresses(config.To)
+	if err != nil {
+		return err
+	}
+	cc, err := ParseEmailAddresses(config.Cc)
+	if err != nil {
+		return err
+	}
+	bcc, err := ParseEmailAddresses(config.Bcc)
+	if err != nil {
+		return err
+	}
+
+	data, err := buildEmail(from, to, cc, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := EmailTLSConfig(config)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(config.SMTP, fmt.Sprint(config.Port))
+	dialer := &net.Dialer{Timeout: emailDialTimeout}
+	var c *smtp.Client
+
+	// 建立连接
+	security := strings.ToUpper(config.Security)
+	if security == "SSL" || security == "TLS" {
+		// SSL/TLS 模式
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+		if err != nil {
+			if strings.Contains(err.Error(), "first record does not look like a TLS handshake") {
+				return fmt.Errorf("TLS连接失败: 端口响应非TLS数据，请检查端口(通常465为SSL/TLS)或尝试STARTTLS模式: %v", err)
+			}
+			return fmt.Errorf("TLS连接失败: %v", err)
+		}
+		c, err = smtp.NewClient(conn, config.SMTP)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("连接SMTP服务器失败: %v", err)
+		}
+	} else {
+		// STARTTLS 或 明文模式
+		conn, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("连接SMTP服务器失败: %v", err)
+		}
+		c, err = smtp.NewClient(conn, config.SMTP)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("连接SMTP服务器失败: %v", err)
+		}
+	}
+
+	defer func() {
+		_ = c.Quit()
+		_ = c.Close()
+	}()
+
+	// STARTTLS 升级
+	if security == "STARTTLS" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("STARTTLS升级失败: 服务器不支持 STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS升级失败: %v", err)
+		}
+	}
+
+	// 认证
+	if config.Password != "" {
+		auth, err := emailAuth(c, config)
+		if err != nil {
+			return err
+		}
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP认证失败: %v", err)
+		}
+	}
+
+	// 发送邮件
+	if err = c.Mail(from.Address); err != nil {
+		return fmt.Errorf("设置发件人失败: %v", err)
+	}
+	for _, recipient := range slices.Concat(to, cc, bcc) {
+		if err = c.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("设置收件人 %s 失败: %v", recipient.Address, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("创建数据写入器失败: %v", err)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("写入邮件内容失败: %v", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("发送邮件数据失败: %v", err)
+	}
+
+	return nil
+}
+
+// emailAuth 按配置或服务器声明支持的认证方式选择认证
+func emailAuth(c *smtp.Client, config EmailConfig) (smtp.Auth, error) {
+	username := config.Username
+	if username == "" {
+		if from, err := mail.ParseAddress(config.From); err == nil {
+			username = from.Address
+		}
+	}
+
+	method := strings.ToUpper(config.AuthMethod)
+	if method == "" {
+		_, params := c.Extension("AUTH")
+		supported := strings.Fields(strings.ToUpper(params))
+		method = "PLAIN"
+		for _, m := range []string{"PLAIN", "LOGIN", "CRAM-MD5"} {
+			if slices.Contains(supported, m) {
+				method = m
+				break
+			}
+		}
+	}
+
+	switch method {
+	case "PLAIN":
+		return smtp.PlainAuth("", username, config.Password, config.SMTP), nil
+	case "LOGIN":
+		return &loginAuth{username: username, password: config.Password, host: config.SMTP}, nil
+	case "CRAM-MD5":
+		return smtp.CRAMMD5Auth(username, config.Password), nil
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", config.AuthMethod)
+	}
+}
+
+// loginAuth 实现 LOGIN 认证，与 PLAIN 一样只允许在加密连接或本机上发送密码
+type loginAuth struct {
+	username string
+	password string
+	host     string
+	step     int
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	a.step = 0
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	// 服务器依次询问用户名和密码，提示语不规范时按顺序回答
+	prompt := strings.ToLower(string(fromServer))
+	a.step++
+	switch {
+	case strings.Contains(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.Contains(prompt, "password"):
+		return []byte(a.password), nil
+	case a.step == 1:
+		return []byte(a.username), nil
+	case a.step == 2:
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// buildEmail 构建邮件，非 ASCII 的标题和显示名称按 RFC 2047 编码，正文使用 quoted-printable 编码
+func buildEmail(from *mail.Address, to, cc []*mail.Address, msg EmailMessage, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	header("From", from.String())
+	header("To", joinAddresses(to))
+	if len(cc) > 0 {
+		header("Cc", joinAddresses(cc))
+	}
+	header("Subject", mime.BEncoding.Encode("UTF-8", strings.Join(strings.Fields(msg.Subject), " ")))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address, now))
+	header("MIME-Version", "1.0")
+
+	text := msg.Text
+	if text == "" && msg.HTML != "" {
+		text = htmlToText(msg.HTML)
+	}
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=UTF-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// 邮件客户端优先显示最后一个可显示的部分，HTML 放在纯文本之后
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(normalizeLineEndings(part.body))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable 以 quoted-printable 编码写入正文
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(normalizeLineEndings(body))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// normalizeLineEndings 邮件正文统一使用 CRLF 换行
+func normalizeLineEndings(body string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+}
+
+func joinAddresses(addresses []*mail.Address) string {
+	items := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		items = append(items, address.String())
+	}
+	return strings.Join(items, ", ")
+}
+
+// messageID 生成邮件的 Message-ID，域名取发件人地址的域名
+func messageID(from string, now time.Time) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok && d != "" {
+		domain = d
+	}
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", now.UnixNano(), hex.EncodeToString(random), domain)
+}
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|h[1-6]|li|table)>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// htmlToText 没有纯文本正文时从 HTML 中提取文本，作为 multipart/alternative 的纯文本部分
+func htmlToText(content string) string {
+	text := htmlBlockPattern.ReplaceAllString(content, "")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,324 @@
+package notification
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpSession 模拟的 SMTP 服务器在一次连接中收到的内容
+type smtpSession struct {
+	tls        bool
+	recipients []string
+	data       string
+	err        error // TLS 握手失败等原因导致连接中断时的错误
+}
+
+// smtpServer 模拟 SMTP 服务器，implicitTLS 为 true 时连接建立即进行 TLS 握手，否则支持 STARTTLS
+type smtpServer struct {
+	listener net.Listener
+	port     int
+	caCert   string
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	sessions []*smtpSession
+}
+
+func newSMTPServer(t *testing.T, implicitTLS bool) *smtpServer {
+	t.Helper()
+	cert, caCert := newTestCertificate(t)
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	if implicitTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s := &smtpServer{listener: listener, port: listener.Addr().(*net.TCPAddr).Port, caCert: caCert}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			session := &smtpSession{tls: implicitTLS}
+			s.mu.Lock()
+			s.sessions = append(s.sessions, session)
+			s.mu.Unlock()
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn, session, tlsConfig)
+			}()
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn, session *smtpSession, tlsConfig *tls.Config) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	tp := textproto.NewConn(conn)
+	if err := tp.PrintfLine("220 test ESMTP"); err != nil {
+		session.err = err
+		return
+	}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			if !session.tls {
+				_ = tp.PrintfLine("250-test")
+				_ = tp.PrintfLine("250 STARTTLS")
+			} else {
+				_ = tp.PrintfLine("250 test")
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				session.err = err
+				return
+			}
+			conn, session.tls = tlsConn, true
+			tp = textproto.NewConn(tlsConn)
+		case "RCPT":
+			session.recipients = append(session.recipients, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			session.data = string(data)
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// lastSession 等待所有连接结束后返回最后一次连接收到的内容
+func (s *smtpServer) lastSession(t *testing.T) *smtpSession {
+	t.Helper()
+	s.wg.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sessions) == 0 {
+		t.Fatal("SMTP 服务器未收到连接")
+	}
+	return s.sessions[len(s.sessions)-1]
+}
+
+func (s *smtpServer) config(security string) EmailConfig {
+	return EmailConfig{
+		Enabled:  true,
+		SMTP:     "127.0.0.1",
+		Port:     s.port,
+		Security: security,
+		From:     "CloudSentinel <alert@example.com>",
+		To:       "ops@example.com",
+	}
+}
+
+// newTestCertificate 生成 127.0.0.1 的自签名证书，返回证书和 PEM 格式的 CA 证书
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	cert, err := tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, string(certPEM)
+}
+
+func TestSendEmailVerifiesCertificate(t *testing.T) {
+	cases := []struct {
+		name        string
+		security    string
+		implicitTLS bool
+		wantErr     string
+	}{
+		{"STARTTLS", "STARTTLS", false, "STARTTLS升级失败"},
+		{"SSL", "SSL", true, "TLS连接失败"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := newSMTPServer(t, c.implicitTLS)
+			config := server.config(c.security)
+
+			// 默认校验服务器证书，自签名证书校验失败
+			err := SendEmail(config, "告警", "内容")
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) || !strings.Contains(err.Error(), "certificate") {
+				t.Fatalf("自签名证书应校验失败，错误为 %v", err)
+			}
+			if session := server.lastSession(t); session.data != "" {
+				t.Error("证书校验失败时不应发送邮件")
+			}
+
+			// 配置 CA 证书后校验通过
+			config.CACert = server.caCert
+			if err := SendEmail(config, "告警", "内容"); err != nil {
+				t.Fatalf("配置 CA 证书后发送失败: %v", err)
+			}
+			if session := server.lastSession(t); !session.tls || session.data == "" {
+				t.Errorf("应通过 TLS 发送邮件，收到 %+v", session)
+			}
+
+			// 跳过校验时同样可以发送
+			config.CACert = ""
+			config.SkipVerify = true
+			if err := SendEmail(config, "告警", "内容"); err != nil {
+				t.Fatalf("跳过证书校验后发送失败: %v", err)
+			}
+			if session := server.lastSession(t); !session.tls || session.data == "" {
+				t.Errorf("应通过 TLS 发送邮件，收到 %+v", session)
+			}
+		})
+	}
+}
+
+func TestSendEmailRecipients(t *testing.T) {
+	server := newSMTPServer(t, false)
+	config := server.config("NONE")
+	config.To = "ops@example.com; 张三 <zhangsan@example.com>"
+	config.Cc = "lead@example.com"
+	config.Bcc = "audit@example.com, archive@example.com"
+
+	if err := SendEmail(config, "告警", "内容"); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	session := server.lastSession(t)
+
+	want := []string{"ops@example.com", "zhangsan@example.com", "lead@example.com", "audit@example.com", "archive@example.com"}
+	if strings.Join(session.recipients, ",") != strings.Join(want, ",") {
+		t.Errorf("收件人为 %v，期望 %v", session.recipients, want)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(session.data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 2 || to[1].Name != "张三" || to[1].Address != "zhangsan@example.com" {
+		t.Errorf("To 为 %q", msg.Header.Get("To"))
+	}
+	if msg.Header.Get("Cc") != "<lead@example.com>" {
+		t.Errorf("Cc 为 %q", msg.Header.Get("Cc"))
+	}
+	// 密送地址只出现在 RCPT TO 中，不写入邮件头
+	if _, ok := msg.Header["Bcc"]; ok {
+		t.Error("邮件头中不应包含 Bcc")
+	}
+	header, _, _ := strings.Cut(session.data, "\n\n")
+	for _, address := range []string{"audit@example.com", "archive@example.com"} {
+		if strings.Contains(header, address) {
+			t.Errorf("邮件头中包含密送地址 %s", address)
+		}
+	}
+}
+
+func TestSendEmailMultipart(t *testing.T) {
+	server := newSMTPServer(t, false)
+	subject := "[严重] web-01 - CPU使用率"
+	text := "CPU使用率: 95.50%\n阈值: 90.00%"
+	htmlBody := "<p>CPU使用率: <b>95.50%</b></p>"
+
+	if err := SendEmailMessage(server.config("NONE"), EmailMessage{Subject: subject, Text: text, HTML: htmlBody}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(server.lastSession(t).data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+
+	// 非 ASCII 标题按 RFC 2047 以 UTF-8 B 编码
+	rawSubject := msg.Header.Get("Subject")
+	if len(rawSubject) < 10 || !strings.EqualFold(rawSubject[:10], "=?UTF-8?B?") {
+		t.Errorf("Subject 为 %q，期望 UTF-8 B 编码", rawSubject)
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(rawSubject); err != nil || decoded != subject {
+		t.Errorf("Subject 解码为 %q (%v)，期望 %q", decoded, err, subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type 为 %q", msg.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	wantParts := []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", text}, // 服务器读取 DATA 时已将 CRLF 转换为 LF
+		{"text/html; charset=UTF-8", htmlBody},
+	}
+	for _, want := range wantParts {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("读取 %s 部分失败: %v", want.contentType, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("Content-Type 为 %q，期望 %q", got, want.contentType)
+		}
+		// multipart.Reader 会自动解码 quoted-printable
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != want.body {
+			t.Errorf("%s 正文为 %q，期望 %q", want.contentType, body, want.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("邮件应只包含纯文本和 HTML 两部分，err=%v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+)
+
+// GotifyConfig Gotify 推送配置
+type GotifyConfig struct {
+	Enabled   bool   `json:"enabled"`
+	ServerURL string `json:"server_url"`
+	AppToken  string `json:"app_token"` // 应用令牌
+	Priority  int    `json:"priority"`  // 1-10，客户端通常在 4 以上发出提醒、8 以上弹出通知，为 0 时使用应用的默认优先级
+}
+
+// SendGotify 通过 Gotify 应用令牌发送消息，客户端点击通知时打开服务器详情页
+func SendGotify(config GotifyConfig, msg Message) error {
+	if config.ServerURL == "" || config.AppToken == "" {
+		return fmt.Errorf("gotify配置不完整")
+	}
+
+	message := map[string]interface{}{
+		"title":   messageTitle(msg),
+		"message": msg.Content,
+	}
+	if config.Priority > 0 {
+		message["priority"] = config.Priority
+	}
+	if msg.Link != "" {
+		message["extras"] = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": msg.Link},
+			},
+		}
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	// 令牌通过请求头传递，避免出现在请求地址和错误信息中
+	resp, err := facades.Http().
+		WithHeaders(map[string]string{
+			"Content-Type": "application/json",
+			"X-Gotify-Key": config.AppToken,
+		}).
+		Post(strings.TrimRight(config.ServerURL, "/")+"/message", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("gotify请求失败: %v", err)
+	}
+
+	if resp.Failed() {
+		body, _ := resp.Body()
+		return fmt.Errorf("gotify接口返回错误状态码: %d, Body: %s", resp.Status(), body)
+	}
+	return nil
+}
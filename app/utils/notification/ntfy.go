@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ntfyServerURL ntfy 官方服务地址
+const ntfyServerURL = "https://ntfy.sh"
+
+// NtfyConfig ntfy 推送配置
+type NtfyConfig struct {
+	Enabled   bool   `json:"enabled"`
+	ServerURL string `json:"server_url"` // 自建服务地址，为空时使用 ntfy.sh
+	Topic     string `json:"topic"`
+	Priority  int    `json:"priority"` // 1-5，为 0 时使用服务端默认优先级
+	Tags      string `json:"tags"`     // 逗号分隔的标签，可使用 emoji 简码，如 warning,server
+	Token     string `json:"token"`    // 访问令牌，与用户名密码二选一
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+// SendNtfy 通过 ntfy 的 JSON 接口发布消息，点击通知时打开服务器详情页
+func SendNtfy(config NtfyConfig, msg Message) error {
+	if config.Topic == "" {
+		return fmt.Errorf("ntfy配置不完整")
+	}
+
+	message := map[string]interface{}{
+		"topic":   config.Topic,
+		"title":   messageTitle(msg),
+		"message": msg.Content,
+	}
+	if config.Priority > 0 {
+		message["priority"] = config.Priority
+	}
+	if tags := splitList(config.Tags); len(tags) > 0 {
+		message["tags"] = tags
+	}
+	if msg.Link != "" {
+		message["click"] = msg.Link
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	serverURL := strings.TrimRight(config.ServerURL, "/")
+	if serverURL == "" {
+		serverURL = ntfyServerURL
+	}
+	request := facades.Http().WithHeaders(map[string]string{"Content-Type": "application/json"})
+	if config.Token != "" {
+		request = request.WithToken(config.Token)
+	} else if config.Username != "" {
+		request = request.WithBasicAuth(config.Username, config.Password)
+	}
+	resp, err := request.Post(serverURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("ntfy请求失败: %v", err)
+	}
+
+	if resp.Failed() {
+		body, _ := resp.Body()
+		return fmt.Errorf("ntfy接口返回错误状态码: %d, Body: %s", resp.Status(), body)
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的列表，去掉空白项
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
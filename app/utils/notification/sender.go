@@ -2,133 +2,71 @@ package notification
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"net/smtp"
+	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	"goravel/app/utils/notifytemplate"
+
+	"github.com/goravel/framework/contracts/http/client"
 	"github.com/goravel/framework/facades"
 )
 
-// EmailConfig 邮件配置
-type EmailConfig struct {
-	Enabled  bool   `json:"enabled"`
-	SMTP     string `json:"smtp"`
-	Port     int    `json:"port"`
-	Security string `json:"security"` // NONE, STARTTLS, SSL
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Password string `json:"password"`
-}
-
 // WebhookConfig Webhook配置
 type WebhookConfig struct {
-	Enabled   bool   `json:"enabled"`
-	Webhook   string `json:"webhook"`
-	Mentioned string `json:"mentioned"`
-	Platform  string `json:"platform"` // feishu, wechat, generic
+	Enabled     bool   `json:"enabled"`
+	Webhook     string `json:"webhook"`
+	Mentioned   string `json:"mentioned"`
+	Platform    string `json:"platform"`     // feishu, wechat, dingtalk, slack, discord, generic
+	Secret      string `json:"secret"`       // 钉钉加签密钥或通用 Webhook 的 X-Signature 签名密钥，为空时不签名
+	Keyword     string `json:"keyword"`      // 钉钉自定义关键词，消息中不含关键词时自动补充
+	MessageType string `json:"message_type"` // 钉钉消息类型：markdown、text，为空时使用 markdown
+
+	// 以下仅通用平台使用
+	BodyTemplate string            `json:"body_template"` // 请求体模板，为空时使用默认的文本消息格式
+	Headers      map[string]string `json:"headers"`       // 自定义请求头
+	Method       string            `json:"method"`        // POST、PUT、PATCH，为空时使用 POST
+	SuccessCodes []int             `json:"success_codes"` // 视为发送成功的状态码，为空时 2xx 均视为成功
 }
 
-func SendEmail(config EmailConfig, subject, content string) error {
-	if config.SMTP == "" || config.From == "" || config.To == "" {
-		return fmt.Errorf("邮件配置不完整")
-	}
-
-	msg := bytes.Buffer{}
-	msg.WriteString(fmt.Sprintf("From: %s\r\n", config.From))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", config.To))
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-
-	// 根据内容自动判断是 HTML 还是纯文本
-	contentType := "text/plain; charset=UTF-8"
-	if strings.HasPrefix(strings.TrimSpace(content), "<") {
-		// 如果内容以 HTML 标签开头，则认为是 HTML
-		contentType = "text/html; charset=UTF-8"
-	}
-
-	msg.WriteString(fmt.Sprintf("Content-Type: %s\r\n", contentType))
-	msg.WriteString("\r\n")
-	msg.WriteString(content)
-
-	addr := fmt.Sprintf("%s:%d", config.SMTP, config.Port)
-	var c *smtp.Client
-	var err error
-
-	// 建立连接
-	if strings.ToUpper(config.Security) == "SSL" || strings.ToUpper(config.Security) == "TLS" {
-		// SSL/TLS 模式
-		tlsConfig := &tls.Config{
-			ServerName:         config.SMTP,
-			InsecureSkipVerify: true, // 允许自签名证书
-		}
-		conn, err := tls.Dial("tcp", addr, tlsConfig)
-		if err != nil {
-			if strings.Contains(err.Error(), "first record does not look like a TLS handshake") {
-				return fmt.Errorf("TLS连接失败: 端口响应非TLS数据，请检查端口(通常465为SSL/TLS)或尝试STARTTLS模式: %v", err)
-			}
-			return fmt.Errorf("TLS连接失败: %v", err)
-		}
-		c, err = smtp.NewClient(conn, config.SMTP)
-	} else {
-		// STARTTLS 或 明文模式
-		c, err = smtp.Dial(addr)
-	}
-
-	if err != nil {
-		return fmt.Errorf("连接SMTP服务器失败: %v", err)
-	}
-
-	defer func() {
-		_ = c.Quit()
-		_ = c.Close()
-	}()
-
-	// STARTTLS 升级
-	if strings.ToUpper(config.Security) == "STARTTLS" {
-		tlsConfig := &tls.Config{
-			ServerName:         config.SMTP,
-			InsecureSkipVerify: true,
-		}
-		if err := c.StartTLS(tlsConfig); err != nil {
-			return fmt.Errorf("STARTTLS升级失败: %v", err)
-		}
-	}
-
-	// 认证
-	if config.Password != "" {
-		auth := smtp.PlainAuth("", config.From, config.Password, config.SMTP)
-		if err := c.Auth(auth); err != nil {
-			return fmt.Errorf("SMTP认证失败: %v", err)
-		}
-	}
-
-	// 发送邮件
-	if err = c.Mail(config.From); err != nil {
-		return fmt.Errorf("设置发件人失败: %v", err)
-	}
-	if err = c.Rcpt(config.To); err != nil {
-		return fmt.Errorf("设置收件人失败: %v", err)
-	}
-
-	w, err := c.Data()
-	if err != nil {
-		return fmt.Errorf("创建数据写入器失败: %v", err)
-	}
+// Message Webhook 消息，Title、Color、Link 仅在支持富文本的平台中使用
+type Message struct {
+	Title   string
+	Content string
+	Color   string               // 状态颜色，如 #ff4d4f
+	Link    string               // 服务器详情页链接
+	Event   string               // 事件类型，通用 Webhook 请求体模板使用
+	Data    *notifytemplate.Data // 事件数据，通用 Webhook 请求体模板使用，汇总和测试通知为空
+}
 
-	_, err = w.Write(msg.Bytes())
-	if err != nil {
-		return fmt.Errorf("写入邮件内容失败: %v", err)
+// truncateRunes 按字符截断文本，超出时以省略号结尾
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
 	}
+	return string(runes[:limit-3]) + "..."
+}
 
-	if err = w.Close(); err != nil {
-		return fmt.Errorf("发送邮件数据失败: %v", err)
+// messageTitle 消息标题，未指定时使用内容的第一行
+func messageTitle(msg Message) string {
+	if msg.Title != "" {
+		return msg.Title
 	}
-
-	return nil
+	title, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+	return title
 }
 
 func SendWebhook(config WebhookConfig, content string) error {
+	return SendWebhookMessage(config, Message{Content: content})
+}
+
+// SendWebhookMessage 按平台格式发送 Webhook 消息
+func SendWebhookMessage(config WebhookConfig, msg Message) error {
+	content := msg.Content
 	if config.Webhook == "" {
 		return fmt.Errorf("webhook配置不完整")
 	}
@@ -188,22 +126,21 @@ func SendWebhook(config WebhookConfig, content string) error {
 				message["text"].(map[string]interface{})["mentioned_list"] = trimmedIDs
 			}
 		}
-	case "generic":
-		// 通用平台，不支持提及功能
-		message = map[string]interface{}{
-			"msgtype": "text",
-			"text": map[string]interface{}{
-				"content": content,
-			},
-		}
+	case "dingtalk":
+		// 钉钉
+		// 结构: {"msgtype": "markdown", "markdown": {"title": "...", "text": "..."}, "at": {"atMobiles": [...]}}
+		message = dingTalkMessage(config, content)
+	case "slack":
+		// Slack
+		// 结构: {"text": "...", "attachments": [{"color": "...", "blocks": [...]}]}
+		message = slackMessage(config, msg)
+	case "discord":
+		// Discord
+		// 结构: {"content": "...", "embeds": [{"title": "...", "description": "...", "color": 0}]}
+		message = discordMessage(config, msg)
 	default:
-		// 未知平台，使用通用格式
-		message = map[string]interface{}{
-			"msgtype": "text",
-			"text": map[string]interface{}{
-				"content": content,
-			},
-		}
+		// 通用平台及未知平台，不支持提及功能
+		return sendGenericWebhook(config, msg)
 	}
 
 	jsonData, err := json.Marshal(message)
@@ -211,9 +148,16 @@ func SendWebhook(config WebhookConfig, content string) error {
 		return err
 	}
 
+	webhookURL := config.Webhook
+	if platform == "dingtalk" && config.Secret != "" {
+		if webhookURL, err = signDingTalkURL(config.Webhook, config.Secret, time.Now()); err != nil {
+			return err
+		}
+	}
+
 	resp, err := facades.Http().
 		WithHeaders(map[string]string{"Content-Type": "application/json"}).
-		Post(config.Webhook, bytes.NewBuffer(jsonData))
+		Post(webhookURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -223,5 +167,66 @@ func SendWebhook(config WebhookConfig, content string) error {
 		return fmt.Errorf("webhook接口返回错误状态码: %d, Body: %s", resp.Status(), body)
 	}
 
+	if platform == "dingtalk" {
+		body, _ := resp.Body()
+		return checkDingTalkResponse(body)
+	}
+
+	return nil
+}
+
+// sendGenericWebhook 发送通用 Webhook：按请求体模板构建 JSON，附加自定义请求头和签名，按配置的方法和成功状态码请求
+func sendGenericWebhook(config WebhookConfig, msg Message) error {
+	var jsonData []byte
+	var err error
+	if strings.TrimSpace(config.BodyTemplate) != "" {
+		if jsonData, err = RenderWebhookBody(config.BodyTemplate, msg); err != nil {
+			return err
+		}
+	} else {
+		jsonData, err = json.Marshal(map[string]interface{}{
+			"msgtype": "text",
+			"text": map[string]interface{}{
+				"content": msg.Content,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for name, value := range config.Headers {
+		headers[name] = value
+	}
+	if config.Secret != "" {
+		headers[webhookSignatureHeader] = SignWebhookBody(config.Secret, jsonData)
+	}
+
+	request := facades.Http().WithHeaders(headers)
+	var resp client.Response
+	switch strings.ToUpper(config.Method) {
+	case "", http.MethodPost:
+		resp, err = request.Post(config.Webhook, bytes.NewReader(jsonData))
+	case http.MethodPut:
+		resp, err = request.Put(config.Webhook, bytes.NewReader(jsonData))
+	case http.MethodPatch:
+		resp, err = request.Patch(config.Webhook, bytes.NewReader(jsonData))
+	default:
+		return fmt.Errorf("不支持的请求方法: %s", config.Method)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := resp.Failed()
+	if len(config.SuccessCodes) > 0 {
+		failed = !slices.Contains(config.SuccessCodes, resp.Status())
+	}
+	if failed {
+		body, _ := resp.Body()
+		return fmt.Errorf("webhook接口返回错误状态码: %d, Body: %s", resp.Status(), body)
+	}
+
 	return nil
 }
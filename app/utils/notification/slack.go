@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Slack Block Kit 文本块的长度限制
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+)
+
+// slackEscape 转义 Slack mrkdwn 中的控制字符
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackMentions 将提及配置转换为 Slack 格式：@all 提及频道所有人，其余按成员ID提及
+func slackMentions(mentioned string) string {
+	if mentioned == "@all" {
+		return "<!channel>"
+	}
+	var mentions []string
+	for _, id := range strings.Split(mentioned, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+// slackMessage 构建 Slack Incoming Webhook 消息：Block Kit 内容放在带颜色的 attachment 中，颜色条表示告警状态
+func slackMessage(config WebhookConfig, msg Message) map[string]interface{} {
+	title := messageTitle(msg)
+	text := slackEscape(msg.Content)
+	if mentions := slackMentions(config.Mentioned); mentions != "" {
+		text += "\n" + mentions
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": truncateRunes(title, slackHeaderLimit)},
+		},
+		{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": truncateRunes(text, slackSectionLimit)},
+		},
+	}
+	if msg.Link != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []map[string]interface{}{
+				{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": "查看服务器"},
+					"url":  msg.Link,
+				},
+			},
+		})
+	}
+
+	attachment := map[string]interface{}{"blocks": blocks}
+	if msg.Color != "" {
+		attachment["color"] = msg.Color
+	}
+	return map[string]interface{}{
+		// text 用于系统通知和不支持 Block Kit 的客户端
+		"text":        title,
+		"attachments": []map[string]interface{}{attachment},
+	}
+}
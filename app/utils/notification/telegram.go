@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+)
+
+// telegramAPIURL Telegram Bot API 官方地址
+const telegramAPIURL = "https://api.telegram.org"
+
+// telegramMaxLength Telegram 单条消息的最大字符数
+const telegramMaxLength = 4096
+
+// TelegramParseModes Telegram 支持的消息解析模式，为空时按纯文本发送
+var TelegramParseModes = []string{"", "HTML", "MarkdownV2", "Markdown"}
+
+// TelegramConfig Telegram 机器人配置
+type TelegramConfig struct {
+	Enabled   bool   `json:"enabled"`
+	BotToken  string `json:"bot_token"`
+	ChatID    string `json:"chat_id"`    // 用户、群组或频道ID，频道也可使用 @username
+	ThreadID  int64  `json:"thread_id"`  // 话题群组中的话题ID，为 0 时发送到默认话题
+	ParseMode string `json:"parse_mode"` // HTML、MarkdownV2、Markdown，为空时按纯文本发送
+	APIURL    string `json:"api_url"`    // 自建 Bot API 服务地址，为空时使用官方地址
+}
+
+// telegramResponse Bot API 的响应
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// SendTelegram 通过 Telegram 机器人发送消息；按解析模式发送失败时（如内容中含未转义的标记字符）改为纯文本重发
+func SendTelegram(config TelegramConfig, content string) error {
+	if config.BotToken == "" || config.ChatID == "" {
+		return fmt.Errorf("telegram配置不完整")
+	}
+
+	if runes := []rune(content); len(runes) > telegramMaxLength {
+		content = string(runes[:telegramMaxLength-3]) + "..."
+	}
+
+	result, err := sendTelegramMessage(config, content, config.ParseMode)
+	if err != nil {
+		return err
+	}
+	if !result.OK && config.ParseMode != "" && strings.Contains(result.Description, "can't parse entities") {
+		facades.Log().Warningf("Telegram 消息按 %s 解析失败，改为纯文本发送: %s", config.ParseMode, result.Description)
+		if result, err = sendTelegramMessage(config, content, ""); err != nil {
+			return err
+		}
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram接口返回错误: %d %s", result.ErrorCode, result.Description)
+	}
+
+	return nil
+}
+
+// sendTelegramMessage 调用 sendMessage 接口
+func sendTelegramMessage(config TelegramConfig, content, parseMode string) (*telegramResponse, error) {
+	message := map[string]interface{}{
+		"chat_id": config.ChatID,
+		"text":    content,
+	}
+	if config.ThreadID > 0 {
+		message["message_thread_id"] = config.ThreadID
+	}
+	if parseMode != "" {
+		message["parse_mode"] = parseMode
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := strings.TrimRight(config.APIURL, "/")
+	if apiURL == "" {
+		apiURL = telegramAPIURL
+	}
+	resp, err := facades.Http().
+		WithHeaders(map[string]string{"Content-Type": "application/json"}).
+		Post(fmt.Sprintf("%s/bot%s/sendMessage", apiURL, config.BotToken), bytes.NewBuffer(jsonData))
+	if err != nil {
+		// 请求地址中包含 Bot Token，错误信息中不返回
+		return nil, fmt.Errorf("telegram请求失败: %s", strings.ReplaceAll(err.Error(), config.BotToken, "***"))
+	}
+
+	body, _ := resp.Body()
+	var result telegramResponse
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return nil, fmt.Errorf("telegram接口返回错误状态码: %d, Body: %s", resp.Status(), body)
+	}
+	return &result, nil
+}
@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"goravel/tests"
+)
+
+const testBotToken = "123456:TEST-token"
+
+// telegramServer 模拟 Bot API，按顺序返回 responses 中的响应，并记录收到的消息
+type telegramServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	paths     []string
+	messages  []map[string]interface{}
+	responses []string
+}
+
+func newTelegramServer(t *testing.T, responses ...string) *telegramServer {
+	t.Helper()
+	tests.NewApp(t)
+
+	s := &telegramServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.messages = append(s.messages, message)
+		response := `{"ok":true}`
+		if len(s.responses) > 0 {
+			response, s.responses = s.responses[0], s.responses[1:]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *telegramServer) config() TelegramConfig {
+	return TelegramConfig{Enabled: true, BotToken: testBotToken, ChatID: "-1001234567890", APIURL: s.URL + "/"}
+}
+
+func TestSendTelegramOptions(t *testing.T) {
+	server := newTelegramServer(t)
+	config := server.config()
+	config.ThreadID = 42
+	config.ParseMode = "HTML"
+
+	if err := SendTelegram(config, "<b>CPU</b> 告警"); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	if len(server.messages) != 1 {
+		t.Fatalf("发送了 %d 条消息，期望 1 条", len(server.messages))
+	}
+	if server.paths[0] != "/bot"+testBotToken+"/sendMessage" {
+		t.Errorf("请求路径为 %s", server.paths[0])
+	}
+	message := server.messages[0]
+	if message["chat_id"] != config.ChatID || message["text"] != "<b>CPU</b> 告警" {
+		t.Errorf("消息为 %v", message)
+	}
+	if message["message_thread_id"] != float64(42) {
+		t.Errorf("message_thread_id 为 %v，期望 42", message["message_thread_id"])
+	}
+	if message["parse_mode"] != "HTML" {
+		t.Errorf("parse_mode 为 %v，期望 HTML", message["parse_mode"])
+	}
+
+	// 未配置话题和解析模式时不传这两个参数
+	if err := SendTelegram(server.config(), "纯文本"); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	for _, key := range []string{"message_thread_id", "parse_mode"} {
+		if _, ok := server.messages[1][key]; ok {
+			t.Errorf("未配置时不应传 %s", key)
+		}
+	}
+}
+
+func TestSendTelegramParseErrorFallback(t *testing.T) {
+	server := newTelegramServer(t,
+		`{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Unsupported start tag \"cpu\" at byte offset 1"}`,
+		`{"ok":true}`,
+	)
+	config := server.config()
+	config.ParseMode = "HTML"
+
+	if err := SendTelegram(config, "<cpu> 使用率 95%"); err != nil {
+		t.Fatalf("纯文本重发后应成功: %v", err)
+	}
+	if len(server.messages) != 2 {
+		t.Fatalf("发送了 %d 条消息，期望按纯文本重发 1 次", len(server.messages))
+	}
+	if server.messages[0]["parse_mode"] != "HTML" {
+		t.Errorf("首次发送的 parse_mode 为 %v", server.messages[0]["parse_mode"])
+	}
+	if _, ok := server.messages[1]["parse_mode"]; ok {
+		t.Error("重发时不应传 parse_mode")
+	}
+	if server.messages[1]["text"] != "<cpu> 使用率 95%" {
+		t.Errorf("重发的内容为 %v", server.messages[1]["text"])
+	}
+}
+
+func TestSendTelegramError(t *testing.T) {
+	server := newTelegramServer(t, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+	config := server.config()
+	config.ParseMode = "HTML"
+
+	// 其他错误不重发
+	err := SendTelegram(config, "告警")
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden: bot was blocked by the user") {
+		t.Fatalf("错误为 %v", err)
+	}
+	if len(server.messages) != 1 {
+		t.Errorf("发送了 %d 条消息，期望不重发", len(server.messages))
+	}
+}
+
+func TestSendTelegramTruncate(t *testing.T) {
+	server := newTelegramServer(t)
+
+	if err := SendTelegram(server.config(), strings.Repeat("告", telegramMaxLength+100)); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	text, _ := server.messages[0]["text"].(string)
+	if runes := []rune(text); len(runes) != telegramMaxLength {
+		t.Errorf("消息长度为 %d 个字符，期望截断为 %d", len(runes), telegramMaxLength)
+	}
+	if !strings.HasSuffix(text, "...") {
+		t.Error("截断的消息应以 ... 结尾")
+	}
+
+	// 未超过长度时不截断
+	content := strings.Repeat("告", telegramMaxLength)
+	if err := SendTelegram(server.config(), content); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if server.messages[1]["text"] != content {
+		t.Error("未超过长度的消息不应截断")
+	}
+}
+
+func TestSendTelegramRedactsToken(t *testing.T) {
+	server := newTelegramServer(t)
+	config := server.config()
+	server.Close()
+
+	err := SendTelegram(config, "告警")
+	if err == nil {
+		t.Fatal("服务不可用时应返回错误")
+	}
+	if strings.Contains(err.Error(), testBotToken) {
+		t.Errorf("错误信息中包含 Bot Token: %v", err)
+	}
+	if !strings.Contains(err.Error(), "***") {
+		t.Errorf("错误信息中应以 *** 代替 Bot Token: %v", err)
+	}
+}
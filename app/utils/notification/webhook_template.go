@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"goravel/app/utils/notifytemplate"
+)
+
+// WebhookMethods 通用 Webhook 支持的请求方法，为空时使用 POST
+var WebhookMethods = []string{"", http.MethodPost, http.MethodPut, http.MethodPatch}
+
+// webhookSignatureHeader 通用 Webhook 请求体签名的请求头，值为 sha256=<HMAC-SHA256 十六进制>
+const webhookSignatureHeader = "X-Signature"
+
+// webhookBodyData 通用 Webhook 请求体模板可用的变量，除通知模板的变量外还包括事件类型、渲染后的正文和服务器详情页链接
+type webhookBodyData struct {
+	notifytemplate.Data
+	Event   string // 事件类型，如 alert、recovery、server_offline、escalation，汇总和测试通知为空
+	Content string // 渲染后的 Webhook 正文
+	Link    string // 服务器详情页链接
+}
+
+// webhookBodyFuncs 请求体模板函数，json 将变量编码为 JSON 值，如 {"server": {{ json .ServerName }}}
+var webhookBodyFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		raw, err := json.Marshal(v)
+		return string(raw), err
+	},
+}
+
+// newWebhookBodyData 构建请求体模板变量，汇总和测试通知没有事件数据时只提供标题、正文和时间
+func newWebhookBodyData(msg Message) webhookBodyData {
+	data := notifytemplate.Data{Extra: map[string]string{}}
+	if msg.Data != nil {
+		data = *msg.Data
+	}
+	data.Title = messageTitle(msg)
+	if data.Timestamp == "" {
+		data.Timestamp = time.Now().Format("2006-01-02 15:04:05")
+	}
+	if data.Color == "" {
+		data.Color = msg.Color
+	}
+	return webhookBodyData{Data: data, Event: msg.Event, Content: msg.Content, Link: msg.Link}
+}
+
+// RenderWebhookBody 渲染通用 Webhook 请求体模板，渲染结果必须是合法的 JSON
+func RenderWebhookBody(bodyTemplate string, msg Message) ([]byte, error) {
+	t, err := template.New("body").Funcs(webhookBodyFuncs).Option("missingkey=error").Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("请求体模板解析失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, newWebhookBodyData(msg)); err != nil {
+		return nil, fmt.Errorf("请求体模板渲染失败: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.New("请求体模板渲染结果不是合法的 JSON，字符串变量请使用 {{ json .变量 }} 输出")
+	}
+	return buf.Bytes(), nil
+}
+
+// SignWebhookBody 使用共享密钥对请求体做 HMAC-SHA256 签名
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateWebhookHeaders 校验自定义请求头，名称只能包含字母、数字和连字符，值不能换行
+func ValidateWebhookHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if name == "" {
+			return errors.New("请求头名称不能为空")
+		}
+		for _, c := range name {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("请求头名称 %s 无效", name)
+			}
+		}
+		if strings.EqualFold(name, webhookSignatureHeader) || strings.EqualFold(name, "Content-Length") || strings.EqualFold(name, "Host") {
+			return fmt.Errorf("请求头 %s 不能自定义", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("请求头 %s 的值不能包含换行", name)
+		}
+	}
+	return nil
+}
+
+// ValidateWebhookSuccessCodes 校验视为发送成功的状态码
+func ValidateWebhookSuccessCodes(codes []int) error {
+	for _, code := range codes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("状态码 %d 无效", code)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package notifytemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// 通知渠道
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// 通知事件类型
+const (
+	EventAlert         = "alert"          // 阈值、表达式、异常检测等规则进入告警或告警级别变化
+	EventRecovery      = "recovery"       // 规则告警恢复
+	EventBandwidth     = "bandwidth"      // 带宽峰值告警
+	EventTraffic       = "traffic"        // 流量耗尽告警
+	EventExpiration    = "expiration"     // 服务器到期提醒
+	EventServerOffline = "server_offline" // 服务器离线
+	EventServerOnline  = "server_online"  // 服务器上线
+	EventServiceDown   = "service_down"   // 被监控的服务停止
+	EventServiceUp     = "service_up"     // 被监控的服务恢复
+	EventEscalation    = "escalation"     // 告警长时间未确认时的升级提醒
+)
+
+// Channels 支持自定义模板的通知渠道
+var Channels = []string{ChannelEmail, ChannelWebhook}
+
+// Events 支持自定义模板的事件类型
+var Events = []string{EventAlert, EventRecovery, EventBandwidth, EventTraffic, EventExpiration, EventServerOffline, EventServerOnline, EventServiceDown, EventServiceUp, EventEscalation}
+
+// Template 通知模板，Subject 用于邮件标题，Webhook 只使用 Body
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Data 渲染模板时可用的变量，模板中以 {{ .ServerName }} 的形式引用
+type Data struct {
+	Title        string            // 通知标题，即渲染后的邮件标题
+	Timestamp    string            // 触发时间，如 2006-01-02 15:04:05
+	ServerID     string            // 服务器ID
+	ServerName   string            // 服务器名称
+	ServerIP     string            // 服务器IP
+	MetricLabel  string            // 指标名称或表达式规则名称
+	Severity     string            // 告警级别：警告、严重，恢复时为空
+	StatusText   string            // 当前状态：警告、严重、恢复正常
+	IsRecovery   bool              // 是否为恢复通知
+	Color        string            // 状态颜色，告警为红色或橙色，恢复为绿色
+	CurrentValue float64           // 当前值
+	Threshold    float64           // 触发阈值
+	Unit         string            // 单位，如 %、°C、Mbps
+	Expression   string            // 表达式规则的触发条件，其他规则为空
+	Values       string            // 表达式中各指标项的取值，如 cpu=91.00, load1=4.20
+	Labels       string            // 规则标签，如 env=prod, team=ops
+	Detail       string            // 默认格式的告警详情文本
+	Extra        map[string]string // 事件特有的变量，如流量告警的 UsedGB、LimitGB，到期提醒的 ExpireTime
+}
+
+// Variable 模板变量说明
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Variables 模板中可用的变量说明，Extra 中的变量按事件类型列出
+var Variables = []Variable{
+	{Name: ".Title", Description: "通知标题，即渲染后的邮件标题（仅正文可用）"},
+	{Name: ".Timestamp", Description: "触发时间"},
+	{Name: ".ServerID", Description: "服务器ID"},
+	{Name: ".ServerName", Description: "服务器名称"},
+	{Name: ".ServerIP", Description: "服务器IP"},
+	{Name: ".MetricLabel", Description: "指标名称或表达式规则名称"},
+	{Name: ".Severity", Description: "告警级别：警告、严重，恢复时为空"},
+	{Name: ".StatusText", Description: "当前状态：警告、严重、恢复正常"},
+	{Name: ".IsRecovery", Description: "是否为恢复通知"},
+	{Name: ".Color", Description: "状态颜色"},
+	{Name: ".CurrentValue", Description: "当前值，可用 printf \"%.2f\" 格式化"},
+	{Name: ".Threshold", Description: "触发阈值"},
+	{Name: ".Unit", Description: "单位"},
+	{Name: ".Expression", Description: "表达式规则的触发条件"},
+	{Name: ".Values", Description: "表达式中各指标项的取值"},
+	{Name: ".Labels", Description: "规则标签"},
+	{Name: ".Detail", Description: "默认格式的告警详情文本"},
+	{Name: ".Extra.UsedGB", Description: "已用流量(GB)，仅流量告警"},
+	{Name: ".Extra.LimitGB", Description: "流量限额(GB)，仅流量告警"},
+	{Name: ".Extra.ExpireTime", Description: "到期时间，仅到期提醒"},
+	{Name: ".Extra.ServiceName", Description: "服务名称，仅服务停止和恢复"},
+	{Name: ".Extra.Duration", Description: "故障时长，仅服务恢复"},
+	{Name: ".Extra.Elapsed", Description: "告警已持续的分钟数，仅升级提醒"},
+}
+
+// ValidChannel 检查渠道是否支持自定义模板
+func ValidChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidEvent 检查事件类型是否支持自定义模板
+func ValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHTML 邮件正文以 HTML 标签开头时按 HTML 模板渲染并转义变量，发送时作为 HTML 正文
+func IsHTML(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "<")
+}
+
+// Render 渲染模板，返回标题和正文；邮件的 HTML 正文使用 html/template，其余使用 text/template
+func Render(channel string, tmpl Template, data Data) (string, string, error) {
+	if strings.TrimSpace(tmpl.Body) == "" {
+		return "", "", errors.New("模板正文不能为空")
+	}
+
+	subject := ""
+	if channel == ChannelEmail {
+		if strings.TrimSpace(tmpl.Subject) == "" {
+			return "", "", errors.New("邮件标题模板不能为空")
+		}
+		t, err := texttemplate.New("subject").Option("missingkey=error").Parse(tmpl.Subject)
+		if err != nil {
+			return "", "", fmt.Errorf("标题模板解析失败: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return "", "", fmt.Errorf("标题模板渲染失败: %v", err)
+		}
+		// 邮件标题不能换行
+		subject = strings.Join(strings.Fields(buf.String()), " ")
+		data.Title = subject
+	}
+
+	var buf bytes.Buffer
+	if channel == ChannelEmail && IsHTML(tmpl.Body) {
+		t, err := htmltemplate.New("body").Option("missingkey=error").Parse(tmpl.Body)
+		if err != nil {
+			return "", "", fmt.Errorf("正文模板解析失败: %v", err)
+		}
+		if err := t.Execute(&buf, data); err != nil {
+			return "", "", fmt.Errorf("正文模板渲染失败: %v", err)
+		}
+	} else {
+		t, err := texttemplate.New("body").Option("missingkey=error").Parse(tmpl.Body)
+		if err != nil {
+			return "", "", fmt.Errorf("正文模板解析失败: %v", err)
+		}
+		if err := t.Execute(&buf, data); err != nil {
+			return "", "", fmt.Errorf("正文模板渲染失败: %v", err)
+		}
+	}
+	return subject, buf.String(), nil
+}
+
+// SampleData 用于预览模板的示例数据
+func SampleData(event string) Data {
+	data := Data{
+		Title:        "[严重] web-01 - CPU使用率",
+		Timestamp:    "2026-01-01 12:00:00",
+		ServerID:     "00000000-0000-0000-0000-000000000000",
+		ServerName:   "web-01",
+		ServerIP:     "192.168.1.10",
+		MetricLabel:  "CPU使用率",
+		Severity:     "严重",
+		StatusText:   "严重",
+		Color:        "#ff4d4f",
+		CurrentValue: 95.5,
+		Threshold:    90,
+		Unit:         "%",
+		Labels:       "env=prod",
+		Detail:       "指标: CPU使用率\n当前值: 95.50%\n阈值: 90.00%\n标签: env=prod",
+		Extra:        map[string]string{},
+	}
+
+	switch event {
+	case EventRecovery, EventServerOnline, EventServiceUp:
+		data.Severity = ""
+		data.StatusText = "恢复正常"
+		data.IsRecovery = true
+		data.Color = "#52c41a"
+		data.CurrentValue = 42.3
+		data.Detail = "指标: CPU使用率\n当前值: 42.30%\n标签: env=prod"
+	case EventBandwidth:
+		data.MetricLabel = "带宽峰值"
+		data.CurrentValue = 850
+		data.Threshold = 800
+		data.Unit = "Mbps"
+	case EventTraffic:
+		data.MetricLabel = "流量耗尽"
+		data.CurrentValue = 92.5
+		data.Threshold = 80
+		data.Extra["UsedGB"] = "925.00"
+		data.Extra["LimitGB"] = "1000.00"
+	case EventExpiration:
+		data.MetricLabel = "即将到期"
+		data.CurrentValue = 3
+		data.Threshold = 7
+		data.Unit = "天"
+		data.Extra["ExpireTime"] = "2026-01-04 12:00:00"
+	case EventEscalation:
+		data.Title = "[升级] web-01 - CPU使用率"
+		data.Extra["Elapsed"] = "30"
+	}
+	if event == EventServiceDown || event == EventServiceUp {
+		data.MetricLabel = "服务 nginx"
+		data.CurrentValue = 0
+		data.Threshold = 0
+		data.Unit = ""
+		data.Labels = ""
+		data.Extra["ServiceName"] = "nginx"
+		data.Detail = "服务: nginx"
+		if event == EventServiceUp {
+			data.Extra["Duration"] = "5分钟"
+			data.Detail = "服务: nginx\n故障时长: 5分钟"
+		}
+	}
+	if event == EventServerOffline || event == EventServerOnline {
+		data.MetricLabel = "服务器离线"
+	}
+	return data
+}
@@ -66,6 +66,8 @@ func init() {
 		&providers.ValidationServiceProvider{},
 		&providers.DatabaseServiceProvider{},
 		&providers.CleanupServiceProvider{},
+		&providers.AgentRolloutServiceProvider{},
+		&providers.AlertServiceProvider{},
 		&gin.ServiceProvider{},
 	}
 
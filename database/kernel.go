@@ -39,6 +39,22 @@ func (kernel Kernel) Migrations() []schema.Migration {
 		&migrations.CreateAgentLogsTable{},
 		&migrations.M20260206000001AddServiceStatusToServersTable{},
 		&migrations.M20260206000002AddGPUInfoToServersTable{},
+		&migrations.M20261018000001RebuildServiceMonitorAlertsTable{},
+		&migrations.M20261018000002CreateServerGPUMetricsTable{},
+		&migrations.M20261018000003CreateServerSensorReadingsTable{},
+		&migrations.M20261018000004AddAgentMinSupportedVersionSetting{},
+		&migrations.M20261018000005CreateAgentRolloutsTable{},
+		&migrations.M20261018000006CreateAgentRolloutServersTable{},
+		&migrations.M20261018000007RebuildAlertsTable{},
+		&migrations.M20261018000008CreateAlertSilencesTable{},
+		&migrations.M20261018000009CreateAlertEscalationPoliciesTable{},
+		&migrations.M20261018000010AddEscalationPolicyColumns{},
+		&migrations.M20261018000011AddAlertRuleInheritanceColumns{},
+		&migrations.M20261018000012AddAlertGroupWindowSetting{},
+		&migrations.M20261018000013CreateServerMetricBaselinesTable{},
+		&migrations.M20261018000014CreateAlertProfilesTable{},
+		&migrations.M20261018000015CreateNotificationSubscriptionsTable{},
+		&migrations.M20261018000016CreateNotificationDeliveriesTable{},
 	}
 }
 
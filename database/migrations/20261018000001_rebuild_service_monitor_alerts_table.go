@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000001RebuildServiceMonitorAlertsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000001RebuildServiceMonitorAlertsTable) Signature() string {
+	return "20261018000001_rebuild_service_monitor_alerts_table"
+}
+
+// Up Run the migrations.
+// 按服务记录停止和恢复事件：新增 service_name 和恢复时的故障时长 duration，
+// rule_id 改为可空并指向 server_alert_rules（服务监控不再依赖已弃用的 alert_rules）
+func (r *M20261018000001RebuildServiceMonitorAlertsTable) Up() error {
+	if facades.Schema().HasTable("service_monitor_alerts") && facades.Schema().HasColumn("service_monitor_alerts", "service_name") {
+		return nil
+	}
+
+	if err := facades.Schema().DropIfExists("service_monitor_alerts"); err != nil {
+		return err
+	}
+
+	return facades.Schema().Create("service_monitor_alerts", func(table schema.Blueprint) {
+		table.String("id")
+		table.Primary("id")
+		table.Integer("rule_id").Nullable().Comment("关联的 server_alert_rules 规则ID")
+		table.String("server_id")
+		table.String("service_name").Comment("服务名称")
+		table.String("type").Comment("事件类型: down, up")
+		table.String("title")
+		table.Text("message").Nullable()
+		table.Integer("response_time").Nullable()
+		table.BigInteger("duration").Default(0).Comment("恢复事件对应的故障持续时间(秒)")
+		table.Boolean("is_read").Default(false)
+		table.Timestamp("timestamp").UseCurrent()
+
+		table.Index("server_id", "service_name")
+		table.Index("timestamp")
+		table.Foreign("server_id").References("id").On("servers")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000001RebuildServiceMonitorAlertsTable) Down() error {
+	if err := facades.Schema().DropIfExists("service_monitor_alerts"); err != nil {
+		return err
+	}
+
+	return facades.Schema().Create("service_monitor_alerts", func(table schema.Blueprint) {
+		table.String("id")
+		table.Primary("id")
+		table.Integer("rule_id")
+		table.String("server_id")
+		table.String("type")
+		table.String("title")
+		table.Text("message").Nullable()
+		table.Integer("response_time").Nullable()
+		table.Boolean("is_read").Default(false)
+		table.Timestamp("timestamp").UseCurrent()
+
+		table.Foreign("rule_id").References("id").On("alert_rules")
+		table.Foreign("server_id").References("id").On("servers")
+	})
+}
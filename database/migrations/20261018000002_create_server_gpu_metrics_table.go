@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000002CreateServerGPUMetricsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000002CreateServerGPUMetricsTable) Signature() string {
+	return "20261018000002_create_server_gpu_metrics_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000002CreateServerGPUMetricsTable) Up() error {
+	if !facades.Schema().HasTable("server_gpu_metrics") {
+		return facades.Schema().Create("server_gpu_metrics", func(table schema.Blueprint) {
+			table.ID()
+			table.String("server_id")
+			table.Integer("gpu_index").Default(0).Comment("GPU序号")
+			table.String("name").Nullable().Comment("GPU型号")
+			table.Decimal("utilization").Default(0).Comment("GPU使用率(%)")
+			table.Decimal("memory_used").Default(0).Comment("已用显存(MB)")
+			table.Decimal("memory_total").Default(0).Comment("总显存(MB)")
+			table.Decimal("temperature").Default(0).Comment("温度(°C)")
+			table.Decimal("power_draw").Default(0).Comment("功耗(W)")
+			table.Timestamp("timestamp").UseCurrent()
+			table.Timestamps()
+
+			table.Index("server_id", "timestamp")
+			table.Foreign("server_id").References("id").On("servers")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000002CreateServerGPUMetricsTable) Down() error {
+	return facades.Schema().DropIfExists("server_gpu_metrics")
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000003CreateServerSensorReadingsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000003CreateServerSensorReadingsTable) Signature() string {
+	return "20261018000003_create_server_sensor_readings_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000003CreateServerSensorReadingsTable) Up() error {
+	if !facades.Schema().HasTable("server_sensor_readings") {
+		return facades.Schema().Create("server_sensor_readings", func(table schema.Blueprint) {
+			table.ID()
+			table.String("server_id")
+			table.String("sensor_key").Comment("传感器唯一标识，如 cpu_core:0")
+			table.String("sensor_type").Comment("传感器类型: cpu_package, cpu_core, nvme, chassis")
+			table.String("label").Nullable().Comment("传感器名称")
+			table.Decimal("temperature").Default(0).Comment("温度(°C)")
+			table.Timestamp("timestamp").UseCurrent()
+			table.Timestamps()
+
+			table.Index("server_id", "timestamp")
+			table.Foreign("server_id").References("id").On("servers")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000003CreateServerSensorReadingsTable) Down() error {
+	return facades.Schema().DropIfExists("server_sensor_readings")
+}
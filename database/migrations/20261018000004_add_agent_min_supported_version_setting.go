@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000004AddAgentMinSupportedVersionSetting struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000004AddAgentMinSupportedVersionSetting) Signature() string {
+	return "20261018000004_add_agent_min_supported_version_setting"
+}
+
+// Up Run the migrations.
+func (r *M20261018000004AddAgentMinSupportedVersionSetting) Up() error {
+	count, err := facades.Orm().Query().Model(&models.SystemSetting{}).Where("setting_key = ?", "agent_min_supported_version").Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// 为空表示不限制最低 Agent 版本
+	setting := models.SystemSetting{
+		SettingKey:   "agent_min_supported_version",
+		SettingValue: "",
+		SettingType:  "string",
+		Description:  "最低支持的Agent版本",
+	}
+	return facades.Orm().Query().Create(&setting)
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000004AddAgentMinSupportedVersionSetting) Down() error {
+	_, err := facades.Orm().Query().Where("setting_key = ?", "agent_min_supported_version").Delete(&models.SystemSetting{})
+	return err
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000005CreateAgentRolloutsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000005CreateAgentRolloutsTable) Signature() string {
+	return "20261018000005_create_agent_rollouts_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000005CreateAgentRolloutsTable) Up() error {
+	if !facades.Schema().HasTable("agent_rollouts") {
+		return facades.Schema().Create("agent_rollouts", func(table schema.Blueprint) {
+			table.ID()
+			table.String("name")
+			table.String("target_version").Comment("目标Agent版本")
+			table.String("version_type").Nullable()
+			table.Integer("group_id").Nullable().Comment("目标分组，为空表示按服务器选择")
+			table.Integer("canary_count").Default(1).Comment("金丝雀服务器数量")
+			table.Integer("batch_size").Default(5).Comment("每批服务器数量")
+			table.Integer("max_failures").Default(0).Comment("允许的最大失败数，超过后自动暂停")
+			table.Integer("batch_timeout").Default(300).Comment("等待Agent重连的超时时间(秒)")
+			table.String("status", 20).Default("pending").Comment("状态: pending, running, completed, halted, cancelled")
+			table.Integer("current_batch").Default(0)
+			table.Integer("total_batches").Default(0)
+			table.Integer("total_servers").Default(0)
+			table.Integer("succeeded_count").Default(0)
+			table.Integer("failed_count").Default(0)
+			table.Text("error").Nullable().Comment("暂停或失败原因")
+			table.Timestamp("started_at").Nullable()
+			table.Timestamp("finished_at").Nullable()
+			table.Timestamps()
+
+			table.Index("status")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000005CreateAgentRolloutsTable) Down() error {
+	return facades.Schema().DropIfExists("agent_rollouts")
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000006CreateAgentRolloutServersTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000006CreateAgentRolloutServersTable) Signature() string {
+	return "20261018000006_create_agent_rollout_servers_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000006CreateAgentRolloutServersTable) Up() error {
+	if !facades.Schema().HasTable("agent_rollout_servers") {
+		return facades.Schema().Create("agent_rollout_servers", func(table schema.Blueprint) {
+			table.ID()
+			table.Integer("rollout_id")
+			table.String("server_id")
+			table.Integer("batch_index").Default(0).Comment("批次序号，0为金丝雀批次")
+			table.String("status", 20).Default("pending").Comment("状态: pending, updating, succeeded, failed, skipped")
+			table.String("from_version").Nullable().Comment("更新前的Agent版本")
+			table.Text("error").Nullable()
+			table.Timestamp("started_at").Nullable()
+			table.Timestamp("finished_at").Nullable()
+			table.Timestamps()
+
+			table.Index("rollout_id", "batch_index")
+			table.Foreign("rollout_id").References("id").On("agent_rollouts")
+			table.Foreign("server_id").References("id").On("servers")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000006CreateAgentRolloutServersTable) Down() error {
+	return facades.Schema().DropIfExists("agent_rollout_servers")
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000007RebuildAlertsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000007RebuildAlertsTable) Signature() string {
+	return "20261018000007_rebuild_alerts_table"
+}
+
+// Up Run the migrations.
+// 改为告警实例表：每行对应一次从触发到恢复的告警，以 rule_key 和 status 定位进行中的告警，
+// 并增加级别、确认和恢复字段
+func (r *M20261018000007RebuildAlertsTable) Up() error {
+	if facades.Schema().HasTable("alerts") && facades.Schema().HasColumn("alerts", "rule_key") {
+		return nil
+	}
+
+	if err := facades.Schema().DropIfExists("alerts"); err != nil {
+		return err
+	}
+
+	return facades.Schema().Create("alerts", func(table schema.Blueprint) {
+		table.String("id")
+		table.Primary("id")
+		table.String("server_id")
+		table.Integer("rule_id").Nullable().Comment("关联的 server_alert_rules 规则ID")
+		table.String("rule_key").Comment("告警状态标识，如 cpu、gpu_util:0、expression:xxx、server_offline")
+		table.String("type").Comment("告警类型: threshold, expression, server_offline")
+		table.String("severity").Default("warning").Comment("告警级别: warning, critical")
+		table.String("status").Default("firing").Comment("告警状态: firing, resolved")
+		table.String("title")
+		table.Text("message").Nullable()
+		table.Decimal("metric_value").Nullable()
+		table.Decimal("threshold").Nullable()
+		table.Text("labels").Nullable()
+		table.Boolean("is_read").Default(false)
+		table.Timestamp("acknowledged_at").Nullable()
+		table.String("acknowledged_by").Nullable()
+		table.Timestamp("resolved_at").Nullable()
+		table.String("resolved_by").Nullable().Comment("恢复方式: auto 为自动恢复，其余为手动处理人")
+		table.Timestamp("timestamp").UseCurrent().Comment("告警开始时间")
+		table.Timestamps()
+
+		table.Index("server_id", "rule_key", "status")
+		table.Index("status")
+		table.Index("timestamp")
+		table.Foreign("server_id").References("id").On("servers")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000007RebuildAlertsTable) Down() error {
+	if err := facades.Schema().DropIfExists("alerts"); err != nil {
+		return err
+	}
+
+	return facades.Schema().Create("alerts", func(table schema.Blueprint) {
+		table.String("id")
+		table.Primary("id")
+		table.String("server_id")
+		table.Integer("rule_id")
+		table.String("type")
+		table.String("title")
+		table.Text("message").Nullable()
+		table.Decimal("metric_value").Nullable()
+		table.Boolean("is_read").Default(false)
+		table.Timestamp("timestamp").UseCurrent()
+
+		table.Foreign("server_id").References("id").On("servers")
+		table.Foreign("rule_id").References("id").On("alert_rules")
+	})
+}
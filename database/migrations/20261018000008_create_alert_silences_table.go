@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000008CreateAlertSilencesTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000008CreateAlertSilencesTable) Signature() string {
+	return "20261018000008_create_alert_silences_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000008CreateAlertSilencesTable) Up() error {
+	if !facades.Schema().HasTable("alert_silences") {
+		return facades.Schema().Create("alert_silences", func(table schema.Blueprint) {
+			table.ID()
+			table.String("name")
+			table.Text("comment").Nullable()
+			table.String("server_id").Nullable().Comment("匹配的服务器，为空表示不限")
+			table.Integer("group_id").Nullable().Comment("匹配的服务器分组，为空表示不限")
+			table.String("rule_type").Nullable().Comment("匹配的规则类型，如 cpu、server_offline、expression，为空表示不限")
+			table.Text("labels").Nullable().Comment("匹配的规则标签(JSON)，需全部匹配")
+			table.Timestamp("starts_at").Nullable()
+			table.Timestamp("ends_at").Nullable()
+			table.String("cron").Nullable().Comment("周期维护窗口的开始时间(cron 表达式)")
+			table.Integer("duration").Default(0).Comment("周期维护窗口时长(分钟)")
+			table.String("created_by").Nullable()
+			table.Timestamps()
+
+			table.Index("server_id")
+			table.Index("group_id")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000008CreateAlertSilencesTable) Down() error {
+	return facades.Schema().DropIfExists("alert_silences")
+}
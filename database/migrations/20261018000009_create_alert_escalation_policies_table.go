@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000009CreateAlertEscalationPoliciesTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000009CreateAlertEscalationPoliciesTable) Signature() string {
+	return "20261018000009_create_alert_escalation_policies_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000009CreateAlertEscalationPoliciesTable) Up() error {
+	if !facades.Schema().HasTable("alert_escalation_policies") {
+		return facades.Schema().Create("alert_escalation_policies", func(table schema.Blueprint) {
+			table.ID()
+			table.String("name")
+			table.Text("description").Nullable()
+			table.Text("steps").Comment("升级步骤(JSON)")
+			table.Timestamps()
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000009CreateAlertEscalationPoliciesTable) Down() error {
+	return facades.Schema().DropIfExists("alert_escalation_policies")
+}
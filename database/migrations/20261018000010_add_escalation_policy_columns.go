@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000010AddEscalationPolicyColumns struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000010AddEscalationPolicyColumns) Signature() string {
+	return "20261018000010_add_escalation_policy_columns"
+}
+
+// Up Run the migrations.
+func (r *M20261018000010AddEscalationPolicyColumns) Up() error {
+	if !facades.Schema().HasColumn("server_groups", "escalation_policy_id") {
+		if err := facades.Schema().Table("server_groups", func(table schema.Blueprint) {
+			table.Integer("escalation_policy_id").Nullable().Comment("分组默认的告警升级策略")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasColumn("alerts", "escalation_policy_id") {
+		return facades.Schema().Table("alerts", func(table schema.Blueprint) {
+			table.Integer("escalation_policy_id").Nullable().Comment("告警使用的升级策略")
+			table.Integer("escalation_step").Default(0).Comment("已执行的升级步骤数")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000010AddEscalationPolicyColumns) Down() error {
+	if err := facades.Schema().Table("server_groups", func(table schema.Blueprint) {
+		table.DropColumn("escalation_policy_id")
+	}); err != nil {
+		return err
+	}
+
+	return facades.Schema().Table("alerts", func(table schema.Blueprint) {
+		table.DropColumn("escalation_policy_id", "escalation_step")
+	})
+}
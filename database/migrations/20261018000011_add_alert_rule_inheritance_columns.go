@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000011AddAlertRuleInheritanceColumns struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000011AddAlertRuleInheritanceColumns) Signature() string {
+	return "20261018000011_add_alert_rule_inheritance_columns"
+}
+
+// Up Run the migrations.
+func (r *M20261018000011AddAlertRuleInheritanceColumns) Up() error {
+	if !facades.Schema().HasColumn("server_alert_rules", "group_id") {
+		if err := facades.Schema().Table("server_alert_rules", func(table schema.Blueprint) {
+			table.Integer("group_id").Nullable().Comment("分组规则所属分组，server_id 与 group_id 均为 NULL 表示全局规则")
+			table.Index("group_id")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasColumn("servers", "alert_rules_mode") {
+		return facades.Schema().Table("servers", func(table schema.Blueprint) {
+			table.String("alert_rules_mode", 20).Default("override").Comment("告警规则模式：override 服务器规则优先，inherit 仅继承分组和全局规则")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000011AddAlertRuleInheritanceColumns) Down() error {
+	if err := facades.Schema().Table("server_alert_rules", func(table schema.Blueprint) {
+		table.DropIndex("group_id")
+		table.DropColumn("group_id")
+	}); err != nil {
+		return err
+	}
+
+	return facades.Schema().Table("servers", func(table schema.Blueprint) {
+		table.DropColumn("alert_rules_mode")
+	})
+}
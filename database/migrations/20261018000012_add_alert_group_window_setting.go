@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"goravel/app/models"
+
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000012AddAlertGroupWindowSetting struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000012AddAlertGroupWindowSetting) Signature() string {
+	return "20261018000012_add_alert_group_window_setting"
+}
+
+// Up Run the migrations.
+func (r *M20261018000012AddAlertGroupWindowSetting) Up() error {
+	count, err := facades.Orm().Query().Model(&models.SystemSetting{}).Where("setting_key = ?", "alert_group_window").Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// 默认为 0，不合并，每条告警单独发送；需要合并时在告警设置中开启
+	setting := models.SystemSetting{
+		SettingKey:   "alert_group_window",
+		SettingValue: "0",
+		SettingType:  "int",
+		Description:  "告警合并窗口(秒)",
+	}
+	return facades.Orm().Query().Create(&setting)
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000012AddAlertGroupWindowSetting) Down() error {
+	_, err := facades.Orm().Query().Where("setting_key = ?", "alert_group_window").Delete(&models.SystemSetting{})
+	return err
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000013CreateServerMetricBaselinesTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000013CreateServerMetricBaselinesTable) Signature() string {
+	return "20261018000013_create_server_metric_baselines_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000013CreateServerMetricBaselinesTable) Up() error {
+	if !facades.Schema().HasTable("server_metric_baselines") {
+		return facades.Schema().Create("server_metric_baselines", func(table schema.Blueprint) {
+			table.ID()
+			table.String("server_id")
+			table.String("metric").Comment("指标名称，如 cpu、memory、disk")
+			table.Integer("hour_of_week").Comment("一周中的小时(0-167)，周日 0 点为 0")
+			table.Decimal("mean").Default(0).Comment("历史均值")
+			table.Decimal("stddev").Default(0).Comment("历史标准差")
+			table.Integer("samples").Default(0).Comment("参与计算的样本数")
+			table.Timestamps()
+
+			table.Unique("server_id", "metric", "hour_of_week")
+			table.Foreign("server_id").References("id").On("servers")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000013CreateServerMetricBaselinesTable) Down() error {
+	return facades.Schema().DropIfExists("server_metric_baselines")
+}
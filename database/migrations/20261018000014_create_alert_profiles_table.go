@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000014CreateAlertProfilesTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000014CreateAlertProfilesTable) Signature() string {
+	return "20261018000014_create_alert_profiles_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000014CreateAlertProfilesTable) Up() error {
+	if !facades.Schema().HasTable("alert_profiles") {
+		if err := facades.Schema().Create("alert_profiles", func(table schema.Blueprint) {
+			table.ID()
+			table.String("name")
+			table.Text("description").Nullable()
+			table.Timestamps()
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasColumn("server_alert_rules", "profile_id") {
+		if err := facades.Schema().Table("server_alert_rules", func(table schema.Blueprint) {
+			table.Integer("profile_id").Nullable().Comment("告警配置模板规则所属模板")
+			table.Index("profile_id")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasColumn("servers", "alert_profile_id") {
+		if err := facades.Schema().Table("servers", func(table schema.Blueprint) {
+			table.Integer("alert_profile_id").Nullable().Comment("服务器使用的告警配置模板")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasColumn("server_groups", "alert_profile_id") {
+		return facades.Schema().Table("server_groups", func(table schema.Blueprint) {
+			table.Integer("alert_profile_id").Nullable().Comment("分组使用的告警配置模板")
+		})
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000014CreateAlertProfilesTable) Down() error {
+	if err := facades.Schema().Table("server_groups", func(table schema.Blueprint) {
+		table.DropColumn("alert_profile_id")
+	}); err != nil {
+		return err
+	}
+	if err := facades.Schema().Table("servers", func(table schema.Blueprint) {
+		table.DropColumn("alert_profile_id")
+	}); err != nil {
+		return err
+	}
+	if err := facades.Schema().Table("server_alert_rules", func(table schema.Blueprint) {
+		table.DropIndex("profile_id")
+		table.DropColumn("profile_id")
+	}); err != nil {
+		return err
+	}
+
+	return facades.Schema().DropIfExists("alert_profiles")
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000015CreateNotificationSubscriptionsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000015CreateNotificationSubscriptionsTable) Signature() string {
+	return "20261018000015_create_notification_subscriptions_table"
+}
+
+// Up Run the migrations.
+func (r *M20261018000015CreateNotificationSubscriptionsTable) Up() error {
+	if !facades.Schema().HasColumn("alert_notifications", "name") {
+		if err := facades.Schema().Table("alert_notifications", func(table schema.Blueprint) {
+			table.String("name").Default("").Comment("通知渠道名称")
+			table.Boolean("is_default").Default(true).Comment("未订阅的服务器是否默认接收该渠道的通知")
+		}); err != nil {
+			return err
+		}
+
+		// 清理旧版本更新不存在的配置时写入的空类型记录，已有渠道以类型命名
+		if err := facades.Schema().Sql("DELETE FROM alert_notifications WHERE notification_type = ''"); err != nil {
+			return err
+		}
+		if err := facades.Schema().Sql("UPDATE alert_notifications SET name = notification_type WHERE name = ''"); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasTable("notification_subscriptions") {
+		if err := facades.Schema().Create("notification_subscriptions", func(table schema.Blueprint) {
+			table.ID()
+			table.Integer("channel_id")
+			table.String("server_id").Nullable()
+			table.Integer("group_id").Nullable()
+			table.Boolean("enabled").Default(true)
+			table.Timestamps()
+			table.Index("channel_id")
+			table.Index("server_id")
+			table.Index("group_id")
+		}); err != nil {
+			return err
+		}
+
+		// 将服务器按类型的通知开关迁移为对该类型渠道的订阅
+		if facades.Schema().HasTable("server_notification_channels") {
+			if err := facades.Schema().Sql(`INSERT INTO notification_subscriptions (channel_id, server_id, enabled, created_at, updated_at)
+				SELECT n.id, c.server_id, c.enabled, c.created_at, c.updated_at
+				FROM server_notification_channels c
+				JOIN (SELECT notification_type, MIN(id) AS id FROM alert_notifications GROUP BY notification_type) n
+				ON n.notification_type = c.notification_type`); err != nil {
+				return err
+			}
+		}
+	}
+
+	return facades.Schema().DropIfExists("server_notification_channels")
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000015CreateNotificationSubscriptionsTable) Down() error {
+	if err := facades.Schema().DropIfExists("notification_subscriptions"); err != nil {
+		return err
+	}
+	if err := facades.Schema().Table("alert_notifications", func(table schema.Blueprint) {
+		table.DropColumn("name", "is_default")
+	}); err != nil {
+		return err
+	}
+
+	return (&M20250129000007CreateServerNotificationChannelsTable{}).Up()
+}
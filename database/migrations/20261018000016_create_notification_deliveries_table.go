@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"fmt"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261018000016CreateNotificationDeliveriesTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261018000016CreateNotificationDeliveriesTable) Signature() string {
+	return "20261018000016_create_notification_deliveries_table"
+}
+
+// notificationRetrySettings 通知重试的默认设置
+var notificationRetrySettings = []models.SystemSetting{
+	{SettingKey: "notification_retry_max_attempts", SettingValue: "5", SettingType: "int", Description: "通知发送失败的最大尝试次数"},
+	{SettingKey: "notification_retry_backoff", SettingValue: "60", SettingType: "int", Description: "通知首次重试的间隔(秒)，之后每次翻倍"},
+}
+
+// Up Run the migrations.
+func (r *M20261018000016CreateNotificationDeliveriesTable) Up() error {
+	if !facades.Schema().HasTable("notification_deliveries") {
+		if err := facades.Schema().Create("notification_deliveries", func(table schema.Blueprint) {
+			table.ID()
+			table.Integer("channel_id").Nullable()
+			table.String("channel_type", 20)
+			table.String("channel_name").Default("")
+			table.String("target").Default("").Comment("接收方，如收件人、Webhook 域名、Chat ID")
+			table.String("subject").Default("")
+			table.Text("payload").Comment("发送任务的 JSON，用于重试和重发")
+			table.String("status", 20).Default("pending").Comment("pending、sent、retrying、dead")
+			table.Integer("attempts").Default(0)
+			table.Text("last_error").Nullable()
+			table.Integer("latency_ms").Default(0).Comment("最近一次尝试的耗时(毫秒)")
+			table.Timestamp("next_retry_at").Nullable()
+			table.Timestamp("delivered_at").Nullable()
+			table.Integer("resend_of").Nullable().Comment("手动重发时原投递记录的ID")
+			table.Timestamps()
+			table.Index("channel_id")
+			table.Index("status", "next_retry_at")
+			table.Index("created_at")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !facades.Schema().HasTable("notification_delivery_attempts") {
+		if err := facades.Schema().Create("notification_delivery_attempts", func(table schema.Blueprint) {
+			table.ID()
+			table.Integer("delivery_id")
+			table.Integer("attempt")
+			table.Boolean("success")
+			table.Text("error").Nullable()
+			table.Integer("latency_ms").Default(0)
+			table.Timestamps()
+			table.Index("delivery_id")
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 与建表使用同一连接写入默认设置，已存在的设置保持不变
+	for _, setting := range notificationRetrySettings {
+		if err := facades.Schema().Sql(fmt.Sprintf(`INSERT INTO system_settings (setting_key, setting_value, setting_type, description, created_at, updated_at)
+			SELECT '%s', '%s', '%s', '%s', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+			WHERE NOT EXISTS (SELECT 1 FROM system_settings WHERE setting_key = '%s')`,
+			setting.SettingKey, setting.SettingValue, setting.SettingType, setting.Description, setting.SettingKey)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down Reverse the migrations.
+func (r *M20261018000016CreateNotificationDeliveriesTable) Down() error {
+	for _, setting := range notificationRetrySettings {
+		if err := facades.Schema().Sql(fmt.Sprintf("DELETE FROM system_settings WHERE setting_key = '%s'", setting.SettingKey)); err != nil {
+			return err
+		}
+	}
+	if err := facades.Schema().DropIfExists("notification_delivery_attempts"); err != nil {
+		return err
+	}
+	return facades.Schema().DropIfExists("notification_deliveries")
+}
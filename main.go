@@ -119,6 +119,9 @@ func main() {
 			facades.Log().Errorf("Schedule Shutdown error: %v", err)
 		}
 
+		// 发送告警合并窗口内尚未发送的通知
+		services.GetAlertGrouper().Flush()
+
 		os.Exit(0)
 	}()
 
@@ -20,6 +20,9 @@ func Api() {
 	serverController := controllers.NewServerController()
 	serverGroupController := controllers.NewServerGroupController()
 	serverAlertController := controllers.NewServerAlertController()
+	agentRolloutController := controllers.NewAgentRolloutController()
+	alertController := controllers.NewAlertController()
+	alertProfileController := controllers.NewAlertProfileController()
 	staticController := controllers.NewStaticController()
 
 	facades.Route().Prefix("api").Group(func(router route.Router) {
@@ -47,6 +50,19 @@ func Api() {
 				settingsRoute.Patch("/permissions", settingsController.UpdatePermissionsSettings)
 				settingsRoute.Patch("/alerts", settingsController.UpdateAlertsSettings)
 				settingsRoute.Post("/alerts/test", settingsController.TestAlertSettings)
+
+				// 通知模板
+				settingsRoute.Get("/alerts/templates", settingsController.GetNotificationTemplates)
+				settingsRoute.Post("/alerts/templates/preview", settingsController.PreviewNotificationTemplate)
+				settingsRoute.Patch("/alerts/templates/:channel/:event", settingsController.UpdateNotificationTemplate)
+				settingsRoute.Delete("/alerts/templates/:channel/:event", settingsController.ResetNotificationTemplate)
+
+				// 通知渠道
+				settingsRoute.Get("/alerts/channels", settingsController.GetNotificationChannels)
+				settingsRoute.Post("/alerts/channels", settingsController.CreateNotificationChannel)
+				settingsRoute.Patch("/alerts/channels/:id", settingsController.UpdateNotificationChannel)
+				settingsRoute.Delete("/alerts/channels/:id", settingsController.DeleteNotificationChannel)
+				settingsRoute.Post("/alerts/channels/:id/test", settingsController.TestNotificationChannel)
 			})
 
 			// 更新相关
@@ -57,6 +73,56 @@ func Api() {
 				updateRoute.Get("/agent/check", updateController.CheckAgent)
 			})
 
+			// Agent 版本
+			authRouter.Prefix("/agents").Middleware(middleware.AdminAuth()).Group(func(agentsRoute route.Router) {
+				agentsRoute.Get("/versions", updateController.AgentVersions)
+
+				// 分批发布
+				agentsRoute.Get("/rollouts", agentRolloutController.GetRollouts)
+				agentsRoute.Post("/rollouts", agentRolloutController.CreateRollout)
+				agentsRoute.Get("/rollouts/:id", agentRolloutController.GetRollout)
+				agentsRoute.Post("/rollouts/:id/resume", agentRolloutController.ResumeRollout)
+				agentsRoute.Post("/rollouts/:id/cancel", agentRolloutController.CancelRollout)
+			})
+
+			// 告警记录
+			authRouter.Prefix("/alerts").Middleware(middleware.AdminAuth()).Group(func(alertsRoute route.Router) {
+				alertsRoute.Get("", alertController.GetAlerts)
+				alertsRoute.Post("/:id/acknowledge", alertController.AcknowledgeAlert)
+				alertsRoute.Post("/:id/resolve", alertController.ResolveAlert)
+
+				// 静默与维护窗口
+				alertsRoute.Get("/silences", alertController.GetSilences)
+				alertsRoute.Post("/silences", alertController.CreateSilence)
+				alertsRoute.Patch("/silences/:id", alertController.UpdateSilence)
+				alertsRoute.Delete("/silences/:id", alertController.DeleteSilence)
+
+				// 升级策略
+				alertsRoute.Get("/escalation-policies", alertController.GetEscalationPolicies)
+				alertsRoute.Post("/escalation-policies", alertController.CreateEscalationPolicy)
+				alertsRoute.Patch("/escalation-policies/:id", alertController.UpdateEscalationPolicy)
+				alertsRoute.Delete("/escalation-policies/:id", alertController.DeleteEscalationPolicy)
+
+				// 通知投递记录
+				alertsRoute.Get("/deliveries", alertController.GetNotificationDeliveries)
+				alertsRoute.Get("/deliveries/:id", alertController.GetNotificationDelivery)
+				alertsRoute.Post("/deliveries/:id/resend", alertController.ResendNotificationDelivery)
+			})
+
+			// 告警规则回测和告警配置模板
+			authRouter.Prefix("/alert-rules").Middleware(middleware.AdminAuth()).Group(func(alertRulesRoute route.Router) {
+				alertRulesRoute.Post("/backtest", serverAlertController.BacktestAlertRule)
+
+				// 告警配置模板
+				alertRulesRoute.Get("/profiles", alertProfileController.GetAlertProfiles)
+				alertRulesRoute.Post("/profiles", alertProfileController.CreateAlertProfile)
+				alertRulesRoute.Get("/profiles/:id", alertProfileController.GetAlertProfile)
+				alertRulesRoute.Patch("/profiles/:id", alertProfileController.UpdateAlertProfile)
+				alertRulesRoute.Delete("/profiles/:id", alertProfileController.DeleteAlertProfile)
+				alertRulesRoute.Delete("/profiles/:id/rules/:type", alertProfileController.DeleteAlertProfileRule)
+				alertRulesRoute.Post("/profiles/:id/assign", alertProfileController.AssignAlertProfile)
+			})
+
 			// 服务器相关
 			authRouter.Prefix("/servers").Group(func(serversRoute route.Router) {
 				// 服务器基础操作
@@ -71,6 +137,11 @@ func Api() {
 				serversRoute.Get("/:id/metrics/memory", serverController.GetServerMetricsMemory)
 				serversRoute.Get("/:id/metrics/disk", serverController.GetServerMetricsDisk)
 				serversRoute.Get("/:id/metrics/network", serverController.GetServerMetricsNetwork)
+				serversRoute.Get("/:id/metrics/gpu", serverController.GetServerMetricsGPU)
+				serversRoute.Get("/:id/metrics/temperature", serverController.GetServerMetricsTemperature)
+
+				// 服务监控
+				serversRoute.Get("/:id/services/history", serverController.GetServerServiceHistory)
 
 				// 服务器操作
 				serversRoute.Post("/:id/agent/restart", serverController.RestartAgent)
@@ -79,7 +150,21 @@ func Api() {
 
 				// 服务器告警规则
 				serversRoute.Get("/:id/alert-rules", serverAlertController.GetServerAlertRules)
+				serversRoute.Get("/:id/alert-rules/expressions", serverAlertController.GetExpressionRules)
+				serversRoute.Post("/:id/alert-rules/expressions", serverAlertController.SaveExpressionRule)
+				serversRoute.Post("/:id/alert-rules/expressions/preview", serverAlertController.PreviewExpression)
+				serversRoute.Delete("/:id/alert-rules/expressions/:key", serverAlertController.DeleteExpressionRule)
+				serversRoute.Get("/:id/alert-rules/effective", serverAlertController.GetEffectiveAlertRules)
 				serversRoute.Post("/alert-rules/copy", serverAlertController.CopyAlertRules)
+
+				// 服务器通知渠道订阅
+				serversRoute.Get("/:id/notification-subscriptions", serverAlertController.GetServerNotificationSubscriptions)
+				serversRoute.Patch("/:id/notification-subscriptions", serverAlertController.UpdateServerNotificationSubscriptions)
+
+				// 全局告警规则
+				serversRoute.Get("/alert-rules/global", serverAlertController.GetGlobalAlertRules)
+				serversRoute.Patch("/alert-rules/global", serverAlertController.UpdateGlobalAlertRules)
+				serversRoute.Delete("/alert-rules/global/:type", serverAlertController.DeleteGlobalAlertRule)
 			})
 
 			// 服务器分组管理
@@ -88,6 +173,11 @@ func Api() {
 				groupsRoute.Post("", serverGroupController.CreateGroup)
 				groupsRoute.Patch("/:id", serverGroupController.UpdateGroup)
 				groupsRoute.Delete("/:id", serverGroupController.DeleteGroup)
+				groupsRoute.Get("/:id/alert-rules", serverGroupController.GetGroupAlertRules)
+				groupsRoute.Patch("/:id/alert-rules", serverGroupController.UpdateGroupAlertRules)
+				groupsRoute.Delete("/:id/alert-rules/:type", serverGroupController.DeleteGroupAlertRule)
+				groupsRoute.Get("/:id/notification-subscriptions", serverGroupController.GetGroupNotificationSubscriptions)
+				groupsRoute.Patch("/:id/notification-subscriptions", serverGroupController.UpdateGroupNotificationSubscriptions)
 			})
 		})
 	})
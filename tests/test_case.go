@@ -0,0 +1,176 @@
+// Package tests 提供测试使用的应用环境
+// 测试不加载 .env，而是使用临时的 SQLite 数据库和内存缓存，数据库按 database.Kernel 执行全部迁移
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goravel/framework/cache"
+	frameworkconfig "github.com/goravel/framework/config"
+	contractscache "github.com/goravel/framework/contracts/cache"
+	contractsconfig "github.com/goravel/framework/contracts/config"
+	"github.com/goravel/framework/contracts/database/driver"
+	contractsorm "github.com/goravel/framework/contracts/database/orm"
+	contractsschema "github.com/goravel/framework/contracts/database/schema"
+	contractsclient "github.com/goravel/framework/contracts/http/client"
+	contractslog "github.com/goravel/framework/contracts/log"
+	contractsqueue "github.com/goravel/framework/contracts/queue"
+	databaseorm "github.com/goravel/framework/database/orm"
+	databaseschema "github.com/goravel/framework/database/schema"
+	"github.com/goravel/framework/foundation/json"
+	"github.com/goravel/framework/http/client"
+	mocksfoundation "github.com/goravel/framework/mocks/foundation"
+	"github.com/goravel/framework/support"
+	"github.com/goravel/framework/testing/mock"
+	"github.com/goravel/framework/testing/utils"
+	"github.com/goravel/sqlite"
+
+	"goravel/database"
+)
+
+// databaseCount 已创建的测试数据库数量
+// 框架按连接名缓存数据库连接，每个测试数据库使用不同的连接名
+var databaseCount atomic.Int32
+
+// AppKey 测试使用的应用密钥，用于加解密通知渠道的敏感配置
+const AppKey = "0123456789abcdef0123456789abcdef"
+
+// App 测试应用环境，创建后 facades 使用其中的配置、日志、缓存和数据库
+type App struct {
+	Mock   *mocksfoundation.Application
+	Config contractsconfig.Config
+	Log    contractslog.Log
+	Cache  contractscache.Cache
+	Orm    contractsorm.Orm
+	Schema contractsschema.Schema
+}
+
+// NewApp 创建只包含配置、日志和内存缓存的应用环境，不连接数据库
+func NewApp(t *testing.T) *App {
+	t.Helper()
+
+	// 不读取 .env，应用密钥直接写入配置
+	support.DontVerifyEnvFileExists = true
+	config := frameworkconfig.NewApplication(filepath.Join(t.TempDir(), ".env"))
+	config.Add("app", map[string]any{
+		"name": "CloudSentinel",
+		"env":  "testing",
+		"key":  AppKey,
+	})
+	config.Add("http", map[string]any{
+		"url": "http://localhost",
+	})
+	config.Add("cache", map[string]any{
+		"default": "memory",
+		"stores": map[string]any{
+			"memory": map[string]any{"driver": "memory"},
+		},
+		"prefix": "cloudsentinel_test",
+	})
+
+	app := &App{
+		Mock:   mock.Factory().App(),
+		Config: config,
+		Log:    utils.NewTestLog(),
+	}
+	cacheApp, err := cache.NewApplication(config, app.Log, "memory")
+	if err != nil {
+		t.Fatalf("创建缓存失败: %v", err)
+	}
+	app.Cache = cacheApp
+
+	app.Mock.On("MakeConfig").Return(app.Config).Maybe()
+	app.Mock.On("MakeLog").Return(app.Log).Maybe()
+	app.Mock.On("MakeCache").Return(app.Cache).Maybe()
+	app.Mock.On("GetJson").Return(json.New()).Maybe()
+	app.Mock.On("MakeQueue").Return(&syncQueue{}).Maybe()
+	// 每次调用 facades.Http() 都返回新的请求，与框架的行为一致
+	app.Mock.On("MakeHttp").Return(func() contractsclient.Request {
+		return client.NewRequest(&contractsclient.Config{Timeout: 10 * time.Second}, json.New())
+	}).Maybe()
+	return app
+}
+
+// syncQueue 与线上使用的 sync 驱动一致，分发任务时直接执行
+type syncQueue struct {
+	contractsqueue.Queue
+}
+
+func (q *syncQueue) Job(job contractsqueue.Job, args ...[]contractsqueue.Arg) contractsqueue.PendingJob {
+	return &syncPendingJob{job: job}
+}
+
+type syncPendingJob struct {
+	job contractsqueue.Job
+}
+
+func (p *syncPendingJob) Delay(time.Time) contractsqueue.PendingJob     { return p }
+func (p *syncPendingJob) OnConnection(string) contractsqueue.PendingJob { return p }
+func (p *syncPendingJob) OnQueue(string) contractsqueue.PendingJob      { return p }
+func (p *syncPendingJob) Dispatch() error                               { return p.job.Handle() }
+func (p *syncPendingJob) DispatchSync() error                           { return p.job.Handle() }
+
+// NewDatabase 创建使用临时 SQLite 数据库的应用环境，并执行全部迁移
+func NewDatabase(t *testing.T) *App {
+	t.Helper()
+
+	app := NewApp(t)
+	connection := fmt.Sprintf("sqlite_%d", databaseCount.Add(1))
+	sqlite.App = app.Mock
+	sqliteDriver := sqlite.NewSqlite(app.Config, app.Log, connection)
+	app.Config.Add("database", map[string]any{
+		"default": connection,
+		"connections": map[string]any{
+			connection: map[string]any{
+				"database": filepath.Join(t.TempDir(), "database.db"),
+				"prefix":   "",
+				"singular": false,
+				"via": func() (driver.Driver, error) {
+					return sqliteDriver, nil
+				},
+			},
+		},
+		// 只使用一个连接，避免迁移和查询之间出现 database is locked
+		"pool": map[string]any{
+			"max_idle_conns":    1,
+			"max_open_conns":    1,
+			"conn_max_idletime": 3600,
+			"conn_max_lifetime": 3600,
+		},
+		"migrations": map[string]any{
+			"table": "migrations",
+		},
+	})
+
+	orm, err := databaseorm.BuildOrm(context.Background(), app.Config, connection, app.Log, func(key ...any) {})
+	if err != nil {
+		t.Fatalf("连接测试数据库失败: %v", err)
+	}
+	app.Orm = orm
+	t.Cleanup(func() {
+		if db, err := orm.DB(); err == nil {
+			_ = db.Close()
+		}
+	})
+
+	schema, err := databaseschema.NewSchema(app.Config, app.Log, orm, sqliteDriver, nil)
+	if err != nil {
+		t.Fatalf("创建数据库结构失败: %v", err)
+	}
+	app.Schema = schema
+
+	app.Mock.On("MakeOrm").Return(app.Orm).Maybe()
+	app.Mock.On("MakeSchema").Return(app.Schema).Maybe()
+
+	for _, migration := range (database.Kernel{}).Migrations() {
+		if err := migration.Up(); err != nil {
+			t.Fatalf("执行迁移 %s 失败: %v", migration.Signature(), err)
+		}
+	}
+	return app
+}